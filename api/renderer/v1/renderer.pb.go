@@ -0,0 +1,414 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: renderer/v1/renderer.proto
+
+// Package renderer.v1 renders terraform plans like the serve command of terraform-j2md.
+
+package rendererv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RenderRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The output of `terraform show -json`.
+	PlanJson []byte `protobuf:"bytes,1,opt,name=plan_json,json=planJson,proto3" json:"plan_json,omitempty"`
+	// Flags of the command without dashes, e.g. {"format": "html", "heading-level": "2"}, overriding the defaults of the server.
+	Options       map[string]string `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	mi := &file_renderer_v1_renderer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renderer_v1_renderer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_renderer_v1_renderer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RenderRequest) GetPlanJson() []byte {
+	if x != nil {
+		return x.PlanJson
+	}
+	return nil
+}
+
+func (x *RenderRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type RenderResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A chunk of the document. Chunks are concatenated in order.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// The media type of the document, set in the first chunk only.
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderResponse) Reset() {
+	*x = RenderResponse{}
+	mi := &file_renderer_v1_renderer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderResponse) ProtoMessage() {}
+
+func (x *RenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renderer_v1_renderer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderResponse.ProtoReflect.Descriptor instead.
+func (*RenderResponse) Descriptor() ([]byte, []int) {
+	return file_renderer_v1_renderer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RenderResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RenderResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type SummarizeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The output of `terraform show -json`.
+	PlanJson      []byte `protobuf:"bytes,1,opt,name=plan_json,json=planJson,proto3" json:"plan_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummarizeRequest) Reset() {
+	*x = SummarizeRequest{}
+	mi := &file_renderer_v1_renderer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeRequest) ProtoMessage() {}
+
+func (x *SummarizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renderer_v1_renderer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeRequest.ProtoReflect.Descriptor instead.
+func (*SummarizeRequest) Descriptor() ([]byte, []int) {
+	return file_renderer_v1_renderer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SummarizeRequest) GetPlanJson() []byte {
+	if x != nil {
+		return x.PlanJson
+	}
+	return nil
+}
+
+type SummarizeResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Add        int32                  `protobuf:"varint,1,opt,name=add,proto3" json:"add,omitempty"`
+	Change     int32                  `protobuf:"varint,2,opt,name=change,proto3" json:"change,omitempty"`
+	Destroy    int32                  `protobuf:"varint,3,opt,name=destroy,proto3" json:"destroy,omitempty"`
+	Replace    int32                  `protobuf:"varint,4,opt,name=replace,proto3" json:"replace,omitempty"`
+	Moved      int32                  `protobuf:"varint,5,opt,name=moved,proto3" json:"moved,omitempty"`
+	HasChanges bool                   `protobuf:"varint,6,opt,name=has_changes,json=hasChanges,proto3" json:"has_changes,omitempty"`
+	// Whether anything is destroyed or replaced.
+	HasDestructiveChanges bool `protobuf:"varint,7,opt,name=has_destructive_changes,json=hasDestructiveChanges,proto3" json:"has_destructive_changes,omitempty"`
+	// Counts like "+3 ~2 -1".
+	Short             string   `protobuf:"bytes,8,opt,name=short,proto3" json:"short,omitempty"`
+	CreatedAddresses  []string `protobuf:"bytes,9,rep,name=created_addresses,json=createdAddresses,proto3" json:"created_addresses,omitempty"`
+	UpdatedAddresses  []string `protobuf:"bytes,10,rep,name=updated_addresses,json=updatedAddresses,proto3" json:"updated_addresses,omitempty"`
+	DeletedAddresses  []string `protobuf:"bytes,11,rep,name=deleted_addresses,json=deletedAddresses,proto3" json:"deleted_addresses,omitempty"`
+	ReplacedAddresses []string `protobuf:"bytes,12,rep,name=replaced_addresses,json=replacedAddresses,proto3" json:"replaced_addresses,omitempty"`
+	// Moved addresses as markdown, "`new` (from `old`)".
+	MovedAddresses []string `protobuf:"bytes,13,rep,name=moved_addresses,json=movedAddresses,proto3" json:"moved_addresses,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SummarizeResponse) Reset() {
+	*x = SummarizeResponse{}
+	mi := &file_renderer_v1_renderer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeResponse) ProtoMessage() {}
+
+func (x *SummarizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renderer_v1_renderer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeResponse.ProtoReflect.Descriptor instead.
+func (*SummarizeResponse) Descriptor() ([]byte, []int) {
+	return file_renderer_v1_renderer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SummarizeResponse) GetAdd() int32 {
+	if x != nil {
+		return x.Add
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetChange() int32 {
+	if x != nil {
+		return x.Change
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetDestroy() int32 {
+	if x != nil {
+		return x.Destroy
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetReplace() int32 {
+	if x != nil {
+		return x.Replace
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetMoved() int32 {
+	if x != nil {
+		return x.Moved
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetHasChanges() bool {
+	if x != nil {
+		return x.HasChanges
+	}
+	return false
+}
+
+func (x *SummarizeResponse) GetHasDestructiveChanges() bool {
+	if x != nil {
+		return x.HasDestructiveChanges
+	}
+	return false
+}
+
+func (x *SummarizeResponse) GetShort() string {
+	if x != nil {
+		return x.Short
+	}
+	return ""
+}
+
+func (x *SummarizeResponse) GetCreatedAddresses() []string {
+	if x != nil {
+		return x.CreatedAddresses
+	}
+	return nil
+}
+
+func (x *SummarizeResponse) GetUpdatedAddresses() []string {
+	if x != nil {
+		return x.UpdatedAddresses
+	}
+	return nil
+}
+
+func (x *SummarizeResponse) GetDeletedAddresses() []string {
+	if x != nil {
+		return x.DeletedAddresses
+	}
+	return nil
+}
+
+func (x *SummarizeResponse) GetReplacedAddresses() []string {
+	if x != nil {
+		return x.ReplacedAddresses
+	}
+	return nil
+}
+
+func (x *SummarizeResponse) GetMovedAddresses() []string {
+	if x != nil {
+		return x.MovedAddresses
+	}
+	return nil
+}
+
+var File_renderer_v1_renderer_proto protoreflect.FileDescriptor
+
+const file_renderer_v1_renderer_proto_rawDesc = "" +
+	"\n" +
+	"\x1arenderer/v1/renderer.proto\x12\vrenderer.v1\"\xab\x01\n" +
+	"\rRenderRequest\x12\x1b\n" +
+	"\tplan_json\x18\x01 \x01(\fR\bplanJson\x12A\n" +
+	"\aoptions\x18\x02 \x03(\v2'.renderer.v1.RenderRequest.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"G\n" +
+	"\x0eRenderResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"/\n" +
+	"\x10SummarizeRequest\x12\x1b\n" +
+	"\tplan_json\x18\x01 \x01(\fR\bplanJson\"\xd5\x03\n" +
+	"\x11SummarizeResponse\x12\x10\n" +
+	"\x03add\x18\x01 \x01(\x05R\x03add\x12\x16\n" +
+	"\x06change\x18\x02 \x01(\x05R\x06change\x12\x18\n" +
+	"\adestroy\x18\x03 \x01(\x05R\adestroy\x12\x18\n" +
+	"\areplace\x18\x04 \x01(\x05R\areplace\x12\x14\n" +
+	"\x05moved\x18\x05 \x01(\x05R\x05moved\x12\x1f\n" +
+	"\vhas_changes\x18\x06 \x01(\bR\n" +
+	"hasChanges\x126\n" +
+	"\x17has_destructive_changes\x18\a \x01(\bR\x15hasDestructiveChanges\x12\x14\n" +
+	"\x05short\x18\b \x01(\tR\x05short\x12+\n" +
+	"\x11created_addresses\x18\t \x03(\tR\x10createdAddresses\x12+\n" +
+	"\x11updated_addresses\x18\n" +
+	" \x03(\tR\x10updatedAddresses\x12+\n" +
+	"\x11deleted_addresses\x18\v \x03(\tR\x10deletedAddresses\x12-\n" +
+	"\x12replaced_addresses\x18\f \x03(\tR\x11replacedAddresses\x12'\n" +
+	"\x0fmoved_addresses\x18\r \x03(\tR\x0emovedAddresses2\xa2\x01\n" +
+	"\x0fRendererService\x12C\n" +
+	"\x06Render\x12\x1a.renderer.v1.RenderRequest\x1a\x1b.renderer.v1.RenderResponse0\x01\x12J\n" +
+	"\tSummarize\x12\x1d.renderer.v1.SummarizeRequest\x1a\x1e.renderer.v1.SummarizeResponseBd\n" +
+	"\"io.repro.terraformj2md.renderer.v1P\x01Z<github.com/reproio/terraform-j2md/api/renderer/v1;rendererv1b\x06proto3"
+
+var (
+	file_renderer_v1_renderer_proto_rawDescOnce sync.Once
+	file_renderer_v1_renderer_proto_rawDescData []byte
+)
+
+func file_renderer_v1_renderer_proto_rawDescGZIP() []byte {
+	file_renderer_v1_renderer_proto_rawDescOnce.Do(func() {
+		file_renderer_v1_renderer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_renderer_v1_renderer_proto_rawDesc), len(file_renderer_v1_renderer_proto_rawDesc)))
+	})
+	return file_renderer_v1_renderer_proto_rawDescData
+}
+
+var file_renderer_v1_renderer_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_renderer_v1_renderer_proto_goTypes = []any{
+	(*RenderRequest)(nil),     // 0: renderer.v1.RenderRequest
+	(*RenderResponse)(nil),    // 1: renderer.v1.RenderResponse
+	(*SummarizeRequest)(nil),  // 2: renderer.v1.SummarizeRequest
+	(*SummarizeResponse)(nil), // 3: renderer.v1.SummarizeResponse
+	nil,                       // 4: renderer.v1.RenderRequest.OptionsEntry
+}
+var file_renderer_v1_renderer_proto_depIdxs = []int32{
+	4, // 0: renderer.v1.RenderRequest.options:type_name -> renderer.v1.RenderRequest.OptionsEntry
+	0, // 1: renderer.v1.RendererService.Render:input_type -> renderer.v1.RenderRequest
+	2, // 2: renderer.v1.RendererService.Summarize:input_type -> renderer.v1.SummarizeRequest
+	1, // 3: renderer.v1.RendererService.Render:output_type -> renderer.v1.RenderResponse
+	3, // 4: renderer.v1.RendererService.Summarize:output_type -> renderer.v1.SummarizeResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_renderer_v1_renderer_proto_init() }
+func file_renderer_v1_renderer_proto_init() {
+	if File_renderer_v1_renderer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_renderer_v1_renderer_proto_rawDesc), len(file_renderer_v1_renderer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_renderer_v1_renderer_proto_goTypes,
+		DependencyIndexes: file_renderer_v1_renderer_proto_depIdxs,
+		MessageInfos:      file_renderer_v1_renderer_proto_msgTypes,
+	}.Build()
+	File_renderer_v1_renderer_proto = out.File
+	file_renderer_v1_renderer_proto_goTypes = nil
+	file_renderer_v1_renderer_proto_depIdxs = nil
+}
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: renderer/v1/renderer.proto
+
+// Package renderer.v1 renders terraform plans like the serve command of terraform-j2md.
+
+package rendererv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RendererService_Render_FullMethodName    = "/renderer.v1.RendererService/Render"
+	RendererService_Summarize_FullMethodName = "/renderer.v1.RendererService/Summarize"
+)
+
+// RendererServiceClient is the client API for RendererService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RendererServiceClient interface {
+	// Render renders the plan, streaming the document in chunks since reports of large plans exceed the message size.
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderResponse], error)
+	// Summarize returns the counts and the addresses of changes of the plan.
+	Summarize(ctx context.Context, in *SummarizeRequest, opts ...grpc.CallOption) (*SummarizeResponse, error)
+}
+
+type rendererServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRendererServiceClient(cc grpc.ClientConnInterface) RendererServiceClient {
+	return &rendererServiceClient{cc}
+}
+
+func (c *rendererServiceClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RendererService_ServiceDesc.Streams[0], RendererService_Render_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RenderRequest, RenderResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RendererService_RenderClient = grpc.ServerStreamingClient[RenderResponse]
+
+func (c *rendererServiceClient) Summarize(ctx context.Context, in *SummarizeRequest, opts ...grpc.CallOption) (*SummarizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SummarizeResponse)
+	err := c.cc.Invoke(ctx, RendererService_Summarize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RendererServiceServer is the server API for RendererService service.
+// All implementations must embed UnimplementedRendererServiceServer
+// for forward compatibility.
+type RendererServiceServer interface {
+	// Render renders the plan, streaming the document in chunks since reports of large plans exceed the message size.
+	Render(*RenderRequest, grpc.ServerStreamingServer[RenderResponse]) error
+	// Summarize returns the counts and the addresses of changes of the plan.
+	Summarize(context.Context, *SummarizeRequest) (*SummarizeResponse, error)
+	mustEmbedUnimplementedRendererServiceServer()
+}
+
+// UnimplementedRendererServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRendererServiceServer struct{}
+
+func (UnimplementedRendererServiceServer) Render(*RenderRequest, grpc.ServerStreamingServer[RenderResponse]) error {
+	return status.Error(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedRendererServiceServer) Summarize(context.Context, *SummarizeRequest) (*SummarizeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Summarize not implemented")
+}
+func (UnimplementedRendererServiceServer) mustEmbedUnimplementedRendererServiceServer() {}
+func (UnimplementedRendererServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeRendererServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RendererServiceServer will
+// result in compilation errors.
+type UnsafeRendererServiceServer interface {
+	mustEmbedUnimplementedRendererServiceServer()
+}
+
+func RegisterRendererServiceServer(s grpc.ServiceRegistrar, srv RendererServiceServer) {
+	// If the following call panics, it indicates UnimplementedRendererServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RendererService_ServiceDesc, srv)
+}
+
+func _RendererService_Render_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RenderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RendererServiceServer).Render(m, &grpc.GenericServerStream[RenderRequest, RenderResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RendererService_RenderServer = grpc.ServerStreamingServer[RenderResponse]
+
+func _RendererService_Summarize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SummarizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RendererServiceServer).Summarize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RendererService_Summarize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RendererServiceServer).Summarize(ctx, req.(*SummarizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RendererService_ServiceDesc is the grpc.ServiceDesc for RendererService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RendererService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "renderer.v1.RendererService",
+	HandlerType: (*RendererServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Summarize",
+			Handler:    _RendererService_Summarize_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Render",
+			Handler:       _RendererService_Render_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "renderer/v1/renderer.proto",
+}
@@ -0,0 +1,53 @@
+// Command terraform-j2md-lambda is the AWS Lambda function of package lambda, for the provided.al2023 runtime as bootstrap.
+//
+// It is configured with environment variables:
+//
+//	TERRAFORM_J2MD_OPTIONS  options of rendering as a query string, e.g. format=html&sort=alpha
+//	OUTPUT_BUCKET           bucket of reports (default: the bucket of the plan of S3 events)
+//	OUTPUT_PREFIX           prefix of keys of reports
+//	WEBHOOK_URL             URL to post reports to (optional)
+//	WEBHOOK_SECRET          secret to sign the posted reports (optional)
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"github.com/reproio/terraform-j2md/lambda"
+)
+
+func main() {
+	params, err := url.ParseQuery(os.Getenv("TERRAFORM_J2MD_OPTIONS"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid TERRAFORM_J2MD_OPTIONS: %v\n", err)
+		os.Exit(2)
+	}
+	opts, err := terraform.ParseOptions(terraform.DefaultOptions(), params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	h := &lambda.Handler{
+		Options:      opts,
+		OutputBucket: os.Getenv("OUTPUT_BUCKET"),
+		OutputPrefix: os.Getenv("OUTPUT_PREFIX"),
+		// The credentials of the execution role are in the environment of the function.
+		NewS3: func(bucket string) *publish.S3 {
+			return &publish.S3{
+				Bucket:          bucket,
+				Region:          os.Getenv("AWS_REGION"),
+				AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			}
+		},
+	}
+	if u := os.Getenv("WEBHOOK_URL"); u != "" {
+		h.Webhook = &publish.Webhook{URL: u, Secret: os.Getenv("WEBHOOK_SECRET"), Retries: 2}
+	}
+	awslambda.Start(h.Handle)
+}
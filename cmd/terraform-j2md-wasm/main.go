@@ -0,0 +1,72 @@
+//go:build js && wasm
+
+// Command terraform-j2md-wasm exports renderPlan to JavaScript, to render plans in browsers
+// without sending them to a server.
+//
+//	const { output, contentType } = renderPlan(planJSON, { format: "html", "heading-level": 2 });
+//
+// The options are the flags of terraform-j2md without dashes, with arrays for repeatable flags.
+// If the plan or the options are invalid, renderPlan returns { error } instead,
+// since Go functions cannot throw JavaScript exceptions.
+package main
+
+import (
+	"strings"
+	"syscall/js"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func main() {
+	js.Global().Set("renderPlan", js.FuncOf(renderPlan))
+	// Keep the exported function alive.
+	select {}
+}
+
+func renderPlan(this js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return failure("renderPlan(json, options) requires the plan JSON as a string")
+	}
+	params := map[string][]string{}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		params = optionParams(args[1])
+	}
+	opts, err := terraform.ParseOptions(terraform.DefaultOptions(), params)
+	if err != nil {
+		return failure("invalid options: " + err.Error())
+	}
+	planData, err := terraform.NewPlanData(strings.NewReader(args[0].String()), opts)
+	if err != nil {
+		return failure("cannot parse input as Terraform plan JSON: " + err.Error())
+	}
+	var b strings.Builder
+	if err := planData.Render(&b); err != nil {
+		return failure("cannot render: " + err.Error())
+	}
+	return map[string]any{"output": b.String(), "contentType": terraform.ContentType(opts.Format)}
+}
+
+// optionParams returns the options of an object as the values of flags.
+// Arrays are the values of repeatable flags, e.g. { severity: ["update=high", "create=medium"] }.
+func optionParams(options js.Value) map[string][]string {
+	params := map[string][]string{}
+	toString := js.Global().Get("String")
+	isArray := js.Global().Get("Array").Get("isArray")
+	keys := js.Global().Get("Object").Call("keys", options)
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		v := options.Get(name)
+		if !isArray.Invoke(v).Bool() {
+			params[name] = []string{toString.Invoke(v).String()}
+			continue
+		}
+		for j := 0; j < v.Length(); j++ {
+			params[name] = append(params[name], toString.Invoke(v.Index(j)).String())
+		}
+	}
+	return params
+}
+
+func failure(message string) any {
+	return map[string]any{"error": message}
+}
@@ -0,0 +1,44 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"reflect"
+	"syscall/js"
+	"testing"
+)
+
+func TestOptionParams(t *testing.T) {
+	options := js.ValueOf(map[string]any{
+		"format":        "html",
+		"heading-level": 2,
+		"severity":      []any{"update=high", "create=medium"},
+	})
+	want := map[string][]string{
+		"format":        {"html"},
+		"heading-level": {"2"},
+		"severity":      {"update=high", "create=medium"},
+	}
+	if got := optionParams(options); !reflect.DeepEqual(got, want) {
+		t.Errorf("optionParams() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderPlan_ArrayOption(t *testing.T) {
+	plan := `{"format_version": "1.2", "resource_changes": []}`
+	res := renderPlan(js.Undefined(), []js.Value{
+		js.ValueOf(plan),
+		js.ValueOf(map[string]any{"severity": []any{"update=high", "delete=low"}}),
+	}).(map[string]any)
+	if res["error"] != nil {
+		t.Errorf("renderPlan() error = %v", res["error"])
+	}
+
+	res = renderPlan(js.Undefined(), []js.Value{
+		js.ValueOf(plan),
+		js.ValueOf(map[string]any{"severity": []any{"update=high", "delete"}}),
+	}).(map[string]any)
+	if res["error"] == nil {
+		t.Error("renderPlan() should fail with an invalid element of an array option")
+	}
+}
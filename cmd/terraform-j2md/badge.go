@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/badge"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func runBadge(args []string) int {
+	flags := flag.NewFlagSet("badge", flag.ExitOnError)
+	label := flags.String("label", "terraform", "label of the badge")
+	svg := flags.Bool("svg", false, "print an inline SVG image instead of a shields.io URL")
+	parseFlags(flags, args)
+
+	planData, err := terraform.NewPlanData(os.Stdin, terraform.DefaultOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform plan JSON: %v", err)
+		return 1
+	}
+	b := badge.New(*label, planData.Summary())
+	if !*svg {
+		fmt.Println(b.URL())
+		return 0
+	}
+	image, err := b.SVG()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot render: %v", err)
+		return 1
+	}
+	fmt.Print(image)
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// loadConfig sets the options in the JSON file at path to opts, whose keys are the names of the flags of rendering
+// and whose values are strings, booleans, numbers or arrays of them for repeatable flags, e.g.
+//
+//	{"format": "html", "registry-links": true, "link-template": ["aws_instance=https://cmdb.example.com/{{.Values.id}}"]}
+//
+// Flags given on the command line take precedence over the file.
+func loadConfig(path string, flags *flag.FlagSet, opts *terraform.Options) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var config map[string]any
+	if err := json.Unmarshal(b, &config); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	params := map[string][]string{}
+	for name, v := range config {
+		if set[name] {
+			continue
+		}
+		values, ok := v.([]any)
+		if !ok {
+			values = []any{v}
+		}
+		for _, value := range values {
+			switch value.(type) {
+			case string, bool, float64:
+				params[name] = append(params[name], fmt.Sprint(value))
+			default:
+				return fmt.Errorf("%s: value of %q must be a string, a boolean, a number or an array of them", path, name)
+			}
+		}
+	}
+	parsed, err := terraform.ParseOptions(*opts, params)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	*opts = parsed
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/fetch"
+)
+
+const fetchUsage = "usage: terraform-j2md fetch <scalr|env0> [flags] > show.json"
+
+func runFetch(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, fetchUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("fetch "+args[0], flag.ExitOnError)
+	var fetcher fetch.Fetcher
+	var runID *string
+	switch args[0] {
+	case "scalr":
+		s := &fetch.Scalr{Token: os.Getenv("SCALR_TOKEN")}
+		hostname := os.Getenv("SCALR_HOSTNAME")
+		if hostname != "" {
+			hostname = "https://" + hostname
+		}
+		flags.StringVar(&s.URL, "url", hostname, "URL of the Scalr account (default: https://$SCALR_HOSTNAME)")
+		runID = flags.String("run", os.Getenv("SCALR_RUN_ID"), "ID of the run (default: $SCALR_RUN_ID)")
+		fetcher = s
+	case "env0":
+		e := &fetch.Env0{APIKey: os.Getenv("ENV0_API_KEY"), APISecret: os.Getenv("ENV0_API_SECRET")}
+		flags.StringVar(&e.URL, "url", fetch.DefaultEnv0URL, "URL of the env0 API")
+		runID = flags.String("run", os.Getenv("ENV0_DEPLOYMENT_LOG_ID"), "ID of the deployment (default: $ENV0_DEPLOYMENT_LOG_ID)")
+		fetcher = e
+	default:
+		fmt.Fprintln(os.Stderr, fetchUsage)
+		return 2
+	}
+	parseFlags(flags, args[1:])
+
+	plan, err := fetcher.Fetch(context.Background(), *runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot fetch the plan: %v\n", err)
+		return 1
+	}
+	if _, err := os.Stdout.Write(plan); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write the plan: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// githubFlags defines the flags of GitHub integrations, defaulting to the environment of GitHub Actions.
+// A GitHub App given by --app-id authenticates instead of $GITHUB_TOKEN.
+func githubFlags(flags *flag.FlagSet) *publish.GitHub {
+	g := &publish.GitHub{Token: os.Getenv("GITHUB_TOKEN"), App: &publish.GitHubApp{}}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = publish.DefaultGitHubAPIURL
+	}
+	flags.StringVar(&g.APIURL, "github-api-url", apiURL, "URL of the GitHub API, https://<host>/api/v3 for GitHub Enterprise Server (default: $GITHUB_API_URL or https://api.github.com)")
+	flags.StringVar(&g.Repository, "repository", os.Getenv("GITHUB_REPOSITORY"), "repository as owner/name (default: $GITHUB_REPOSITORY)")
+	flags.StringVar(&g.App.AppID, "app-id", os.Getenv("GITHUB_APP_ID"), "ID of the GitHub App to authenticate as, instead of $GITHUB_TOKEN (default: $GITHUB_APP_ID)")
+	installationID, _ := strconv.ParseInt(os.Getenv("GITHUB_APP_INSTALLATION_ID"), 10, 64)
+	flags.Int64Var(&g.App.InstallationID, "app-installation-id", installationID, "installation of the GitHub App (default: $GITHUB_APP_INSTALLATION_ID, or the installation on the repository)")
+	g.App.PrivateKey = os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	flags.Func("app-private-key-file", "PEM file of the private key of the GitHub App (default: the key in $GITHUB_APP_PRIVATE_KEY)", func(path string) error {
+		b, err := os.ReadFile(path)
+		g.App.PrivateKey = string(b)
+		return err
+	})
+	return g
+}
+
+// githubEvent is the part of the event payload of the workflow run used by the integrations.
+type githubEvent struct {
+	Number      int `json:"number"`
+	PullRequest struct {
+		Number int `json:"number"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Issue struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+}
+
+// readGitHubEvent reads the event payload at $GITHUB_EVENT_PATH. It is empty outside of GitHub Actions.
+func readGitHubEvent() githubEvent {
+	var event githubEvent
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(b, &event)
+		}
+	}
+	return event
+}
+
+// githubPullRequest returns the number of the pull request of the workflow run, or 0 if unknown.
+func githubPullRequest() int {
+	event := readGitHubEvent()
+	for _, n := range []int{event.PullRequest.Number, event.Number, event.Issue.Number} {
+		if n > 0 {
+			return n
+		}
+	}
+	// GITHUB_REF is refs/pull/<number>/merge for pull_request events.
+	if ref := strings.TrimPrefix(os.Getenv("GITHUB_REF"), "refs/pull/"); ref != os.Getenv("GITHUB_REF") {
+		if n, err := strconv.Atoi(strings.TrimSuffix(ref, "/merge")); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// githubHeadSHA returns the head commit of the pull request of the workflow run,
+// since GITHUB_SHA of pull_request events is the merge commit, or GITHUB_SHA for other events.
+func githubHeadSHA() string {
+	if sha := readGitHubEvent().PullRequest.Head.SHA; sha != "" {
+		return sha
+	}
+	return os.Getenv("GITHUB_SHA")
+}
+
+// writeGitHubOutput appends the counts of changes to the file of step outputs of GitHub Actions.
+func writeGitHubOutput(path string, summary terraform.Summary) error {
+	if path == "" {
+		return errors.New("GITHUB_OUTPUT is not set")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "add=%d\nchange=%d\ndestroy=%d\nreplace=%d\nmoved=%d\nhas_changes=%t\nhas_destructive_changes=%t\nseverity=%s\n",
+		summary.Add, summary.Change, summary.Destroy, summary.Replace, summary.Moved, summary.HasChanges(), summary.HasDestructiveChanges(), summary.Severity)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func runGraph2md(args []string) int {
+	flags := flag.NewFlagSet("graph2md", flag.ExitOnError)
+	opts := terraform.DefaultOptions()
+	planFile := flags.String("plan", "", "JSON file of the output of terraform show -json whose changed resources are highlighted with their actions")
+	flags.StringVar(&opts.CodeFenceChar, "code-fence-char", opts.CodeFenceChar, "character of the code fence of the diagram: ` or ~")
+	flags.IntVar(&opts.CodeFenceLength, "code-fence-length", opts.CodeFenceLength, "number of characters of the code fence of the diagram")
+	flags.BoolVar(&opts.Accessible, "accessible", opts.Accessible, "prefix the labels of highlighted nodes with text markers of their actions, e.g. '[ADD]'")
+	parseFlags(flags, args)
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	var plan *terraform.PlanData
+	if *planFile != "" {
+		f, err := os.Open(*planFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open the plan: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		if plan, err = terraform.NewPlanData(f, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot parse the plan as Terraform plan JSON: %v\n", err)
+			return 1
+		}
+	}
+	graph, err := terraform.NewGraph(os.Stdin, plan, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as output of terraform graph: %v\n", err)
+		return 1
+	}
+	if err := graph.Render(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot render: %v\n", err)
+		return 1
+	}
+	return 0
+}
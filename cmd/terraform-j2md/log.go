@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+)
+
+// parseFlags parses args with the logging flags shared by all commands, and configures the default logger.
+func parseFlags(flags *flag.FlagSet, args []string) {
+	level := slog.LevelInfo
+	flags.TextVar(&level, "log-level", slog.LevelInfo, "minimum level of logs written to stderr: debug, info, warn or error")
+	debug := flags.Bool("debug", false, "log debug messages, e.g. skipped resources, time to diff each resource and requests to services (same as --log-level debug)")
+	var quiet bool
+	flags.BoolVar(&quiet, "quiet", false, "log nothing but errors, so that stdout and stderr contain only the output and errors")
+	flags.BoolVar(&quiet, "q", false, "shorthand for --quiet")
+	_ = flags.Parse(args)
+	switch {
+	case quiet:
+		level = slog.LevelError
+	case *debug:
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
@@ -1,28 +1,117 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/reproio/terraform-j2md/internal/terraform"
+	"golang.org/x/term"
 )
 
 var (
-	escapeHTML = true
+	options      = terraform.DefaultOptions()
+	githubOutput bool
+	messagesFile string
+	configFile   string
+	schemaFile   string
+	fullReport   string
+	templates    = map[string]string{}
+	headerFile   string
+	footerFile   string
+	terraformDir string
 )
 
 func main() {
-	noEscapeHTML := flag.Bool("no-escape-html", false, "prevent <, >, and & from being escaped in JSON strings")
-	flag.Parse()
-	if *noEscapeHTML {
-		escapeHTML = false
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "badge":
+			os.Exit(runBadge(os.Args[2:]))
+		case "post":
+			os.Exit(runPost(os.Args[2:]))
+		case "upload":
+			os.Exit(runUpload(os.Args[2:]))
+		case "fetch":
+			os.Exit(runFetch(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "tui":
+			os.Exit(runTUI(os.Args[2:]))
+		case "state2md":
+			os.Exit(runState2md(os.Args[2:]))
+		case "state-diff":
+			os.Exit(runStateDiff(os.Args[2:]))
+		case "graph2md":
+			os.Exit(runGraph2md(os.Args[2:]))
+		}
+	}
+
+	options.AddFlags(flag.CommandLine)
+	flag.StringVar(&configFile, "config", "", "JSON file of options by flag name, e.g. {\"format\": \"html\", \"link-template\": [...]}; flags take precedence")
+	flag.StringVar(&messagesFile, "messages", "", "JSON file of overrides of generated text by message ID, e.g. {\"created\": \"será creado\"}; --message takes precedence")
+	flag.StringVar(&schemaFile, "schema", "", "JSON file of the output of terraform providers schema -json to mask sensitive attributes missing in the plan and to know computed attributes")
+	flag.StringVar(&fullReport, "full-report", "", "file to write the report with all details to, e.g. an artifact linked by --details-url when --max-detailed-resources omits them")
+	flag.Func("template", "file overriding a named template of markdown as 'name=file': summary, details or resource (repeatable)", func(s string) error {
+		name, path, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("template must be 'name=file': %q", s)
+		}
+		templates[name] = path
+		return nil
+	})
+	flag.StringVar(&headerFile, "header-file", "", "markdown file prepended to the report, e.g. links to the run or on-call contacts")
+	flag.StringVar(&footerFile, "footer-file", "", "markdown file appended to the report, e.g. instructions of applies")
+	flag.StringVar(&terraformDir, "terraform-dir", "", "working directory of terraform whose workspace and backend are rendered below the heading unless --workspace, --backend or --state-address are given")
+	flag.BoolVar(&githubOutput, "github-output", false, "write the counts of changes and has_changes to $GITHUB_OUTPUT for later steps of GitHub Actions")
+	parseFlags(flag.CommandLine, os.Args[1:])
+	if err := loadConfig(configFile, flag.CommandLine, &options); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := loadMessages(messagesFile); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := loadTemplates(templates); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := loadHeaderFooter(headerFile, footerFile); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := loadSchemas(schemaFile, &options); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := loadWorkingDir(terraformDir); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	if err := options.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(2)
+	}
+	// Colors are only for people reading the output on a terminal.
+	if !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv("NO_COLOR") != "" {
+		options.Color = false
 	}
 	os.Exit(run())
 }
 
 func run() int {
-	planData, err := terraform.NewPlanData(os.Stdin, escapeHTML)
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read input: %v", err)
+		return 1
+	}
+	planData, err := terraform.NewPlanData(bytes.NewReader(input), options)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform plan JSON: %v", err)
 		return 1
@@ -31,5 +120,140 @@ func run() int {
 		fmt.Fprintf(os.Stderr, "cannot render: %v", err)
 		return 1
 	}
-	return 0
+	if fullReport != "" {
+		if err := writeFullReport(fullReport, input); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot write full report: %v\n", err)
+			return 1
+		}
+	}
+	if githubOutput {
+		if err := writeGitHubOutput(os.Getenv("GITHUB_OUTPUT"), planData.Summary()); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot write GitHub Actions outputs: %v\n", err)
+			return 1
+		}
+	}
+	for _, v := range planData.ThresholdViolations() {
+		slog.Warn(planData.Message("thresholds"), "limit", planData.Message(v.Message), "count", v.Count, "max", v.Limit)
+	}
+	for _, r := range planData.TypeViolations() {
+		slog.Warn(planData.Message("type_violations"), "address", r.Address(), "action", planData.Message(r.Action()))
+	}
+	return planData.ExitCode()
+}
+
+// writeFullReport writes the plan in input to the file at path with all details.
+func writeFullReport(path string, input []byte) error {
+	opts := options
+	opts.MaxDetailedResources = 0
+	planData, err := terraform.NewPlanData(bytes.NewReader(input), opts)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = planData.Render(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// loadTemplates sets the texts of the files of named templates to options.
+func loadTemplates(paths map[string]string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	options.Templates = map[string]string{}
+	for name, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		options.Templates[name] = string(b)
+	}
+	return nil
+}
+
+// loadHeaderFooter sets the markdown in the files at headerPath and footerPath to options.
+func loadHeaderFooter(headerPath, footerPath string) error {
+	if headerPath != "" {
+		b, err := os.ReadFile(headerPath)
+		if err != nil {
+			return err
+		}
+		options.Header = string(b)
+	}
+	if footerPath != "" {
+		b, err := os.ReadFile(footerPath)
+		if err != nil {
+			return err
+		}
+		options.Footer = string(b)
+	}
+	return nil
+}
+
+// loadSchemas sets the provider schemas in the JSON file at path to opts.
+func loadSchemas(path string, opts *terraform.Options) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var schemas tfjson.ProviderSchemas
+	if err := json.Unmarshal(b, &schemas); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	opts.ProviderSchemas = &schemas
+	return nil
+}
+
+// loadWorkingDir sets the workspace and the backend of the working directory of terraform at dir to options,
+// except those given by flags.
+func loadWorkingDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	workDir, err := terraform.ReadWorkingDir(dir)
+	if err != nil {
+		return err
+	}
+	if options.Workspace == "" {
+		options.Workspace = workDir.Workspace
+	}
+	if options.Backend == "" {
+		options.Backend = workDir.Backend
+	}
+	if options.StateAddress == "" {
+		options.StateAddress = workDir.StateAddress
+	}
+	return nil
+}
+
+// loadMessages adds the overrides of messages in the JSON file at path to options,
+// except those given by --message.
+func loadMessages(path string) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(b, &messages); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	if messages == nil {
+		messages = map[string]string{}
+	}
+	for id, text := range options.Messages {
+		messages[id] = text
+	}
+	options.Messages = messages
+	return nil
 }
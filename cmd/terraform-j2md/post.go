@@ -0,0 +1,787 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+const postUsage = "usage: terraform-j2md post <github|github-check|gitlab|confluence|jira|notion|teams|discord|webhook|email|azure-devops|bitbucket|spacelift> [flags] < show.json"
+
+func runPost(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, postUsage)
+		return 2
+	}
+	switch args[0] {
+	case "github":
+		return runPostGitHub(args[1:])
+	case "github-check":
+		return runPostGitHubCheck(args[1:])
+	case "gitlab":
+		return runPostGitLab(args[1:])
+	case "confluence":
+		return runPostConfluence(args[1:])
+	case "jira":
+		return runPostJira(args[1:])
+	case "notion":
+		return runPostNotion(args[1:])
+	case "teams":
+		return runPostTeams(args[1:])
+	case "discord":
+		return runPostDiscord(args[1:])
+	case "webhook":
+		return runPostWebhook(args[1:])
+	case "email":
+		return runPostEmail(args[1:])
+	case "azure-devops":
+		return runPostAzureDevOps(args[1:])
+	case "bitbucket":
+		return runPostBitbucket(args[1:])
+	case "spacelift":
+		return runPostSpacelift(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown post target %q\n%s\n", args[0], postUsage)
+		return 2
+	}
+}
+
+func runPostGitHub(args []string) int {
+	flags := flag.NewFlagSet("post github", flag.ExitOnError)
+	g := githubFlags(flags)
+	pullRequest := flags.Int("pull-request", githubPullRequest(), "number of the pull request (default: the pull request of the workflow run)")
+	sticky := stickyFlags(flags)
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := g.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := sticky.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if *pullRequest <= 0 {
+		fmt.Fprintln(os.Stderr, "invalid options: pull request number is required")
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	commentURL, err := g.Post(context.Background(), *pullRequest, report, *sticky)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to GitHub: %v\n", err)
+		return 1
+	}
+	fmt.Println(commentURL)
+	return 0
+}
+
+func runPostGitHubCheck(args []string) int {
+	flags := flag.NewFlagSet("post github-check", flag.ExitOnError)
+	g := githubFlags(flags)
+	name := flags.String("name", "terraform plan", "name of the check run or context of the commit status")
+	sha := flags.String("sha", githubHeadSHA(), "commit to report on (default: the head commit of the pull request or $GITHUB_SHA)")
+	status := flags.Bool("status", false, "create a commit status instead of a check run, e.g. with a token which cannot create check runs")
+	failOnDestroy := flags.Bool("fail-on-destroy", false, "fail the check if anything is destroyed or replaced (default: neutral)")
+	detailsURL := flags.String("details-url", "", "URL of the full report linked from the check")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := g.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if *sha == "" {
+		fmt.Fprintln(os.Stderr, "invalid options: commit SHA is required")
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	summary := planData.Summary()
+	conclusion := publish.CheckSuccess
+	if summary.HasDestructiveChanges() {
+		conclusion = publish.CheckNeutral
+		if *failOnDestroy {
+			conclusion = publish.CheckFailure
+		}
+	}
+	ctx := context.Background()
+	if *status {
+		// Commit statuses have no neutral state.
+		if conclusion == publish.CheckNeutral {
+			conclusion = publish.CheckSuccess
+		}
+		err = g.CreateCommitStatus(ctx, publish.CommitStatus{
+			Context:     *name,
+			SHA:         *sha,
+			State:       conclusion,
+			Description: publish.CheckTitle(summary),
+			TargetURL:   *detailsURL,
+		})
+	} else {
+		var checkURL string
+		checkURL, err = g.CreateCheckRun(ctx, publish.CheckRun{
+			Name:       *name,
+			HeadSHA:    *sha,
+			Conclusion: conclusion,
+			Title:      publish.CheckTitle(summary),
+			Summary:    report,
+			DetailsURL: *detailsURL,
+		})
+		if err == nil {
+			fmt.Println(checkURL)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to GitHub: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runPostGitLab(args []string) int {
+	flags := flag.NewFlagSet("post gitlab", flag.ExitOnError)
+	g := publish.GitLab{Token: os.Getenv("GITLAB_TOKEN")}
+	serverURL := os.Getenv("CI_SERVER_URL")
+	if serverURL == "" {
+		serverURL = publish.DefaultGitLabURL
+	}
+	mergeRequest, _ := strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID"))
+	flags.StringVar(&g.URL, "gitlab-url", serverURL, "URL of the GitLab instance (default: $CI_SERVER_URL or https://gitlab.com)")
+	flags.StringVar(&g.Project, "project", os.Getenv("CI_PROJECT_ID"), "ID or path of the project (default: $CI_PROJECT_ID)")
+	flags.IntVar(&mergeRequest, "merge-request", mergeRequest, "IID of the merge request (default: $CI_MERGE_REQUEST_IID)")
+	sticky := stickyFlags(flags)
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := g.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := sticky.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if mergeRequest <= 0 {
+		fmt.Fprintln(os.Stderr, "invalid options: merge request IID is required")
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	noteURL, err := g.Post(context.Background(), mergeRequest, report, *sticky)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to GitLab: %v\n", err)
+		return 1
+	}
+	fmt.Println(noteURL)
+	return 0
+}
+
+// stickyFlags defines the flags of sticky comments.
+func stickyFlags(flags *flag.FlagSet) *publish.Sticky {
+	s := &publish.Sticky{}
+	flags.StringVar(&s.Label, "label", "", "label of the hidden marker, to keep separate comments for several plans (e.g. workspaces) on a pull request")
+	flags.StringVar(&s.Behavior, "behavior", publish.BehaviorUpdate, "how to treat comments posted before: new, update, recreate or delete-orphaned")
+	return s
+}
+
+func runPostConfluence(args []string) int {
+	flags := flag.NewFlagSet("post confluence", flag.ExitOnError)
+	c := publish.Confluence{Token: os.Getenv("CONFLUENCE_TOKEN")}
+	flags.StringVar(&c.BaseURL, "url", os.Getenv("CONFLUENCE_URL"), "URL of the Confluence site, e.g. https://example.atlassian.net/wiki (default: $CONFLUENCE_URL)")
+	flags.StringVar(&c.User, "user", os.Getenv("CONFLUENCE_USER"), "user name or email address for basic auth; the token ($CONFLUENCE_TOKEN) is sent as a bearer token when empty (default: $CONFLUENCE_USER)")
+	flags.StringVar(&c.SpaceKey, "space", "", "key of the space of the page")
+	flags.StringVar(&c.Title, "title", "", "title of the page, which is created when it does not exist")
+	flags.StringVar(&c.PageID, "page-id", "", "ID of the page to update instead of looking it up by space and title")
+	flags.StringVar(&c.ParentID, "parent-id", "", "ID of the parent page of a created page")
+	postOpts := newPostOptions(terraform.FormatConfluence)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := c.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pageURL, err := c.Publish(context.Background(), report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot publish to Confluence: %v\n", err)
+		return 1
+	}
+	fmt.Println(pageURL)
+	return 0
+}
+
+func runPostJira(args []string) int {
+	flags := flag.NewFlagSet("post jira", flag.ExitOnError)
+	j := publish.Jira{Token: os.Getenv("JIRA_TOKEN")}
+	flags.StringVar(&j.BaseURL, "url", os.Getenv("JIRA_URL"), "URL of the Jira site, e.g. https://example.atlassian.net (default: $JIRA_URL)")
+	flags.StringVar(&j.User, "user", os.Getenv("JIRA_USER"), "user name or email address for basic auth; the token ($JIRA_TOKEN) is sent as a bearer token when empty (default: $JIRA_USER)")
+	flags.StringVar(&j.Issue, "issue", "", "key of the issue to comment on, e.g. OPS-123 (default: the first issue key in the branch name)")
+	branch := flags.String("branch", ciBranch(), "branch name to find the issue key in (default: the branch of the CI build)")
+	link := flags.String("link", "", "URL of the full report, e.g. a CI artifact, linked from the comment")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if j.Issue == "" {
+		j.Issue = publish.JiraIssueKey(*branch)
+	}
+	if err := j.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	planData, err := terraform.NewPlanData(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform plan JSON: %v\n", err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	comment, err := publish.JiraSummary(planData, *link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot render: %v\n", err)
+		return 1
+	}
+	commentURL, err := j.Comment(context.Background(), comment)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot comment on Jira issue: %v\n", err)
+		return 1
+	}
+	fmt.Println(commentURL)
+	return 0
+}
+
+func runPostNotion(args []string) int {
+	flags := flag.NewFlagSet("post notion", flag.ExitOnError)
+	n := publish.Notion{Token: os.Getenv("NOTION_TOKEN")}
+	flags.StringVar(&n.DatabaseID, "database-id", "", "ID of the database to create a page in")
+	flags.StringVar(&n.TitleProperty, "title-property", "Name", "name of the title property of the database")
+	flags.StringVar(&n.Title, "title", "", "title of the created page (default: the summary line)")
+	flags.StringVar(&n.PageID, "page-id", "", "ID of the page to append the report to, instead of creating a page in a database")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := n.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	planData, err := terraform.NewPlanData(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform plan JSON: %v\n", err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pageURL, err := n.Publish(context.Background(), planData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot publish to Notion: %v\n", err)
+		return 1
+	}
+	fmt.Println(pageURL)
+	return 0
+}
+
+func runPostTeams(args []string) int {
+	flags := flag.NewFlagSet("post teams", flag.ExitOnError)
+	t := publish.Teams{}
+	postOpts := newPostOptions(terraform.FormatTeams)
+	flags.StringVar(&t.WebhookURL, "webhook-url", os.Getenv("TEAMS_WEBHOOK_URL"), "URL of the incoming webhook (default: $TEAMS_WEBHOOK_URL)")
+	flags.StringVar(&postOpts.DetailsURL, "details-url", "", "URL of the full report linked from the card")
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := t.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	message, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := t.Post(context.Background(), []byte(message)); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to Teams: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runPostDiscord(args []string) int {
+	flags := flag.NewFlagSet("post discord", flag.ExitOnError)
+	d := publish.Discord{}
+	flags.StringVar(&d.WebhookURL, "webhook-url", os.Getenv("DISCORD_WEBHOOK_URL"), "URL of the webhook (default: $DISCORD_WEBHOOK_URL)")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := d.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := d.Post(context.Background(), planData, report); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to Discord: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runPostWebhook(args []string) int {
+	flags := flag.NewFlagSet("post webhook", flag.ExitOnError)
+	w := publish.Webhook{Header: http.Header{}, Secret: os.Getenv("WEBHOOK_SECRET")}
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	flags.StringVar(&w.URL, "url", os.Getenv("WEBHOOK_URL"), "URL to post the report to (default: $WEBHOOK_URL)")
+	flags.StringVar(&postOpts.Format, "format", terraform.FormatMarkdown, "output format of the report")
+	flags.StringVar(&w.ContentType, "content-type", "", "Content-Type of the request (default: the media type of the format)")
+	flags.Var(headerFlag(w.Header), "header", "request header as 'Name: value' (repeatable)")
+	flags.IntVar(&w.Retries, "retries", 3, "number of retries after network errors, 429 and 5xx responses")
+	flags.DurationVar(&w.RetryWait, "retry-wait", time.Second, "wait before the first retry, doubled for each retry")
+	flags.StringVar(&w.SignatureHeader, "signature-header", publish.DefaultSignatureHeader, "header of the HMAC-SHA256 signature of the body, sent if $WEBHOOK_SECRET is set")
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if w.ContentType == "" {
+		w.ContentType = terraform.ContentType(opts.Format)
+	}
+	if err := w.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := w.Post(context.Background(), []byte(report)); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to webhook: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runPostEmail(args []string) int {
+	flags := flag.NewFlagSet("post email", flag.ExitOnError)
+	e := publish.Email{Username: os.Getenv("SMTP_USERNAME"), Password: os.Getenv("SMTP_PASSWORD")}
+	var to listFlag
+	flags.StringVar(&e.Host, "smtp-host", os.Getenv("SMTP_HOST"), "host of the SMTP server (default: $SMTP_HOST)")
+	flags.IntVar(&e.Port, "smtp-port", 0, "port of the SMTP server (default: 587 for starttls, 465 for tls and 25 for none)")
+	flags.StringVar(&e.TLS, "smtp-tls", publish.SMTPStartTLS, "TLS of the connection: starttls, tls or none")
+	flags.StringVar(&e.From, "from", "", "sender address")
+	flags.Var(&to, "to", "recipient addresses, comma separated (repeatable)")
+	flags.StringVar(&e.Subject, "subject", "", "subject of the message (default: the summary line)")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	e.To = to
+	if err := e.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read input: %v\n", err)
+		return 1
+	}
+	var reports []string
+	var planData *terraform.PlanData
+	for _, format := range []string{terraform.FormatMarkdown, terraform.FormatHTML} {
+		opts.Format = format
+		var report string
+		report, planData, err = renderReport(bytes.NewReader(input), opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		reports = append(reports, report)
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if e.Subject == "" {
+		heading, err := planData.Heading()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot render: %v\n", err)
+			return 1
+		}
+		e.Subject = "Terraform plan: " + strings.TrimLeft(heading, "# ")
+	}
+	if err := e.Send(context.Background(), reports[0], reports[1]); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot send email: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runPostAzureDevOps(args []string) int {
+	flags := flag.NewFlagSet("post azure-devops", flag.ExitOnError)
+	a := publish.AzureDevOps{Token: os.Getenv("AZURE_DEVOPS_EXT_PAT")}
+	if a.Token == "" {
+		// The job access token is mapped to the environment by `env: SYSTEM_ACCESSTOKEN: $(System.AccessToken)`.
+		a.Token, a.Bearer = os.Getenv("SYSTEM_ACCESSTOKEN"), true
+	}
+	repository := os.Getenv("BUILD_REPOSITORY_ID")
+	if repository == "" {
+		repository = os.Getenv("BUILD_REPOSITORY_NAME")
+	}
+	flags.StringVar(&a.OrganizationURL, "organization-url", os.Getenv("SYSTEM_COLLECTIONURI"), "URL of the organization, e.g. https://dev.azure.com/example (default: $SYSTEM_COLLECTIONURI)")
+	flags.StringVar(&a.Project, "project", os.Getenv("SYSTEM_TEAMPROJECT"), "name of the project (default: $SYSTEM_TEAMPROJECT)")
+	flags.StringVar(&a.Repository, "repository", repository, "name or ID of the repository (default: $BUILD_REPOSITORY_ID)")
+	flags.StringVar(&a.PullRequestID, "pull-request-id", os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID"), "ID of the pull request (default: $SYSTEM_PULLREQUEST_PULLREQUESTID)")
+	flags.StringVar(&a.ThreadStatus, "thread-status", publish.ThreadStatusAuto, "status of the thread: auto (active if anything is destroyed or replaced, otherwise closed), active, fixed, closed, byDesign, pending or wontFix")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := a.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	threadURL, err := a.Post(context.Background(), report, planData.Summary())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to Azure DevOps: %v\n", err)
+		return 1
+	}
+	fmt.Println(threadURL)
+	return 0
+}
+
+func runPostBitbucket(args []string) int {
+	flags := flag.NewFlagSet("post bitbucket", flag.ExitOnError)
+	serverURL := flags.String("server-url", "", "URL of Bitbucket Server or Data Center; bitbucket.org is used if empty")
+	workspace := flags.String("workspace", os.Getenv("BITBUCKET_WORKSPACE"), "workspace of the repository on bitbucket.org, or the project key on Bitbucket Server (default: $BITBUCKET_WORKSPACE)")
+	repository := flags.String("repository", os.Getenv("BITBUCKET_REPO_SLUG"), "slug of the repository (default: $BITBUCKET_REPO_SLUG)")
+	pullRequestID := flags.String("pull-request-id", os.Getenv("BITBUCKET_PR_ID"), "ID of the pull request (default: $BITBUCKET_PR_ID)")
+	user := flags.String("user", os.Getenv("BITBUCKET_USER"), "user name for basic auth; the token ($BITBUCKET_TOKEN) is sent as a bearer token when empty (default: $BITBUCKET_USER)")
+	maxLength := flags.Int("max-length", 0, "maximum length of the comment, longer reports are truncated (default: 32768)")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	token := os.Getenv("BITBUCKET_TOKEN")
+
+	var post func(context.Context, string) (string, error)
+	if *serverURL == "" {
+		b := &publish.BitbucketCloud{Workspace: *workspace, Repository: *repository, PullRequestID: *pullRequestID, User: *user, Token: token, MaxLength: *maxLength}
+		post, err = b.Post, b.Validate()
+	} else {
+		b := &publish.BitbucketServer{BaseURL: *serverURL, Project: *workspace, Repository: *repository, PullRequestID: *pullRequestID, User: *user, Token: token, MaxLength: *maxLength}
+		post, err = b.Post, b.Validate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	commentURL, err := post(context.Background(), report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to Bitbucket: %v\n", err)
+		return 1
+	}
+	fmt.Println(commentURL)
+	return 0
+}
+
+func runPostSpacelift(args []string) int {
+	flags := flag.NewFlagSet("post spacelift", flag.ExitOnError)
+	s := publish.Spacelift{
+		APIKeyID:     os.Getenv("SPACELIFT_API_KEY_ID"),
+		APIKeySecret: os.Getenv("SPACELIFT_API_KEY_SECRET"),
+		Token:        os.Getenv("SPACELIFT_API_TOKEN"),
+	}
+	flags.StringVar(&s.Endpoint, "endpoint", os.Getenv("SPACELIFT_API_KEY_ENDPOINT"), "URL of the Spacelift account (default: $SPACELIFT_API_KEY_ENDPOINT)")
+	flags.StringVar(&s.Stack, "stack", os.Getenv("TF_VAR_spacelift_stack_id"), "ID of the stack (default: the stack of the run)")
+	flags.StringVar(&s.Run, "run", os.Getenv("TF_VAR_spacelift_run_id"), "ID of the run (default: the current run)")
+	postOpts := newPostOptions(terraform.FormatMarkdown)
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	if err := s.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	report, planData, err := renderReport(os.Stdin, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := s.Comment(context.Background(), report); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot post to Spacelift: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listFlag is a comma separated list which can be repeated.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// headerFlag adds 'Name: value' flags to the header.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must be 'Name: value': %q", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
+
+// ciBranch returns the branch being built from the environment variables of common CI services.
+func ciBranch() string {
+	for _, name := range []string{
+		"GITHUB_HEAD_REF",                     // GitHub Actions (pull requests)
+		"GITHUB_REF_NAME",                     // GitHub Actions
+		"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", // GitLab CI (merge requests)
+		"CI_COMMIT_REF_NAME",                  // GitLab CI
+		"BITBUCKET_BRANCH",                    // Bitbucket Pipelines
+		"SYSTEM_PULLREQUEST_SOURCEBRANCH",     // Azure Pipelines (pull requests)
+		"BUILD_SOURCEBRANCHNAME",              // Azure Pipelines
+		"CIRCLE_BRANCH",                       // CircleCI
+		"BRANCH_NAME",                         // Jenkins
+	} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// postOptions are the options of rendering the reports of a post target.
+type postOptions struct {
+	terraform.Options
+	configFile    string
+	schemaFile    string
+	failOnSecrets bool
+}
+
+// newPostOptions returns the default options of rendering reports in format.
+func newPostOptions(format string) *postOptions {
+	o := &postOptions{Options: terraform.DefaultOptions()}
+	o.Format = format
+	return o
+}
+
+// addFlags defines the flags of rendering, except --format, and --config on flags.
+// It must be called after the flags of the target, which take precedence over the flags of rendering
+// of the same names, e.g. --workspace of bitbucket.
+func (o *postOptions) addFlags(flags *flag.FlagSet) {
+	rendering := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	o.Options.AddFlags(rendering)
+	rendering.StringVar(&o.configFile, "config", "", "JSON file of options of rendering by flag name, e.g. {\"redact\": [\"AKIA[0-9A-Z]{16}\"]}; flags take precedence")
+	rendering.VisitAll(func(f *flag.Flag) {
+		if f.Name != "format" && flags.Lookup(f.Name) == nil {
+			flags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	flags.StringVar(&o.schemaFile, "schema", "", "JSON file of the output of terraform providers schema -json to mask sensitive attributes missing in the plan and to know computed attributes")
+	flags.BoolVar(&o.failOnSecrets, "fail-on-secrets", false, "do not post reports of plans with values which look like secrets, e.g. AWS access keys, exiting with 1 (mask them with --redact)")
+}
+
+// load returns the options set by the parsed flags, --config and --schema, keeping the format of the target.
+func (o *postOptions) load(flags *flag.FlagSet) (terraform.Options, error) {
+	opts := o.Options
+	if err := loadConfig(o.configFile, flags, &opts); err != nil {
+		return opts, err
+	}
+	if err := loadSchemas(o.schemaFile, &opts); err != nil {
+		return opts, err
+	}
+	opts.Format = o.Format
+	return opts, opts.Validate()
+}
+
+// checkSecrets logs the values of the plan which look like secrets,
+// and returns an error if there are any and --fail-on-secrets is given.
+func (o *postOptions) checkSecrets(planData *terraform.PlanData) error {
+	for _, w := range planData.SecretWarnings {
+		slog.Warn("possible secret in plan output", "address", w.Address, "path", w.Path, "kind", w.Kind)
+	}
+	if o.failOnSecrets && len(planData.SecretWarnings) > 0 {
+		return fmt.Errorf("not publishing the report: %d values look like secrets", len(planData.SecretWarnings))
+	}
+	return nil
+}
+
+// renderReport reads a plan from input and renders it with opts.
+func renderReport(input io.Reader, opts terraform.Options) (string, *terraform.PlanData, error) {
+	planData, err := terraform.NewPlanData(input, opts)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot parse input as Terraform plan JSON: %w", err)
+	}
+	var buffer bytes.Buffer
+	if err := planData.Render(&buffer); err != nil {
+		return "", nil, fmt.Errorf("cannot render: %w", err)
+	}
+	return buffer.String(), planData, nil
+}
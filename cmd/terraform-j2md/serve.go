@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	rendererv1 "github.com/reproio/terraform-j2md/api/renderer/v1"
+	"github.com/reproio/terraform-j2md/internal/server"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"google.golang.org/grpc"
+)
+
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	defaultAddr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		defaultAddr = ":" + port
+	}
+	addr := flags.String("addr", defaultAddr, "address to listen on (default: :$PORT or :8080)")
+	grpcAddr := flags.String("grpc-addr", "", "address to serve the gRPC service on, e.g. :9090 (default: no gRPC)")
+	s := server.New(terraform.DefaultOptions())
+	flags.Int64Var(&s.MaxBodySize, "max-body-size", server.DefaultMaxBodySize, "maximum size of posted plans in bytes")
+	flags.DurationVar(&s.RenderTimeout, "render-timeout", server.DefaultRenderTimeout, "limit of the time to render a plan, responding 503 beyond it")
+	// The flags of rendering are the defaults of requests.
+	s.Defaults.AddFlags(flags)
+	parseFlags(flags, args)
+	if err := s.Defaults.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		WriteTimeout:      time.Minute,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 2)
+	go func() { errs <- httpServer.ListenAndServe() }()
+	slog.Info("listening", "addr", *addr)
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot serve: %v\n", err)
+			return 1
+		}
+		grpcServer = grpc.NewServer(grpc.MaxRecvMsgSize(int(s.MaxBodySize)))
+		rendererv1.RegisterRendererServiceServer(grpcServer, &server.GRPCServer{Defaults: s.Defaults, RenderTimeout: s.RenderTimeout, Metrics: s.Metrics})
+		go func() { errs <- grpcServer.Serve(listener) }()
+		slog.Info("serving gRPC", "addr", *grpcAddr)
+	}
+
+	select {
+	case err := <-errs:
+		fmt.Fprintf(os.Stderr, "cannot serve: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "cannot shut down: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func runState2md(args []string) int {
+	flags := flag.NewFlagSet("state2md", flag.ExitOnError)
+	opts := terraform.DefaultOptions()
+	attributes := flags.String("attributes", strings.Join(terraform.DefaultInventoryAttributes, ","), "comma-separated key attributes of resources listed in the inventory, e.g. 'id,tags.Name'")
+	flags.IntVar(&opts.HeadingLevel, "heading-level", opts.HeadingLevel, "markdown heading level of the inventory (0 renders headings as bold text)")
+	flags.StringVar(&opts.Lang, "lang", opts.Lang, "language of generated text: en or ja")
+	parseFlags(flags, args)
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	inventory, err := terraform.NewStateInventory(os.Stdin, splitList(*attributes), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform state JSON: %v\n", err)
+		return 1
+	}
+	if err := inventory.Render(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot render: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// splitList returns the non-empty elements of a comma-separated list.
+func splitList(s string) []string {
+	list := []string{}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+const stateDiffUsage = "usage: terraform-j2md state-diff [flags] <old.json> <new.json>"
+
+func runStateDiff(args []string) int {
+	flags := flag.NewFlagSet("state-diff", flag.ExitOnError)
+	opts := terraform.DefaultOptions()
+	flags.IntVar(&opts.HeadingLevel, "heading-level", opts.HeadingLevel, "markdown heading level of the summary line (0 renders it as bold text)")
+	flags.StringVar(&opts.Lang, "lang", opts.Lang, "language of generated text: en or ja")
+	flags.StringVar(&opts.Query, "query", opts.Query, "jq expression applied to the values of each resource before diffing, e.g. 'del(.tags_all)'")
+	parseFlags(flags, args)
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, stateDiffUsage)
+		return 2
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	oldState, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the old state: %v\n", err)
+		return 1
+	}
+	defer oldState.Close()
+	newState, err := os.Open(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the new state: %v\n", err)
+		return 1
+	}
+	defer newState.Close()
+	diff, err := terraform.NewStateDiff(oldState, newState, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform state JSON: %v\n", err)
+		return 1
+	}
+	if err := diff.Render(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot render: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"github.com/reproio/terraform-j2md/internal/tui"
+)
+
+const tuiUsage = "usage: terraform-j2md tui [flags] <show.json|->"
+
+func runTUI(args []string) int {
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	opts := terraform.DefaultOptions()
+	opts.AddFlags(flags)
+	parseFlags(flags, args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, tuiUsage)
+		return 2
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open the plan: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		input = f
+	}
+	planData, err := terraform.NewPlanData(input, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot parse input as Terraform plan JSON: %v\n", err)
+		return 1
+	}
+
+	// Keys are read from the controlling terminal, since stdin may be the plan.
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the terminal: %v\n", err)
+		return 1
+	}
+	defer tty.Close()
+	if err := tui.Run(planData, tty); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot browse the plan: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func runUpload(args []string) int {
+	flags := flag.NewFlagSet("upload", flag.ExitOnError)
+	to := flags.String("to", "", "destination as s3://<bucket>/<key>, gs://<bucket>/<key> or azblob://<account>/<container>/<key>; the key is a template of .Branch, .Commit, .ShortCommit, .Date and .Timestamp")
+	planKey := flags.String("plan-key", "", "key template to upload the input plan JSON to, in the same bucket, as is without --redact (optional)")
+	branch := flags.String("branch", ciBranch(), "branch of the key template (default: the branch of the CI build)")
+	commit := flags.String("commit", ciCommit(), "commit of the key template (default: the commit of the CI build)")
+	postOpts := newPostOptions(terraform.FormatHTML)
+	flags.StringVar(&postOpts.Format, "format", terraform.FormatHTML, "output format of the report")
+	postOpts.addFlags(flags)
+	parseFlags(flags, args)
+	opts, err := postOpts.load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	store, keyTemplate, err := newObjectStore(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+	data := publish.NewObjectKeyData(*branch, *commit, time.Now())
+	key, err := publish.ExpandObjectKey(keyTemplate, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		return 2
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read input: %v\n", err)
+		return 1
+	}
+	report, planData, err := renderReport(bytes.NewReader(input), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := postOpts.checkSecrets(planData); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ctx := context.Background()
+	reportURL, err := store.Upload(ctx, key, []byte(report), terraform.ContentType(opts.Format))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot upload the report: %v\n", err)
+		return 1
+	}
+	if *planKey != "" {
+		key, err := publish.ExpandObjectKey(*planKey, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+			return 2
+		}
+		planURL, err := store.Upload(ctx, key, input, "application/json")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot upload the plan: %v\n", err)
+			return 1
+		}
+		slog.Info("uploaded the plan", "url", planURL)
+	}
+	fmt.Println(reportURL)
+	return 0
+}
+
+// newObjectStore returns the store of the destination with credentials in the environment, and the key template.
+func newObjectStore(destination string) (publish.ObjectStore, string, error) {
+	scheme, rest, ok := strings.Cut(destination, "://")
+	if !ok {
+		return nil, "", fmt.Errorf("destination must be s3://, gs:// or azblob:// URL: %q", destination)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	switch scheme {
+	case "s3":
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		return &publish.S3{
+			Bucket:          bucket,
+			Region:          region,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+		}, key, nil
+	case "gs":
+		return &publish.GCS{Bucket: bucket, AccessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}, key, nil
+	case "azblob":
+		container, key, _ := strings.Cut(key, "/")
+		return &publish.AzureBlob{
+			Account:   bucket,
+			Container: container,
+			SASToken:  os.Getenv("AZURE_STORAGE_SAS_TOKEN"),
+		}, key, nil
+	default:
+		return nil, "", fmt.Errorf("unknown storage %q (must be s3, gs or azblob)", scheme)
+	}
+}
+
+// ciCommit returns the commit being built from the environment variables of common CI services.
+func ciCommit() string {
+	for _, name := range []string{
+		"GITHUB_SHA",          // GitHub Actions
+		"CI_COMMIT_SHA",       // GitLab CI
+		"BITBUCKET_COMMIT",    // Bitbucket Pipelines
+		"BUILD_SOURCEVERSION", // Azure Pipelines
+		"CIRCLE_SHA1",         // CircleCI
+		"GIT_COMMIT",          // Jenkins
+	} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,17 +1,27 @@
 module github.com/reproio/terraform-j2md
 
-go 1.18
+go 1.25.0
 
 require (
+	github.com/aws/aws-lambda-go v1.54.0
 	github.com/hashicorp/terraform-json v0.17.2-0.20230912071934-9901d28699bc
+	github.com/itchyny/gojq v0.12.19
 	github.com/pmezard/go-difflib v1.0.0
+	golang.org/x/term v0.45.0
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.12
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
 	github.com/hashicorp/go-version v1.6.0 // indirect
+	github.com/itchyny/timefmt-go v0.1.8 // indirect
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/zclconf/go-cty v1.14.0 // indirect
-	golang.org/x/text v0.11.0 // indirect
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
 )
@@ -0,0 +1,121 @@
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// Colors of badges, which are named colors of shields.io
+const (
+	ColorNone        = "lightgrey"
+	ColorAdditive    = "brightgreen"
+	ColorChange      = "yellow"
+	ColorDestructive = "red"
+)
+
+const shieldsBaseURL = "https://img.shields.io/badge/"
+
+// Hex values of the named colors for SVG
+var colorValues = map[string]string{
+	ColorNone:        "#9f9f9f",
+	ColorAdditive:    "#4c1",
+	ColorChange:      "#dfb317",
+	ColorDestructive: "#e05d44",
+}
+
+const svgTemplateBody = `<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">
+  <title>{{.Label}}: {{.Message}}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="{{.Width}}" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+    <rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.ColorValue}}"/>
+    <rect width="{{.Width}}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{.LabelX}}" y="14">{{.Label}}</text>
+    <text x="{{.MessageX}}" y="14">{{.Message}}</text>
+  </g>
+</svg>
+`
+
+var svgTemplate = template.Must(template.New("badge").Parse(svgTemplateBody))
+
+// Badge is a shields.io-style badge.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// New returns a badge summarizing a plan, colored by the most dangerous action in it.
+func New(label string, summary terraform.Summary) Badge {
+	color := ColorNone
+	switch {
+	case summary.HasDestructiveChanges():
+		color = ColorDestructive
+	case summary.Change > 0 || summary.Moved > 0:
+		color = ColorChange
+	case summary.Add > 0:
+		color = ColorAdditive
+	}
+	return Badge{Label: label, Message: summary.Short(), Color: color}
+}
+
+// URL returns the URL of the badge on shields.io.
+func (b Badge) URL() string {
+	return shieldsBaseURL + url.PathEscape(fmt.Sprintf("%s-%s-%s", shieldsEscape(b.Label), shieldsEscape(b.Message), b.Color))
+}
+
+// shieldsEscape escapes dashes, underscores and spaces, which are special in shields.io static badges.
+func shieldsEscape(s string) string {
+	return strings.NewReplacer("-", "--", "_", "__", " ", "_").Replace(s)
+}
+
+// SVG returns the badge as an inline SVG image, which does not depend on shields.io.
+func (b Badge) SVG() (string, error) {
+	labelWidth := textWidth(b.Label)
+	messageWidth := textWidth(b.Message)
+	colorValue, ok := colorValues[b.Color]
+	if !ok {
+		colorValue = b.Color
+	}
+	data := struct {
+		Badge
+		ColorValue   string
+		Width        int
+		LabelWidth   int
+		MessageWidth int
+		LabelX       int
+		MessageX     int
+	}{
+		Badge:        b,
+		ColorValue:   colorValue,
+		Width:        labelWidth + messageWidth,
+		LabelWidth:   labelWidth,
+		MessageWidth: messageWidth,
+		LabelX:       labelWidth / 2,
+		MessageX:     labelWidth + messageWidth/2,
+	}
+	var buff bytes.Buffer
+	if err := svgTemplate.Execute(&buff, data); err != nil {
+		return "", fmt.Errorf("failed to render badge: %w", err)
+	}
+	return buff.String(), nil
+}
+
+// textWidth approximates the width of a text in 11px Verdana with padding.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)*7 + 10
+}
@@ -0,0 +1,41 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultEnv0URL is the URL of the API of env0.
+const DefaultEnv0URL = "https://api.env0.com"
+
+// Env0 downloads plans of deployments of env0 through its API.
+type Env0 struct {
+	// URL is the URL of the API (default: DefaultEnv0URL).
+	URL string
+	// APIKey and APISecret are an API key of the organization.
+	APIKey    string
+	APISecret string
+
+	HTTPClient *http.Client
+}
+
+// Fetch returns the plan JSON of the deployment, whose ID is runID.
+func (e *Env0) Fetch(ctx context.Context, runID string) ([]byte, error) {
+	if e.APIKey == "" || e.APISecret == "" {
+		return nil, errors.New("API key and secret are required")
+	}
+	if runID == "" {
+		return nil, errors.New("deployment ID is required")
+	}
+	base := e.URL
+	if base == "" {
+		base = DefaultEnv0URL
+	}
+	u := strings.TrimSuffix(base, "/") + "/deployments/" + url.PathEscape(runID) + "/plan?format=json"
+	return get(ctx, e.HTTPClient, u, "application/json", func(req *http.Request) {
+		req.SetBasicAuth(e.APIKey, e.APISecret)
+	})
+}
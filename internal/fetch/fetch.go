@@ -0,0 +1,52 @@
+// Package fetch downloads plan JSON from the APIs of services running terraform.
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxPlanSize is the maximum size of downloaded plans.
+const maxPlanSize = 512 << 20
+
+// Fetcher downloads the plan JSON of a run.
+type Fetcher interface {
+	Fetch(ctx context.Context, runID string) ([]byte, error)
+}
+
+// get sends a GET request of u and returns the body. Redirects to pre-signed URLs are followed without the credentials of setAuth.
+func get(ctx context.Context, client *http.Client, u, accept string, setAuth func(*http.Request)) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	setAuth(req)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	slog.Debug("fetched", "host", req.URL.Host, "status", res.StatusCode, "duration", time.Since(start))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, fmt.Errorf("GET %s: %d %s: %s", u, res.StatusCode, http.StatusText(res.StatusCode), strings.TrimSpace(string(b)))
+	}
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxPlanSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", u, err)
+	}
+	if len(b) > maxPlanSize {
+		return nil, fmt.Errorf("GET %s: plan is larger than %d bytes", u, maxPlanSize)
+	}
+	return b, nil
+}
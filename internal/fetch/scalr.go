@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Scalr downloads plans of runs of Scalr through its API, which is compatible with the API of Terraform Cloud.
+type Scalr struct {
+	// URL is the URL of the account, e.g. https://example.scalr.io
+	URL   string
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// Fetch returns the plan JSON of the run.
+func (s *Scalr) Fetch(ctx context.Context, runID string) ([]byte, error) {
+	if s.URL == "" || s.Token == "" {
+		return nil, errors.New("URL and token are required")
+	}
+	if runID == "" {
+		return nil, errors.New("run ID is required")
+	}
+	b, err := get(ctx, s.HTTPClient, s.apiURL("runs", runID), "application/vnd.api+json", s.setAuth)
+	if err != nil {
+		return nil, err
+	}
+	var run struct {
+		Data struct {
+			Relationships struct {
+				Plan struct {
+					Data struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				} `json:"plan"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &run); err != nil {
+		return nil, fmt.Errorf("cannot decode the run %s: %w", runID, err)
+	}
+	planID := run.Data.Relationships.Plan.Data.ID
+	if planID == "" {
+		return nil, fmt.Errorf("run %s has no plan", runID)
+	}
+	return get(ctx, s.HTTPClient, s.apiURL("plans", planID, "json-output"), "application/json", s.setAuth)
+}
+
+func (s *Scalr) apiURL(segments ...string) string {
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.TrimSuffix(s.URL, "/") + "/api/iacp/v3/" + strings.Join(segments, "/")
+}
+
+func (s *Scalr) setAuth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+}
@@ -2,6 +2,8 @@ package format
 
 import (
 	"encoding/json"
+	"strings"
+
 	tfjson "github.com/hashicorp/terraform-json"
 )
 
@@ -39,8 +41,10 @@ func formatJsonChangeValue(old interface{}) (interface{}, error) {
 			x[k] = result
 		}
 	case string:
-		var j json.RawMessage
-		if json.Valid([]byte(old.(string))) && json.Unmarshal([]byte(old.(string)), &j) == nil {
+		// Decode into generic values (not json.RawMessage) so that object keys are sorted when marshaled again.
+		// Numbers are kept as json.Number to preserve their original representation.
+		var j interface{}
+		if json.Valid([]byte(x)) && decodeJson(x, &j) == nil {
 			a, err := json.MarshalIndent(j, "", "  ")
 			if err != nil {
 				return "", err
@@ -51,3 +55,9 @@ func formatJsonChangeValue(old interface{}) (interface{}, error) {
 
 	return old, nil
 }
+
+func decodeJson(s string, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
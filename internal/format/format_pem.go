@@ -0,0 +1,107 @@
+package format
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const pemBeginMarker = "-----BEGIN "
+
+// FormatPemChange replaces PEM blocks in attribute values with a one-line summary,
+// so certificates and keys do not produce hundreds of base64 lines in diffs.
+func FormatPemChange(change *tfjson.Change) (*tfjson.Change, error) {
+	change.Before = formatPemChangeValue(change.Before)
+	change.After = formatPemChangeValue(change.After)
+
+	return change, nil
+}
+
+func formatPemChangeValue(old interface{}) interface{} {
+	switch x := old.(type) {
+	case []interface{}:
+		for i, v := range x {
+			x[i] = formatPemChangeValue(v)
+		}
+	case map[string]interface{}:
+		for k, v := range x {
+			x[k] = formatPemChangeValue(v)
+		}
+	case string:
+		return summarizePemBlocks(x)
+	}
+
+	return old
+}
+
+func summarizePemBlocks(s string) string {
+	if !strings.Contains(s, pemBeginMarker) {
+		return s
+	}
+
+	var b strings.Builder
+	rest := s
+	for {
+		i := strings.Index(rest, pemBeginMarker)
+		if i < 0 {
+			break
+		}
+		// pem.Decode skips malformed blocks, so decode only up to the next
+		// marker to keep a broken block's text instead of dropping it.
+		end := len(rest)
+		if j := strings.Index(rest[i+len(pemBeginMarker):], pemBeginMarker); j >= 0 {
+			end = i + len(pemBeginMarker) + j
+		}
+		block, next := pem.Decode([]byte(rest[i:end]))
+		if block == nil {
+			b.WriteString(rest[:end])
+			rest = rest[end:]
+			continue
+		}
+		b.WriteString(rest[:i])
+		b.WriteString(summarizePemBlock(block))
+		consumed := rest[i : end-len(next)]
+		if strings.HasSuffix(consumed, "\n") {
+			b.WriteString("\n")
+		}
+		rest = rest[end-len(next):]
+	}
+	b.WriteString(rest)
+
+	return b.String()
+}
+
+func summarizePemBlock(block *pem.Block) string {
+	switch {
+	case strings.Contains(block.Type, "PRIVATE KEY"):
+		return fmt.Sprintf("%s (redacted)", block.Type)
+	case block.Type == "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Sprintf("%s (unparsable, sha256: %s)", block.Type, fingerprint(block.Bytes))
+		}
+		return fmt.Sprintf("%s (subject: %s, issuer: %s, expires: %s, sha256: %s)",
+			block.Type,
+			cert.Subject.String(),
+			cert.Issuer.String(),
+			cert.NotAfter.UTC().Format(time.RFC3339),
+			fingerprint(block.Bytes),
+		)
+	default:
+		return fmt.Sprintf("%s (sha256: %s)", block.Type, fingerprint(block.Bytes))
+	}
+}
+
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, c := range sum {
+		parts[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,155 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+const (
+	azureDevOpsAPIVersion = "7.1"
+	// azureDevOpsMaxCommentLength is the maximum length of comments of pull request threads.
+	azureDevOpsMaxCommentLength = 150000
+)
+
+// Statuses of pull request threads
+const (
+	// ThreadStatusAuto is ThreadStatusActive if anything is destroyed or replaced, otherwise ThreadStatusClosed,
+	// so only destructive plans have to be resolved before merging when the branch policy requires it.
+	ThreadStatusAuto   = "auto"
+	ThreadStatusActive = "active"
+	ThreadStatusFixed  = "fixed"
+	ThreadStatusClosed = "closed"
+)
+
+var threadStatuses = []string{ThreadStatusAuto, ThreadStatusActive, ThreadStatusFixed, ThreadStatusClosed, "byDesign", "pending", "wontFix"}
+
+// AzureDevOps posts the report as a thread of an Azure DevOps pull request, updating the thread posted before.
+type AzureDevOps struct {
+	// OrganizationURL is the URL of the organization, e.g. https://dev.azure.com/example
+	OrganizationURL string
+	Project         string
+	// Repository is the name or ID of the repository.
+	Repository    string
+	PullRequestID string
+	// Token is a personal access token, or the job access token (System.AccessToken) if Bearer is set.
+	Token  string
+	Bearer bool
+	// ThreadStatus is the status of the thread, one of the statuses of the API or ThreadStatusAuto.
+	ThreadStatus string
+
+	HTTPClient *http.Client
+}
+
+type azureDevOpsThread struct {
+	ID       int                  `json:"id,omitempty"`
+	Status   string               `json:"status,omitempty"`
+	Comments []azureDevOpsComment `json:"comments,omitempty"`
+}
+
+type azureDevOpsComment struct {
+	ID              int    `json:"id,omitempty"`
+	ParentCommentID int    `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     int    `json:"commentType,omitempty"`
+}
+
+// Validate checks that the pull request is known.
+func (a *AzureDevOps) Validate() error {
+	if a.OrganizationURL == "" || a.Project == "" || a.Repository == "" || a.PullRequestID == "" {
+		return errors.New("organization URL, project, repository and pull request ID are required")
+	}
+	if a.Token == "" {
+		return errors.New("token is required")
+	}
+	if a.ThreadStatus != "" && !containsString(threadStatuses, a.ThreadStatus) {
+		return fmt.Errorf("unknown thread status %q (must be one of %v)", a.ThreadStatus, threadStatuses)
+	}
+	return nil
+}
+
+// Post creates the thread of body, a markdown report, or updates the comment and the status of the thread posted before.
+// It returns the URL of the thread.
+func (a *AzureDevOps) Post(ctx context.Context, body string, summary terraform.Summary) (string, error) {
+	if err := a.Validate(); err != nil {
+		return "", err
+	}
+	content := withMarker(TruncateMarkdown(body, azureDevOpsMaxCommentLength-len(stickyMarker)-3), stickyMarker)
+	status := a.ThreadStatus
+	if status == "" || status == ThreadStatusAuto {
+		status = ThreadStatusClosed
+		if summary.HasDestructiveChanges() {
+			status = ThreadStatusActive
+		}
+	}
+
+	var threads struct {
+		Value []azureDevOpsThread `json:"value"`
+	}
+	if err := doJSON(ctx, a.HTTPClient, http.MethodGet, a.apiURL("threads"), a.setAuth, nil, &threads); err != nil {
+		return "", err
+	}
+	for _, thread := range threads.Value {
+		if len(thread.Comments) == 0 || !strings.Contains(thread.Comments[0].Content, stickyMarker) {
+			continue
+		}
+		comment := thread.Comments[0]
+		u := a.apiURL("threads", fmt.Sprint(thread.ID), "comments", fmt.Sprint(comment.ID))
+		if err := doJSON(ctx, a.HTTPClient, http.MethodPatch, u, a.setAuth, map[string]string{"content": content}, nil); err != nil {
+			return "", err
+		}
+		if thread.Status != status {
+			u := a.apiURL("threads", fmt.Sprint(thread.ID))
+			if err := doJSON(ctx, a.HTTPClient, http.MethodPatch, u, a.setAuth, map[string]string{"status": status}, nil); err != nil {
+				return "", err
+			}
+		}
+		return a.threadURL(thread.ID), nil
+	}
+
+	thread := azureDevOpsThread{
+		Status:   status,
+		Comments: []azureDevOpsComment{{ParentCommentID: 0, Content: content, CommentType: 1}},
+	}
+	var created azureDevOpsThread
+	if err := doJSON(ctx, a.HTTPClient, http.MethodPost, a.apiURL("threads"), a.setAuth, thread, &created); err != nil {
+		return "", err
+	}
+	return a.threadURL(created.ID), nil
+}
+
+func (a *AzureDevOps) apiURL(segments ...string) string {
+	path := []string{a.Project, "_apis", "git", "repositories", a.Repository, "pullRequests", a.PullRequestID}
+	path = append(path, segments...)
+	for i, s := range path {
+		path[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(a.OrganizationURL, "/") + "/" + strings.Join(path, "/") + "?api-version=" + azureDevOpsAPIVersion
+}
+
+func (a *AzureDevOps) threadURL(id int) string {
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%s?discussionId=%d",
+		strings.TrimSuffix(a.OrganizationURL, "/"), url.PathEscape(a.Project), url.PathEscape(a.Repository), url.PathEscape(a.PullRequestID), id)
+}
+
+func (a *AzureDevOps) setAuth(req *http.Request) {
+	if a.Bearer {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	} else {
+		req.SetBasicAuth("", a.Token)
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	bitbucketCloudAPIURL = "https://api.bitbucket.org/2.0"
+
+	// Maximum lengths of pull request comments
+	bitbucketCloudMaxCommentLength  = 32768
+	bitbucketServerMaxCommentLength = 32768
+
+	// bitbucketMarker is a link reference definition, which is not rendered,
+	// since Bitbucket does not render HTML comments as hidden.
+	bitbucketMarker = "[//]: # (terraform-j2md)"
+)
+
+// Bitbucket does not render HTML, so collapsible sections are replaced with bold titles.
+var (
+	detailsOpenPattern  = regexp.MustCompile(`(?m)^<details><summary>(.*)</summary>$`)
+	detailsClosePattern = regexp.MustCompile(`(?m)^</details>\n?`)
+)
+
+// BitbucketCloud posts the report as a comment of a pull request on bitbucket.org, updating the comment posted before.
+type BitbucketCloud struct {
+	// BaseURL is the URL of the API (default: https://api.bitbucket.org/2.0).
+	BaseURL    string
+	Workspace  string
+	Repository string
+	// PullRequestID is the ID of the pull request.
+	PullRequestID string
+	// User is the user name for basic auth with an app password in Token.
+	// When empty, Token is sent as a bearer token (repository, project or workspace access token).
+	User  string
+	Token string
+	// MaxLength is the maximum length of comments (default: 32768).
+	MaxLength int
+
+	HTTPClient *http.Client
+}
+
+type bitbucketCloudComment struct {
+	ID      int `json:"id"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Deleted bool `json:"deleted"`
+	Links   struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// Validate checks that the pull request is known.
+func (b *BitbucketCloud) Validate() error {
+	if b.Workspace == "" || b.Repository == "" || b.PullRequestID == "" {
+		return errors.New("workspace, repository and pull request ID are required")
+	}
+	if b.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+// Post creates a comment of body, a markdown report, or updates the comment posted before, and returns the URL of the comment.
+func (b *BitbucketCloud) Post(ctx context.Context, body string) (string, error) {
+	if err := b.Validate(); err != nil {
+		return "", err
+	}
+	maxLength := b.MaxLength
+	if maxLength == 0 {
+		maxLength = bitbucketCloudMaxCommentLength
+	}
+	comment := map[string]map[string]string{"content": {"raw": bitbucketComment(body, maxLength)}}
+
+	// Comments are paginated, and the next page is linked from each page.
+	u := b.apiURL("comments") + "?pagelen=100"
+	for u != "" {
+		var page struct {
+			Values []bitbucketCloudComment `json:"values"`
+			Next   string                  `json:"next"`
+		}
+		if err := doJSON(ctx, b.HTTPClient, http.MethodGet, u, b.setAuth, nil, &page); err != nil {
+			return "", err
+		}
+		for _, c := range page.Values {
+			if c.Deleted || !strings.Contains(c.Content.Raw, bitbucketMarker) {
+				continue
+			}
+			var updated bitbucketCloudComment
+			if err := doJSON(ctx, b.HTTPClient, http.MethodPut, b.apiURL("comments", fmt.Sprint(c.ID)), b.setAuth, comment, &updated); err != nil {
+				return "", err
+			}
+			return updated.Links.HTML.Href, nil
+		}
+		u = page.Next
+	}
+
+	var created bitbucketCloudComment
+	if err := doJSON(ctx, b.HTTPClient, http.MethodPost, b.apiURL("comments"), b.setAuth, comment, &created); err != nil {
+		return "", err
+	}
+	return created.Links.HTML.Href, nil
+}
+
+func (b *BitbucketCloud) apiURL(segments ...string) string {
+	base := b.BaseURL
+	if base == "" {
+		base = bitbucketCloudAPIURL
+	}
+	path := append([]string{"repositories", b.Workspace, b.Repository, "pullrequests", b.PullRequestID}, segments...)
+	for i, s := range path {
+		path[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.Join(path, "/")
+}
+
+func (b *BitbucketCloud) setAuth(req *http.Request) {
+	if b.User != "" {
+		req.SetBasicAuth(b.User, b.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	}
+}
+
+// BitbucketServer posts the report as a comment of a pull request on Bitbucket Server or Data Center,
+// updating the comment posted before.
+type BitbucketServer struct {
+	// BaseURL is the URL of the server, e.g. https://bitbucket.example.com
+	BaseURL    string
+	Project    string
+	Repository string
+	// PullRequestID is the ID of the pull request.
+	PullRequestID string
+	// User is the user name for basic auth with a password in Token.
+	// When empty, Token is sent as a bearer token (HTTP access token).
+	User  string
+	Token string
+	// MaxLength is the maximum length of comments (default: 32768).
+	MaxLength int
+
+	HTTPClient *http.Client
+}
+
+type bitbucketServerComment struct {
+	ID      int    `json:"id,omitempty"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// Validate checks that the pull request is known.
+func (b *BitbucketServer) Validate() error {
+	if b.BaseURL == "" || b.Project == "" || b.Repository == "" || b.PullRequestID == "" {
+		return errors.New("base URL, project, repository and pull request ID are required")
+	}
+	if b.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+// Post creates a comment of body, a markdown report, or updates the comment posted before, and returns the URL of the comment.
+func (b *BitbucketServer) Post(ctx context.Context, body string) (string, error) {
+	if err := b.Validate(); err != nil {
+		return "", err
+	}
+	maxLength := b.MaxLength
+	if maxLength == 0 {
+		maxLength = bitbucketServerMaxCommentLength
+	}
+	text := bitbucketComment(body, maxLength)
+
+	// Comments are found in the activities of the pull request, which are paginated.
+	start := 0
+	for {
+		var page struct {
+			Values []struct {
+				Action  string                  `json:"action"`
+				Comment *bitbucketServerComment `json:"comment"`
+			} `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+		u := fmt.Sprintf("%s?start=%d&limit=100", b.apiURL("activities"), start)
+		if err := doJSON(ctx, b.HTTPClient, http.MethodGet, u, b.setAuth, nil, &page); err != nil {
+			return "", err
+		}
+		for _, activity := range page.Values {
+			c := activity.Comment
+			if activity.Action != "COMMENTED" || c == nil || !strings.Contains(c.Text, bitbucketMarker) {
+				continue
+			}
+			// The version is required to update the comment, to detect concurrent edits.
+			update := bitbucketServerComment{Version: c.Version, Text: text}
+			if err := doJSON(ctx, b.HTTPClient, http.MethodPut, b.apiURL("comments", fmt.Sprint(c.ID)), b.setAuth, update, nil); err != nil {
+				return "", err
+			}
+			return b.commentURL(c.ID), nil
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	var created bitbucketServerComment
+	if err := doJSON(ctx, b.HTTPClient, http.MethodPost, b.apiURL("comments"), b.setAuth, map[string]string{"text": text}, &created); err != nil {
+		return "", err
+	}
+	return b.commentURL(created.ID), nil
+}
+
+func (b *BitbucketServer) apiURL(segments ...string) string {
+	path := append([]string{"rest", "api", "1.0", "projects", b.Project, "repos", b.Repository, "pull-requests", b.PullRequestID}, segments...)
+	for i, s := range path {
+		path[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(b.BaseURL, "/") + "/" + strings.Join(path, "/")
+}
+
+func (b *BitbucketServer) commentURL(id int) string {
+	return fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%s/overview?commentId=%d",
+		strings.TrimSuffix(b.BaseURL, "/"), url.PathEscape(b.Project), url.PathEscape(b.Repository), url.PathEscape(b.PullRequestID), id)
+}
+
+func (b *BitbucketServer) setAuth(req *http.Request) {
+	if b.User != "" {
+		req.SetBasicAuth(b.User, b.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	}
+}
+
+// bitbucketComment returns the comment of a markdown report without HTML, within maxLength.
+func bitbucketComment(body string, maxLength int) string {
+	body = detailsOpenPattern.ReplaceAllString(body, "**$1**")
+	body = detailsClosePattern.ReplaceAllString(body, "")
+	return withMarker(TruncateMarkdown(body, maxLength-len(bitbucketMarker)-3), bitbucketMarker)
+}
@@ -0,0 +1,177 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Confluence creates or updates a Confluence page through the REST API.
+//
+// The page is identified by PageID, or by SpaceKey and Title. A page found by its title is updated,
+// and a new page is created in the space when there is none.
+type Confluence struct {
+	// BaseURL is the URL of the Confluence site, e.g. https://example.atlassian.net/wiki
+	BaseURL string
+	// User is the user name (or email address on Atlassian Cloud) for basic auth.
+	// When empty, Token is sent as a bearer token (personal access token of Confluence Data Center).
+	User  string
+	Token string
+
+	SpaceKey string
+	Title    string
+	PageID   string
+	// ParentID is the ID of the parent page of created pages (optional).
+	ParentID string
+
+	HTTPClient *http.Client
+}
+
+type confluencePage struct {
+	ID        string               `json:"id,omitempty"`
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Space     *confluenceSpace     `json:"space,omitempty"`
+	Ancestors []confluenceAncestor `json:"ancestors,omitempty"`
+	Version   *confluenceVersion   `json:"version,omitempty"`
+	Body      *confluencePageBody  `json:"body,omitempty"`
+	Links     *confluencePageLinks `json:"_links,omitempty"`
+}
+
+type confluenceSpace struct {
+	Key string `json:"key"`
+}
+
+type confluenceAncestor struct {
+	ID string `json:"id"`
+}
+
+type confluenceVersion struct {
+	Number  int    `json:"number"`
+	Message string `json:"message,omitempty"`
+}
+
+type confluencePageBody struct {
+	Storage confluenceStorage `json:"storage"`
+}
+
+type confluenceStorage struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+type confluencePageLinks struct {
+	Base  string `json:"base,omitempty"`
+	WebUI string `json:"webui,omitempty"`
+}
+
+// Validate checks that the page can be identified.
+func (c *Confluence) Validate() error {
+	if c.BaseURL == "" {
+		return errors.New("confluence base URL is required")
+	}
+	if c.PageID == "" && (c.SpaceKey == "" || c.Title == "") {
+		return errors.New("either a page ID, or a space key and a title is required")
+	}
+	return nil
+}
+
+// Publish writes body, a document in the storage format, to the page and returns the URL of the page.
+// Updates increment the version of the page so that the history of reports is kept.
+func (c *Confluence) Publish(ctx context.Context, body string) (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	page, err := c.findPage(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	update := confluencePage{
+		Type: "page",
+		Body: &confluencePageBody{Storage: confluenceStorage{Value: body, Representation: "storage"}},
+	}
+	var result confluencePage
+	if page == nil {
+		update.Title = c.Title
+		update.Space = &confluenceSpace{Key: c.SpaceKey}
+		if c.ParentID != "" {
+			update.Ancestors = []confluenceAncestor{{ID: c.ParentID}}
+		}
+		err = doJSON(ctx, c.HTTPClient, http.MethodPost, c.apiURL("content"), c.setAuth, update, &result)
+	} else {
+		update.ID = page.ID
+		update.Title = page.Title
+		if c.Title != "" {
+			update.Title = c.Title
+		}
+		number := 1
+		if page.Version != nil {
+			number = page.Version.Number + 1
+		}
+		update.Version = &confluenceVersion{Number: number, Message: "Updated by terraform-j2md"}
+		err = doJSON(ctx, c.HTTPClient, http.MethodPut, c.apiURL("content", page.ID), c.setAuth, update, &result)
+	}
+	if err != nil {
+		return "", err
+	}
+	return c.pageURL(&result), nil
+}
+
+// findPage returns the page to update, or nil if a new page should be created.
+func (c *Confluence) findPage(ctx context.Context) (*confluencePage, error) {
+	if c.PageID != "" {
+		var page confluencePage
+		u := c.apiURL("content", c.PageID) + "?expand=version"
+		if err := doJSON(ctx, c.HTTPClient, http.MethodGet, u, c.setAuth, nil, &page); err != nil {
+			return nil, err
+		}
+		return &page, nil
+	}
+
+	var found struct {
+		Results []confluencePage `json:"results"`
+	}
+	query := url.Values{}
+	query.Set("spaceKey", c.SpaceKey)
+	query.Set("title", c.Title)
+	query.Set("expand", "version")
+	if err := doJSON(ctx, c.HTTPClient, http.MethodGet, c.apiURL("content")+"?"+query.Encode(), c.setAuth, nil, &found); err != nil {
+		return nil, err
+	}
+	if len(found.Results) == 0 {
+		return nil, nil
+	}
+	return &found.Results[0], nil
+}
+
+func (c *Confluence) apiURL(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/rest/api/" + strings.Join(escaped, "/")
+}
+
+func (c *Confluence) pageURL(page *confluencePage) string {
+	if page.Links != nil && page.Links.WebUI != "" {
+		base := page.Links.Base
+		if base == "" {
+			base = strings.TrimSuffix(c.BaseURL, "/")
+		}
+		return base + page.Links.WebUI
+	}
+	return fmt.Sprintf("%s/pages/viewpage.action?pageId=%s", strings.TrimSuffix(c.BaseURL, "/"), url.QueryEscape(page.ID))
+}
+
+func (c *Confluence) setAuth(req *http.Request) {
+	if c.User != "" {
+		req.SetBasicAuth(c.User, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
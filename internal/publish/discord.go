@@ -0,0 +1,171 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/badge"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+const (
+	// Limits of Discord embeds
+	discordMaxTitleLength       = 256
+	discordMaxDescriptionLength = 4096
+
+	// DiscordAttachmentName is the file name of the full report attached to large plans.
+	DiscordAttachmentName = "plan.md"
+)
+
+// Colors of embeds, the same as the colors of badges
+var discordColors = map[string]int{
+	badge.ColorNone:        0x9f9f9f,
+	badge.ColorAdditive:    0x44cc11,
+	badge.ColorChange:      0xdfb317,
+	badge.ColorDestructive: 0xe05d44,
+}
+
+// Discord posts the plan to a Discord webhook as an embed.
+type Discord struct {
+	WebhookURL string
+
+	HTTPClient *http.Client
+}
+
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// Validate checks that the webhook is known.
+func (d *Discord) Validate() error {
+	if d.WebhookURL == "" {
+		return errors.New("discord webhook URL is required")
+	}
+	return nil
+}
+
+// Post sends an embed with the summary line, action counts and changed addresses, colored like badges.
+// When the addresses do not fit in the embed, they are cut off and report (the full report) is attached as plan.md.
+func (d *Discord) Post(ctx context.Context, plan *terraform.PlanData, report string) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	embed, truncated, err := discordSummary(plan)
+	if err != nil {
+		return err
+	}
+	message := discordMessage{Embeds: []discordEmbed{embed}}
+	if !truncated {
+		return doJSON(ctx, d.HTTPClient, http.MethodPost, d.WebhookURL, nil, message, nil)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("payload_json", string(payload)); err != nil {
+		return err
+	}
+	fw, err := mw.CreateFormFile("files[0]", DiscordAttachmentName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(fw, report); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	return doRequest(ctx, d.HTTPClient, http.MethodPost, d.WebhookURL, mw.FormDataContentType(), &body, nil, nil)
+}
+
+// discordSummary returns the embed of the plan and whether the addresses are cut off.
+func discordSummary(plan *terraform.PlanData) (discordEmbed, bool, error) {
+	heading, err := plan.Heading()
+	if err != nil {
+		return discordEmbed{}, false, err
+	}
+	summary := plan.Summary()
+	embed := discordEmbed{
+		Title: truncateRunes(strings.TrimLeft(heading, "# "), discordMaxTitleLength),
+		Color: discordColors[badge.New("", summary).Color],
+	}
+	for _, f := range []struct {
+		name  string
+		count int
+	}{
+		{"Add", summary.Add},
+		{"Change", summary.Change},
+		{"Destroy", summary.Destroy},
+		{"Replace", summary.Replace},
+	} {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: f.name, Value: strconv.Itoa(f.count), Inline: true})
+	}
+
+	var lines []string
+	for _, g := range []struct {
+		label     string
+		addresses []string
+	}{
+		{"add", plan.CreatedAddresses},
+		{"change", plan.UpdatedAddresses},
+		{"destroy", plan.DeletedAddresses},
+		{"replace", plan.ReplacedAddresses},
+	} {
+		if len(g.addresses) == 0 {
+			continue
+		}
+		lines = append(lines, "**"+plan.Message(g.label)+"**")
+		for _, address := range g.addresses {
+			lines = append(lines, "`"+strings.ReplaceAll(address, "`", "'")+"`")
+		}
+	}
+
+	description := strings.Join(lines, "\n")
+	if len(description) <= discordMaxDescriptionLength {
+		embed.Description = description
+		return embed, false, nil
+	}
+	// The description is cut off at a line so that no code span is broken.
+	const notice = "… see the attached " + DiscordAttachmentName
+	var b strings.Builder
+	for _, line := range lines {
+		if b.Len()+len(line)+1+len(notice) > discordMaxDescriptionLength {
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(notice)
+	embed.Description = b.String()
+	return embed, true, nil
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
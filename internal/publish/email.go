@@ -0,0 +1,190 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes of SMTP connections
+const (
+	// SMTPStartTLS upgrades the connection with STARTTLS, usually on port 587.
+	SMTPStartTLS = "starttls"
+	// SMTPImplicitTLS connects with TLS, usually on port 465.
+	SMTPImplicitTLS = "tls"
+	// SMTPNoTLS sends the message in plain text, e.g. to a relay on localhost.
+	SMTPNoTLS = "none"
+)
+
+// Email sends the report by mail through an SMTP server.
+type Email struct {
+	Host string
+	Port int
+	// TLS is SMTPStartTLS, SMTPImplicitTLS or SMTPNoTLS.
+	TLS string
+	// Username and Password are used for PLAIN authentication if Username is not empty.
+	Username string
+	Password string
+
+	From    string
+	To      []string
+	Subject string
+
+	// TLSConfig is the configuration of TLS connections (optional).
+	TLSConfig *tls.Config
+}
+
+// Validate checks that the server and the addresses are valid.
+func (e *Email) Validate() error {
+	if e.Host == "" {
+		return errors.New("SMTP host is required")
+	}
+	if e.TLS != SMTPStartTLS && e.TLS != SMTPImplicitTLS && e.TLS != SMTPNoTLS {
+		return fmt.Errorf("TLS mode must be %s, %s or %s: %q", SMTPStartTLS, SMTPImplicitTLS, SMTPNoTLS, e.TLS)
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", e.From, err)
+	}
+	if len(e.To) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient address %q: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// Message returns the message with the HTML report and the markdown report as its plain text alternative.
+func (e *Email) Message(text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		// The preferred alternative comes last.
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	for _, h := range []struct{ name, value string }{
+		{"From", e.From},
+		{"To", strings.Join(e.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", e.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	} {
+		fmt.Fprintf(&message, "%s: %s\r\n", h.name, h.value)
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// Send sends the message with the reports.
+func (e *Email) Send(ctx context.Context, text, html string) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	message, err := e.Message(text, html)
+	if err != nil {
+		return err
+	}
+
+	port := e.Port
+	if port == 0 {
+		port = map[string]int{SMTPStartTLS: 587, SMTPImplicitTLS: 465, SMTPNoTLS: 25}[e.TLS]
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	tlsConfig := e.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: e.Host}
+	}
+	var dialer net.Dialer
+	var conn net.Conn
+	if e.TLS == SMTPImplicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: &dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.TLS == SMTPStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+	if e.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("authentication: %w", err)
+		}
+	}
+	from, _ := mail.ParseAddress(e.From)
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		rcpt, _ := mail.ParseAddress(to)
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	slog.Debug("sent email", "addr", addr, "recipients", len(e.To), "size", len(message))
+	return c.Quit()
+}
@@ -0,0 +1,260 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+const (
+	// DefaultGitHubAPIURL is the API URL of github.com. GitHub Enterprise Server serves the API at https://<host>/api/v3.
+	DefaultGitHubAPIURL = "https://api.github.com"
+	githubAPIVersion    = "2022-11-28"
+
+	// githubMaxCommentLength is the maximum length of issue and pull request comments.
+	githubMaxCommentLength = 65536
+)
+
+// GitHub posts reports to a repository on github.com or GitHub Enterprise Server through the REST API.
+type GitHub struct {
+	// APIURL is the URL of the API (default: DefaultGitHubAPIURL).
+	APIURL string
+	Token  string
+	// Repository is the name of the repository as owner/name.
+	Repository string
+	// App authenticates as a GitHub App instead of Token when its AppID is set.
+	App *GitHubApp
+
+	HTTPClient *http.Client
+
+	appToken string
+}
+
+type githubComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Validate checks that the repository is known.
+func (g *GitHub) Validate() error {
+	if owner, name, ok := strings.Cut(g.Repository, "/"); !ok || owner == "" || name == "" {
+		return fmt.Errorf("repository must be owner/name: %q", g.Repository)
+	}
+	if g.usesApp() {
+		if g.App.PrivateKey == "" {
+			return errors.New("private key of the GitHub App is required")
+		}
+	} else if g.Token == "" {
+		return errors.New("token or GitHub App is required")
+	}
+	return nil
+}
+
+func (g *GitHub) usesApp() bool {
+	return g.App != nil && g.App.AppID != ""
+}
+
+// authenticate validates g and fetches an installation access token of the GitHub App if needed.
+func (g *GitHub) authenticate(ctx context.Context) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	if !g.usesApp() || g.appToken != "" {
+		return nil
+	}
+	token, err := g.App.installationToken(ctx, g)
+	if err != nil {
+		return fmt.Errorf("cannot authenticate as the GitHub App: %w", err)
+	}
+	g.appToken = token
+	return nil
+}
+
+// Post posts body, a markdown report, as a comment on the pull request according to sticky,
+// and returns the URL of the comment.
+func (g *GitHub) Post(ctx context.Context, pullRequest int, body string, sticky Sticky) (string, error) {
+	if err := g.authenticate(ctx); err != nil {
+		return "", err
+	}
+	if err := sticky.Validate(); err != nil {
+		return "", err
+	}
+	body = TruncateMarkdown(body, githubMaxCommentLength-len(sticky.Marker())-3)
+	return sticky.post(ctx, &githubThread{github: g, pullRequest: pullRequest}, body)
+}
+
+// githubThread is the comments of a pull request, which are comments of the issue of the pull request.
+type githubThread struct {
+	github      *GitHub
+	pullRequest int
+}
+
+func (t *githubThread) list(ctx context.Context) ([]postedComment, error) {
+	var comments []postedComment
+	for page := 1; ; page++ {
+		var got []githubComment
+		u := fmt.Sprintf("%s?per_page=100&page=%d", t.github.apiURL("issues", fmt.Sprint(t.pullRequest), "comments"), page)
+		if err := doJSON(ctx, t.github.HTTPClient, http.MethodGet, u, t.github.setAuth, nil, &got); err != nil {
+			return nil, err
+		}
+		for _, c := range got {
+			comments = append(comments, postedComment{ID: fmt.Sprint(c.ID), Body: c.Body})
+		}
+		if len(got) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+func (t *githubThread) create(ctx context.Context, body string) (string, error) {
+	var created githubComment
+	u := t.github.apiURL("issues", fmt.Sprint(t.pullRequest), "comments")
+	if err := doJSON(ctx, t.github.HTTPClient, http.MethodPost, u, t.github.setAuth, map[string]string{"body": body}, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+func (t *githubThread) update(ctx context.Context, id, body string) (string, error) {
+	var updated githubComment
+	u := t.github.apiURL("issues", "comments", id)
+	if err := doJSON(ctx, t.github.HTTPClient, http.MethodPatch, u, t.github.setAuth, map[string]string{"body": body}, &updated); err != nil {
+		return "", err
+	}
+	return updated.HTMLURL, nil
+}
+
+func (t *githubThread) delete(ctx context.Context, id string) error {
+	return doJSON(ctx, t.github.HTTPClient, http.MethodDelete, t.github.apiURL("issues", "comments", id), t.github.setAuth, nil, nil)
+}
+
+// apiURL returns the URL of the API of the repository.
+func (g *GitHub) apiURL(segments ...string) string {
+	base := g.baseURL()
+	owner, name, _ := strings.Cut(g.Repository, "/")
+	path := append([]string{"repos", owner, name}, segments...)
+	for i, s := range path {
+		path[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.Join(path, "/")
+}
+
+func (g *GitHub) baseURL() string {
+	if g.APIURL == "" {
+		return DefaultGitHubAPIURL
+	}
+	return g.APIURL
+}
+
+func (g *GitHub) setAuth(req *http.Request) {
+	token := g.Token
+	if g.usesApp() {
+		token = g.appToken
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", githubAPIVersion)
+}
+
+// Conclusions of check runs and states of commit statuses
+const (
+	CheckSuccess = "success"
+	CheckNeutral = "neutral"
+	CheckFailure = "failure"
+)
+
+// githubMaxCheckOutputLength is the maximum length of the summary of check runs.
+const githubMaxCheckOutputLength = 65535
+
+// githubMaxStatusDescriptionLength is the maximum length of descriptions of commit statuses.
+const githubMaxStatusDescriptionLength = 140
+
+// CheckRun is a completed check run of a commit.
+type CheckRun struct {
+	Name    string
+	HeadSHA string
+	// Conclusion is CheckSuccess, CheckNeutral or CheckFailure.
+	Conclusion string
+	// Title is the one-line summary shown in the checks list.
+	Title string
+	// Summary is the markdown report shown on the page of the check run.
+	Summary    string
+	DetailsURL string
+}
+
+// CreateCheckRun creates the check run and returns the URL of its page.
+// Check runs can be created with tokens of GitHub Apps, including GITHUB_TOKEN of GitHub Actions.
+func (g *GitHub) CreateCheckRun(ctx context.Context, run CheckRun) (string, error) {
+	if err := g.authenticate(ctx); err != nil {
+		return "", err
+	}
+	in := map[string]any{
+		"name":       run.Name,
+		"head_sha":   run.HeadSHA,
+		"status":     "completed",
+		"conclusion": run.Conclusion,
+		"output": map[string]string{
+			"title":   run.Title,
+			"summary": TruncateMarkdown(run.Summary, githubMaxCheckOutputLength),
+		},
+	}
+	if run.DetailsURL != "" {
+		in["details_url"] = run.DetailsURL
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := doJSON(ctx, g.HTTPClient, http.MethodPost, g.apiURL("check-runs"), g.setAuth, in, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+// CommitStatus is a status of a commit.
+type CommitStatus struct {
+	// Context is the name of the status, e.g. "terraform plan".
+	Context string
+	SHA     string
+	// State is CheckSuccess or CheckFailure.
+	State       string
+	Description string
+	TargetURL   string
+}
+
+// CreateCommitStatus creates the status, which needs no GitHub App unlike check runs but has no report.
+func (g *GitHub) CreateCommitStatus(ctx context.Context, status CommitStatus) error {
+	if err := g.authenticate(ctx); err != nil {
+		return err
+	}
+	in := map[string]string{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": truncateRunes(status.Description, githubMaxStatusDescriptionLength),
+	}
+	if status.TargetURL != "" {
+		in["target_url"] = status.TargetURL
+	}
+	return doJSON(ctx, g.HTTPClient, http.MethodPost, g.apiURL("statuses", status.SHA), g.setAuth, in, nil)
+}
+
+// CheckTitle returns the one-line summary of checks, like "+3 ~2 -0, no destroys".
+func CheckTitle(summary terraform.Summary) string {
+	if !summary.HasChanges() {
+		return "no changes"
+	}
+	destructive := summary.Destroy + summary.Replace
+	switch destructive {
+	case 0:
+		return summary.Short() + ", no destroys"
+	case 1:
+		return summary.Short() + ", 1 destroy"
+	default:
+		return fmt.Sprintf("%s, %d destroys", summary.Short(), destructive)
+	}
+}
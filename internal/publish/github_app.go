@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GitHubApp authenticates as an installation of a GitHub App, which has higher rate limits than personal access tokens
+// and posts as the app instead of a user.
+type GitHubApp struct {
+	AppID string
+	// PrivateKey is the PEM encoded private key of the app.
+	PrivateKey string
+	// InstallationID is the installation of the app (default: the installation on the repository).
+	InstallationID int64
+}
+
+// JWT returns a JSON Web Token of the app at t, valid for 9 minutes.
+func (a *GitHubApp) JWT(t time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(a.PrivateKey))
+	if block == nil {
+		return "", errors.New("private key of the GitHub App is not PEM encoded")
+	}
+	key, err := parseRSAPrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key of the GitHub App: %w", err)
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	// iat is a minute ago to allow clock drift, and the maximum of exp is 10 minutes later.
+	claims, _ := json.Marshal(map[string]any{
+		"iat": t.Add(-time.Minute).Unix(),
+		"exp": t.Add(9 * time.Minute).Unix(),
+		"iss": a.AppID,
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PKCS #1 key, which GitHub generates, or a PKCS #8 key.
+func parseRSAPrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// installationToken returns an installation access token of the app limited to the repository of g.
+func (a *GitHubApp) installationToken(ctx context.Context, g *GitHub) (string, error) {
+	jwt, err := a.JWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	setAuth := func(req *http.Request) {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+jwt)
+		req.Header.Set("X-GitHub-Api-Version", githubAPIVersion)
+	}
+	id := a.InstallationID
+	if id == 0 {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		if err := doJSON(ctx, g.HTTPClient, http.MethodGet, g.apiURL("installation"), setAuth, nil, &installation); err != nil {
+			return "", err
+		}
+		id = installation.ID
+	}
+	_, name, _ := strings.Cut(g.Repository, "/")
+	u := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(g.baseURL(), "/"), id)
+	var token struct {
+		Token string `json:"token"`
+	}
+	in := map[string][]string{"repositories": {name}}
+	if err := doJSON(ctx, g.HTTPClient, http.MethodPost, u, setAuth, in, &token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("POST %s: no token in the response", u)
+	}
+	return token.Token, nil
+}
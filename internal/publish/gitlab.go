@@ -0,0 +1,140 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultGitLabURL is the URL of gitlab.com.
+	DefaultGitLabURL = "https://gitlab.com"
+
+	// gitlabMaxNoteLength is the maximum length of notes.
+	gitlabMaxNoteLength = 1000000
+)
+
+// GitLab posts reports as notes of merge requests on gitlab.com or a self-managed instance through the REST API.
+type GitLab struct {
+	// URL is the URL of the instance (default: DefaultGitLabURL).
+	URL string
+	// Token is a personal, project or group access token with the api scope.
+	Token string
+	// Project is the ID or the path (group/name) of the project.
+	Project string
+
+	HTTPClient *http.Client
+}
+
+type gitlabNote struct {
+	ID     int64  `json:"id"`
+	Body   string `json:"body"`
+	System bool   `json:"system"`
+}
+
+// Validate checks that the project is known.
+func (g *GitLab) Validate() error {
+	if g.Project == "" {
+		return errors.New("project is required")
+	}
+	if g.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+// Post posts body, a markdown report, as a note of the merge request according to sticky,
+// and returns the URL of the note.
+func (g *GitLab) Post(ctx context.Context, mergeRequest int, body string, sticky Sticky) (string, error) {
+	if err := g.Validate(); err != nil {
+		return "", err
+	}
+	if err := sticky.Validate(); err != nil {
+		return "", err
+	}
+	body = TruncateMarkdown(body, gitlabMaxNoteLength-len(sticky.Marker())-3)
+	return sticky.post(ctx, &gitlabThread{gitlab: g, mergeRequest: mergeRequest}, body)
+}
+
+func (g *GitLab) apiURL(segments ...string) string {
+	base := g.URL
+	if base == "" {
+		base = DefaultGitLabURL
+	}
+	path := append([]string{"projects", g.Project}, segments...)
+	for i, s := range path {
+		path[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(base, "/") + "/api/v4/" + strings.Join(path, "/")
+}
+
+func (g *GitLab) setAuth(req *http.Request) {
+	req.Header.Set("PRIVATE-TOKEN", g.Token)
+}
+
+// gitlabThread is the notes of a merge request.
+type gitlabThread struct {
+	gitlab       *GitLab
+	mergeRequest int
+	webURL       string
+}
+
+func (t *gitlabThread) list(ctx context.Context) ([]postedComment, error) {
+	var notes []postedComment
+	for page := 1; ; page++ {
+		var got []gitlabNote
+		u := fmt.Sprintf("%s?sort=asc&order_by=created_at&per_page=100&page=%d", t.gitlab.apiURL("merge_requests", fmt.Sprint(t.mergeRequest), "notes"), page)
+		if err := doJSON(ctx, t.gitlab.HTTPClient, http.MethodGet, u, t.gitlab.setAuth, nil, &got); err != nil {
+			return nil, err
+		}
+		for _, n := range got {
+			if !n.System {
+				notes = append(notes, postedComment{ID: fmt.Sprint(n.ID), Body: n.Body})
+			}
+		}
+		if len(got) < 100 {
+			return notes, nil
+		}
+	}
+}
+
+func (t *gitlabThread) create(ctx context.Context, body string) (string, error) {
+	var created gitlabNote
+	u := t.gitlab.apiURL("merge_requests", fmt.Sprint(t.mergeRequest), "notes")
+	if err := doJSON(ctx, t.gitlab.HTTPClient, http.MethodPost, u, t.gitlab.setAuth, map[string]string{"body": body}, &created); err != nil {
+		return "", err
+	}
+	return t.noteURL(ctx, created.ID)
+}
+
+func (t *gitlabThread) update(ctx context.Context, id, body string) (string, error) {
+	var updated gitlabNote
+	u := t.gitlab.apiURL("merge_requests", fmt.Sprint(t.mergeRequest), "notes", id)
+	if err := doJSON(ctx, t.gitlab.HTTPClient, http.MethodPut, u, t.gitlab.setAuth, map[string]string{"body": body}, &updated); err != nil {
+		return "", err
+	}
+	return t.noteURL(ctx, updated.ID)
+}
+
+func (t *gitlabThread) delete(ctx context.Context, id string) error {
+	u := t.gitlab.apiURL("merge_requests", fmt.Sprint(t.mergeRequest), "notes", id)
+	return doJSON(ctx, t.gitlab.HTTPClient, http.MethodDelete, u, t.gitlab.setAuth, nil, nil)
+}
+
+// noteURL returns the URL of the note on the page of the merge request.
+func (t *gitlabThread) noteURL(ctx context.Context, id int64) (string, error) {
+	if t.webURL == "" {
+		var mr struct {
+			WebURL string `json:"web_url"`
+		}
+		u := t.gitlab.apiURL("merge_requests", fmt.Sprint(t.mergeRequest))
+		if err := doJSON(ctx, t.gitlab.HTTPClient, http.MethodGet, u, t.gitlab.setAuth, nil, &mr); err != nil {
+			return "", err
+		}
+		t.webURL = mr.WebURL
+	}
+	return fmt.Sprintf("%s#note_%d", t.webURL, id), nil
+}
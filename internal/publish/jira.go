@@ -0,0 +1,104 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// jiraEscaper escapes characters of addresses which start links, macros or emphasis in the wiki markup.
+var jiraEscaper = strings.NewReplacer("[", `\[`, "]", `\]`, "{", `\{`, "}", `\}`, "*", `\*`)
+
+var jiraIssueKeyPattern = regexp.MustCompile(`[A-Z][A-Z0-9_]+-[0-9]+`)
+
+// JiraIssueKey returns the first issue key (e.g. OPS-123) in a branch name like feature/OPS-123-add-bucket,
+// or an empty string if there is none.
+func JiraIssueKey(branch string) string {
+	return jiraIssueKeyPattern.FindString(branch)
+}
+
+// Jira adds comments to a Jira issue through the REST API.
+type Jira struct {
+	// BaseURL is the URL of the Jira site, e.g. https://example.atlassian.net
+	BaseURL string
+	// User is the user name (or email address on Atlassian Cloud) for basic auth.
+	// When empty, Token is sent as a bearer token (personal access token of Jira Data Center).
+	User  string
+	Token string
+	Issue string
+
+	HTTPClient *http.Client
+}
+
+// Validate checks that the issue is known.
+func (j *Jira) Validate() error {
+	if j.BaseURL == "" {
+		return errors.New("jira base URL is required")
+	}
+	if j.Issue == "" {
+		return errors.New("issue key is required, and no issue key is found in the branch name")
+	}
+	return nil
+}
+
+// Comment adds body, a text in the Jira wiki markup, as a comment on the issue and returns the URL of the comment.
+func (j *Jira) Comment(ctx context.Context, body string) (string, error) {
+	if err := j.Validate(); err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(j.BaseURL, "/")
+	u := fmt.Sprintf("%s/rest/api/2/issue/%s/comment", base, url.PathEscape(j.Issue))
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(ctx, j.HTTPClient, http.MethodPost, u, j.setAuth, map[string]string{"body": body}, &result); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/browse/%s?focusedCommentId=%s", base, url.PathEscape(j.Issue), url.QueryEscape(result.ID)), nil
+}
+
+func (j *Jira) setAuth(req *http.Request) {
+	if j.User != "" {
+		req.SetBasicAuth(j.User, j.Token)
+	} else if j.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+}
+
+// JiraSummary returns the summary of the plan in the Jira wiki markup:
+// the summary line, the changed addresses for each action and a link to the full report if link is not empty.
+func JiraSummary(plan *terraform.PlanData, link string) (string, error) {
+	heading, err := plan.Heading()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("*" + strings.TrimLeft(heading, "# ") + "*\n")
+	for _, g := range []struct {
+		label     string
+		addresses []string
+	}{
+		{"add", plan.CreatedAddresses},
+		{"change", plan.UpdatedAddresses},
+		{"destroy", plan.DeletedAddresses},
+		{"replace", plan.ReplacedAddresses},
+	} {
+		if len(g.addresses) == 0 {
+			continue
+		}
+		b.WriteString("* " + plan.Message(g.label) + "\n")
+		for _, address := range g.addresses {
+			b.WriteString("** {{" + jiraEscaper.Replace(address) + "}}\n")
+		}
+	}
+	if link != "" {
+		b.WriteString("\n[" + plan.Message("full_report") + "|" + link + "]\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,251 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+const (
+	notionAPIURL  = "https://api.notion.com"
+	notionVersion = "2022-06-28"
+
+	// Limits of the Notion API
+	notionMaxTextLength   = 2000
+	notionMaxRichTexts    = 100
+	notionMaxChildren     = 100
+	notionMaxBlocksPerReq = 1000
+)
+
+// Notion writes the plan as blocks into a Notion page through the API.
+//
+// With DatabaseID, a page titled Title is created in the database. Otherwise the blocks are appended to the page PageID.
+type Notion struct {
+	// BaseURL is the URL of the API (default: https://api.notion.com).
+	BaseURL string
+	// Token is the secret of an integration which the database or page is shared with.
+	Token string
+
+	DatabaseID string
+	// TitleProperty is the name of the title property of the database (default: Name).
+	TitleProperty string
+	// Title is the title of the created page (default: the summary line).
+	Title string
+
+	PageID string
+
+	HTTPClient *http.Client
+}
+
+type notionBlock map[string]any
+
+// Validate checks that the destination is known.
+func (n *Notion) Validate() error {
+	if n.Token == "" {
+		return errors.New("notion token is required")
+	}
+	if (n.DatabaseID == "") == (n.PageID == "") {
+		return errors.New("either a database ID or a page ID is required")
+	}
+	return nil
+}
+
+// Publish writes the plan and returns the URL of the page.
+func (n *Notion) Publish(ctx context.Context, plan *terraform.PlanData) (string, error) {
+	if err := n.Validate(); err != nil {
+		return "", err
+	}
+	heading, err := plan.Heading()
+	if err != nil {
+		return "", err
+	}
+	heading = strings.TrimLeft(heading, "# ")
+	blocks, err := notionBlocks(plan, heading)
+	if err != nil {
+		return "", err
+	}
+
+	pageID, pageURL := n.PageID, notionPageURL(n.PageID)
+	if n.DatabaseID != "" {
+		title := n.Title
+		if title == "" {
+			title = heading
+		}
+		titleProperty := n.TitleProperty
+		if titleProperty == "" {
+			titleProperty = "Name"
+		}
+		first := blocks[:notionBatchLength(blocks)]
+		page := map[string]any{
+			"parent":     map[string]string{"database_id": n.DatabaseID},
+			"properties": map[string]any{titleProperty: map[string]any{"title": notionRichTexts(title)}},
+			"children":   first,
+		}
+		var created struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		}
+		if err := doJSON(ctx, n.HTTPClient, http.MethodPost, n.apiURL("pages"), n.setHeaders, page, &created); err != nil {
+			return "", err
+		}
+		blocks = blocks[len(first):]
+		pageID, pageURL = created.ID, created.URL
+	}
+
+	// The number of blocks in a request is limited, so the rest are appended in batches.
+	for len(blocks) > 0 {
+		batch := blocks[:notionBatchLength(blocks)]
+		u := n.apiURL("blocks", pageID, "children")
+		if err := doJSON(ctx, n.HTTPClient, http.MethodPatch, u, n.setHeaders, map[string]any{"children": batch}, nil); err != nil {
+			return "", err
+		}
+		blocks = blocks[len(batch):]
+	}
+	return pageURL, nil
+}
+
+// notionBatchLength returns the number of leading blocks which can be sent in a request,
+// which may have 100 children and 1000 blocks including nested ones.
+func notionBatchLength(blocks []notionBlock) int {
+	total := 0
+	for i, b := range blocks {
+		size := 1 + len(notionChildren(b))
+		if i == notionMaxChildren || (i > 0 && total+size > notionMaxBlocksPerReq) {
+			return i
+		}
+		total += size
+	}
+	return len(blocks)
+}
+
+func notionChildren(b notionBlock) []notionBlock {
+	content, _ := b[b["type"].(string)].(map[string]any)
+	children, _ := content["children"].([]notionBlock)
+	return children
+}
+
+func (n *Notion) apiURL(segments ...string) string {
+	base := n.BaseURL
+	if base == "" {
+		base = notionAPIURL
+	}
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(base, "/") + "/v1/" + strings.Join(escaped, "/")
+}
+
+func (n *Notion) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+n.Token)
+	req.Header.Set("Notion-Version", notionVersion)
+}
+
+func notionPageURL(id string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(id, "-", "")
+}
+
+// notionBlocks converts the plan into blocks like the markdown report:
+// the summary line, a bulleted list of addresses for each action and a toggle of diffs.
+func notionBlocks(plan *terraform.PlanData, heading string) ([]notionBlock, error) {
+	blocks := []notionBlock{{
+		"type":      "heading_3",
+		"heading_3": map[string]any{"rich_text": notionRichTexts(heading)},
+	}}
+	for _, g := range []struct {
+		label     string
+		addresses []string
+	}{
+		{"add", plan.CreatedAddresses},
+		{"change", plan.UpdatedAddresses},
+		{"destroy", plan.DeletedAddresses},
+		{"replace", plan.ReplacedAddresses},
+	} {
+		if len(g.addresses) == 0 {
+			continue
+		}
+		children := make([]notionBlock, 0, len(g.addresses))
+		for _, address := range g.addresses {
+			children = append(children, notionBlock{
+				"type":               "bulleted_list_item",
+				"bulleted_list_item": map[string]any{"rich_text": notionCode(address)},
+			})
+		}
+		blocks = append(blocks, notionParents("bulleted_list_item", plan.Message(g.label), children)...)
+	}
+
+	if len(plan.ResourceChanges) == 0 {
+		return blocks, nil
+	}
+	var diffs []notionBlock
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, notionBlock{
+			"type": "code",
+			"code": map[string]any{"language": "diff", "rich_text": notionRichTexts(body)},
+		})
+	}
+	return append(blocks, notionParents("toggle", plan.Message("change_details"), diffs)...), nil
+}
+
+// notionParents returns blocks of blockType labeled label with children.
+// A block may have only 100 children, so there are as many blocks as needed, labeled with the range of their children.
+func notionParents(blockType, label string, children []notionBlock) []notionBlock {
+	var blocks []notionBlock
+	for i := 0; i < len(children); i += notionMaxChildren {
+		end := i + notionMaxChildren
+		if end > len(children) {
+			end = len(children)
+		}
+		text := label
+		if len(children) > notionMaxChildren {
+			text = fmt.Sprintf("%s (%d-%d of %d)", label, i+1, end, len(children))
+		}
+		blocks = append(blocks, notionBlock{
+			"type":    blockType,
+			blockType: map[string]any{"rich_text": notionRichTexts(text), "children": children[i:end]},
+		})
+	}
+	return blocks
+}
+
+// notionRichTexts splits s into text objects within the length limit.
+// Text beyond the limit of the number of objects is cut off.
+func notionRichTexts(s string) []map[string]any {
+	var texts []map[string]any
+	for _, chunk := range splitText(s, notionMaxTextLength) {
+		if len(texts) == notionMaxRichTexts {
+			texts[len(texts)-1]["text"].(map[string]any)["content"] = "... (truncated)"
+			break
+		}
+		texts = append(texts, map[string]any{"type": "text", "text": map[string]any{"content": chunk}})
+	}
+	return texts
+}
+
+func notionCode(s string) []map[string]any {
+	texts := notionRichTexts(s)
+	for _, t := range texts {
+		t["annotations"] = map[string]bool{"code": true}
+	}
+	return texts
+}
+
+// splitText splits s into chunks of at most size runes.
+func splitText(s string, size int) []string {
+	var chunks []string
+	runes := []rune(s)
+	for len(runes) > size {
+		chunks = append(chunks, string(runes[:size]))
+		runes = runes[size:]
+	}
+	return append(chunks, string(runes))
+}
@@ -0,0 +1,152 @@
+// Package publish posts rendered reports to external services.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyLength is the number of bytes of a response body included in errors.
+const maxErrorBodyLength = 512
+
+// StatusError is returned when a service responds with an unexpected status code.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// doJSON sends in as a JSON body (if not nil) and decodes the JSON response into out (if not nil).
+// setAuth is called to add credentials to the request.
+func doJSON(ctx context.Context, client *http.Client, method, url string, setAuth func(*http.Request), in, out any) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+		contentType = "application/json"
+	}
+	return doRequest(ctx, client, method, url, contentType, body, setAuth, out)
+}
+
+// doRequest sends body of contentType and decodes the JSON response into out (if not nil).
+func doRequest(ctx context.Context, client *http.Client, method, url, contentType string, body io.Reader, setAuth func(*http.Request), out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if setAuth != nil {
+		setAuth(req)
+	}
+	res, err := sendRequest(client, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return newStatusError(method, url, res)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: cannot decode response: %w", method, url, err)
+	}
+	return nil
+}
+
+// sendRequest sends req with client (http.DefaultClient if nil) and logs the response at the debug level.
+// Only the host of the URL is logged, since paths and queries of webhooks may include credentials.
+func sendRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		slog.Debug("request failed", "method", req.Method, "host", req.URL.Host, "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+	slog.Debug("sent request", "method", req.Method, "host", req.URL.Host, "status", res.StatusCode, "duration", time.Since(start))
+	return res, nil
+}
+
+func newStatusError(method, url string, res *http.Response) *StatusError {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyLength))
+	return &StatusError{Method: method, URL: url, StatusCode: res.StatusCode, Body: strings.TrimSpace(string(b))}
+}
+
+// stickyMarker is a hidden HTML comment in comments posted by this tool, to find and update them.
+const stickyMarker = "<!-- terraform-j2md -->"
+
+// withMarker appends marker to a markdown comment.
+func withMarker(body, marker string) string {
+	return strings.TrimRight(body, "\n") + "\n\n" + marker + "\n"
+}
+
+// TruncateMarkdown cuts a markdown report longer than max bytes at a line, closing an open code fence
+// and <details> element so that the rest is still rendered, and appends a notice of the truncation.
+func TruncateMarkdown(body string, max int) string {
+	if len(body) <= max {
+		return body
+	}
+	const notice = "\n_The report is truncated because it is too long._\n"
+	// Room for the closing fence, </details> and the notice
+	limit := max - len(notice) - 64
+	if limit < 0 {
+		limit = 0
+	}
+	cut := strings.LastIndex(body[:limit], "\n")
+	if cut < 0 {
+		cut = 0
+	}
+	head := body[:cut]
+
+	var fence string
+	openDetails := 0
+	for _, line := range strings.Split(head, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+		default:
+			openDetails += strings.Count(line, "<details") - strings.Count(line, "</details>")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(head)
+	b.WriteString("\n")
+	if fence != "" {
+		b.WriteString(fence + "\n")
+	}
+	for i := 0; i < openDetails; i++ {
+		b.WriteString("\n</details>\n")
+	}
+	b.WriteString(notice)
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// spaceliftMaxCommentLength is the length of comments posted to runs, kept below the size policies handle comfortably.
+const spaceliftMaxCommentLength = 65536
+
+// Spacelift posts the report as a comment of a Spacelift run through the GraphQL API.
+// Comments are shown on the page of the run and are the input of policies receiving run comments.
+type Spacelift struct {
+	// Endpoint is the URL of the account, e.g. https://example.app.spacelift.io
+	Endpoint string
+	// APIKeyID and APIKeySecret are an API key exchanged for a token.
+	APIKeyID     string
+	APIKeySecret string
+	// Token is a JWT used instead of an API key, e.g. SPACELIFT_API_TOKEN of runs of administrative stacks.
+	Token string
+	Stack string
+	Run   string
+
+	HTTPClient *http.Client
+}
+
+// Validate checks that the run and the credentials are known.
+func (s *Spacelift) Validate() error {
+	if s.Endpoint == "" || s.Stack == "" || s.Run == "" {
+		return errors.New("endpoint, stack and run are required")
+	}
+	if s.Token == "" && (s.APIKeyID == "" || s.APIKeySecret == "") {
+		return errors.New("API key or token is required")
+	}
+	return nil
+}
+
+// Comment posts body, a markdown report, as a comment of the run.
+func (s *Spacelift) Comment(ctx context.Context, body string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	token := s.Token
+	if token == "" {
+		var user struct {
+			APIKeyUser struct {
+				JWT string `json:"jwt"`
+			} `json:"apiKeyUser"`
+		}
+		const query = `mutation($id: ID!, $secret: String!) { apiKeyUser(id: $id, secret: $secret) { jwt } }`
+		if err := s.graphql(ctx, "", query, map[string]any{"id": s.APIKeyID, "secret": s.APIKeySecret}, &user); err != nil {
+			return fmt.Errorf("cannot exchange the API key: %w", err)
+		}
+		token = user.APIKeyUser.JWT
+	}
+	const query = `mutation($stack: ID!, $run: ID!, $body: String!) { runCommentCreate(stack: $stack, run: $run, body: $body) { body } }`
+	vars := map[string]any{"stack": s.Stack, "run": s.Run, "body": TruncateMarkdown(body, spaceliftMaxCommentLength)}
+	return s.graphql(ctx, token, query, vars, nil)
+}
+
+// graphql sends the query, returning the errors of the response as an error.
+func (s *Spacelift) graphql(ctx context.Context, token, query string, vars map[string]any, data any) error {
+	var res struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	res.Data = data
+	setAuth := func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	u := strings.TrimSuffix(s.Endpoint, "/") + "/graphql"
+	if err := doJSON(ctx, s.HTTPClient, http.MethodPost, u, setAuth, map[string]any{"query": query, "variables": vars}, &res); err != nil {
+		return err
+	}
+	if len(res.Errors) > 0 {
+		messages := make([]string, len(res.Errors))
+		for i, e := range res.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("POST %s: %s", u, strings.Join(messages, "; "))
+	}
+	return nil
+}
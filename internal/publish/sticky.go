@@ -0,0 +1,104 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Behaviors of sticky comments
+const (
+	// BehaviorNew always adds a comment.
+	BehaviorNew = "new"
+	// BehaviorUpdate updates the latest comment with the marker, or adds one if there is none.
+	BehaviorUpdate = "update"
+	// BehaviorRecreate deletes the comments with the marker and adds a comment, so that it comes last.
+	BehaviorRecreate = "recreate"
+	// BehaviorDeleteOrphaned updates like BehaviorUpdate and deletes the other comments with the marker,
+	// which are left by BehaviorNew or concurrent runs.
+	BehaviorDeleteOrphaned = "delete-orphaned"
+)
+
+// Behaviors are the behaviors of sticky comments.
+var Behaviors = []string{BehaviorNew, BehaviorUpdate, BehaviorRecreate, BehaviorDeleteOrphaned}
+
+// Sticky is how a comment is posted: the label of its marker and the behavior to comments posted before.
+type Sticky struct {
+	// Label distinguishes comments of several plans, e.g. workspaces, posted to the same pull request.
+	Label    string
+	Behavior string
+}
+
+// Validate checks the label and the behavior.
+func (s Sticky) Validate() error {
+	if strings.Contains(s.Label, "--") || strings.Contains(s.Label, ">") {
+		return fmt.Errorf("label must not contain -- or >: %q", s.Label)
+	}
+	if s.Behavior != "" && !containsString(Behaviors, s.Behavior) {
+		return fmt.Errorf("unknown behavior %q (must be one of %v)", s.Behavior, Behaviors)
+	}
+	return nil
+}
+
+// Marker returns the hidden HTML comment identifying comments with the label.
+func (s Sticky) Marker() string {
+	if s.Label == "" {
+		return stickyMarker
+	}
+	return "<!-- terraform-j2md:" + s.Label + " -->"
+}
+
+type postedComment struct {
+	ID   string
+	Body string
+}
+
+// commentThread is the comments of a pull request or a merge request.
+type commentThread interface {
+	// list returns the comments, oldest first.
+	list(ctx context.Context) ([]postedComment, error)
+	create(ctx context.Context, body string) (string, error)
+	update(ctx context.Context, id, body string) (string, error)
+	delete(ctx context.Context, id string) error
+}
+
+// post posts body with the marker according to the behavior, and returns the URL of the comment.
+func (s Sticky) post(ctx context.Context, thread commentThread, body string) (string, error) {
+	marker := s.Marker()
+	body = withMarker(body, marker)
+	if s.Behavior == BehaviorNew {
+		return thread.create(ctx, body)
+	}
+
+	comments, err := thread.list(ctx)
+	if err != nil {
+		return "", err
+	}
+	var marked []postedComment
+	for _, c := range comments {
+		if strings.Contains(c.Body, marker) {
+			marked = append(marked, c)
+		}
+	}
+
+	if s.Behavior == BehaviorRecreate {
+		for _, c := range marked {
+			if err := thread.delete(ctx, c.ID); err != nil {
+				return "", err
+			}
+		}
+		return thread.create(ctx, body)
+	}
+	if len(marked) == 0 {
+		return thread.create(ctx, body)
+	}
+	latest := marked[len(marked)-1]
+	if s.Behavior == BehaviorDeleteOrphaned {
+		for _, c := range marked[:len(marked)-1] {
+			if err := thread.delete(ctx, c.ID); err != nil {
+				return "", err
+			}
+		}
+	}
+	return thread.update(ctx, latest.ID, body)
+}
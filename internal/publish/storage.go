@@ -0,0 +1,309 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ObjectStore uploads objects to a bucket of object storage.
+type ObjectStore interface {
+	// Upload writes body to key and returns the URL of the object.
+	Upload(ctx context.Context, key string, body []byte, contentType string) (string, error)
+}
+
+// ObjectKeyData is the data of templates of object keys.
+type ObjectKeyData struct {
+	Branch string
+	Commit string
+	// ShortCommit is the first 7 characters of Commit.
+	ShortCommit string
+	// Date is the date of the upload as 2006-01-02 in UTC.
+	Date string
+	// Timestamp is the time of the upload as 20060102T150405Z.
+	Timestamp string
+}
+
+// NewObjectKeyData returns the data of templates of object keys at t.
+func NewObjectKeyData(branch, commit string, t time.Time) ObjectKeyData {
+	short := commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	t = t.UTC()
+	return ObjectKeyData{
+		Branch:      branch,
+		Commit:      commit,
+		ShortCommit: short,
+		Date:        t.Format("2006-01-02"),
+		Timestamp:   t.Format("20060102T150405Z"),
+	}
+}
+
+// ExpandObjectKey executes the template of an object key like "plans/{{.Branch}}/{{.ShortCommit}}.html".
+func ExpandObjectKey(keyTemplate string, data ObjectKeyData) (string, error) {
+	t, err := template.New("key").Option("missingkey=error").Parse(keyTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid key template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to expand key template: %w", err)
+	}
+	key := strings.TrimPrefix(b.String(), "/")
+	if key == "" {
+		return "", errors.New("object key is empty")
+	}
+	return key, nil
+}
+
+// S3 uploads objects to an Amazon S3 bucket with Signature Version 4.
+type S3 struct {
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint is the URL of an S3 compatible service (optional), which is accessed with path-style URLs.
+	Endpoint string
+
+	HTTPClient *http.Client
+}
+
+// Upload writes body to key and returns the URL of the object.
+func (s *S3) Upload(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	if s.Bucket == "" || s.Region == "" {
+		return "", errors.New("bucket and region are required")
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return "", errors.New("AWS credentials are required")
+	}
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	sum := sha256.Sum256(body)
+	s.SignRequest(req, hex.EncodeToString(sum[:]), time.Now())
+	if err := doSigned(s.HTTPClient, req); err != nil {
+		return "", err
+	}
+	return u, nil
+}
+
+// Download returns the object at key.
+func (s *S3) Download(ctx context.Context, key string) ([]byte, error) {
+	if s.Bucket == "" || s.Region == "" {
+		return nil, errors.New("bucket and region are required")
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return nil, errors.New("AWS credentials are required")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(nil)
+	s.SignRequest(req, hex.EncodeToString(sum[:]), time.Now())
+	res, err := sendRequest(s.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, newStatusError(req.Method, req.URL.String(), res)
+	}
+	return io.ReadAll(res.Body)
+}
+
+func (s *S3) objectURL(key string) string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + escapePath(s.Bucket) + "/" + escapePath(key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, escapePath(key))
+}
+
+// SignRequest adds the headers of Signature Version 4 at t to req, signing all of its headers.
+func (s *S3) SignRequest(req *http.Request, payloadHash string, t time.Time) {
+	const algorithm = "AWS4-HMAC-SHA256"
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(vs, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	for _, part := range []string{s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes s as required by Signature Version 4: everything but unreserved characters is percent-encoded.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// GCS uploads objects to a Google Cloud Storage bucket with an OAuth 2.0 access token,
+// e.g. the output of `gcloud auth print-access-token`.
+type GCS struct {
+	Bucket      string
+	AccessToken string
+
+	HTTPClient *http.Client
+}
+
+// Upload writes body to key and returns the URL of the object.
+func (g *GCS) Upload(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	if g.Bucket == "" {
+		return "", errors.New("bucket is required")
+	}
+	if g.AccessToken == "" {
+		return "", errors.New("access token is required")
+	}
+	u := "https://storage.googleapis.com/" + escapePath(g.Bucket) + "/" + escapePath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+g.AccessToken)
+	if err := doSigned(g.HTTPClient, req); err != nil {
+		return "", err
+	}
+	return u, nil
+}
+
+// AzureBlob uploads block blobs to a container of Azure Blob Storage with a SAS token.
+type AzureBlob struct {
+	Account   string
+	Container string
+	SASToken  string
+	// Endpoint is the URL of the blob service (default: https://<account>.blob.core.windows.net).
+	Endpoint string
+
+	HTTPClient *http.Client
+}
+
+// Upload writes body to key and returns the URL of the blob, without the SAS token.
+func (a *AzureBlob) Upload(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	if a.Account == "" || a.Container == "" {
+		return "", errors.New("storage account and container are required")
+	}
+	if a.SASToken == "" {
+		return "", errors.New("SAS token is required")
+	}
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + a.Account + ".blob.core.windows.net"
+	}
+	u := strings.TrimSuffix(endpoint, "/") + "/" + escapePath(a.Container) + "/" + escapePath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u+"?"+strings.TrimPrefix(a.SASToken, "?"), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Version", "2021-08-06")
+	if err := doSigned(a.HTTPClient, req); err != nil {
+		return "", err
+	}
+	return u, nil
+}
+
+// doSigned sends req, whose URL may include credentials hidden from errors.
+func doSigned(client *http.Client, req *http.Request) error {
+	res, err := sendRequest(client, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return newStatusError(req.Method, (&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}).String(), res)
+	}
+	return nil
+}
+
+// escapePath escapes each segment of a slash separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,34 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Teams posts messages to a Microsoft Teams incoming webhook or a Workflows (Power Automate) webhook.
+type Teams struct {
+	WebhookURL string
+
+	HTTPClient *http.Client
+}
+
+// Validate checks that the webhook is known.
+func (t *Teams) Validate() error {
+	if t.WebhookURL == "" {
+		return errors.New("teams webhook URL is required")
+	}
+	return nil
+}
+
+// Post sends message, a JSON payload like the output of the teams format.
+func (t *Teams) Post(ctx context.Context, message []byte) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if !json.Valid(message) {
+		return errors.New("message is not a JSON document")
+	}
+	return doJSON(ctx, t.HTTPClient, http.MethodPost, t.WebhookURL, nil, json.RawMessage(message), nil)
+}
@@ -0,0 +1,94 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultSignatureHeader is the header of HMAC signatures, the same as GitHub webhooks.
+const DefaultSignatureHeader = "X-Hub-Signature-256"
+
+// Webhook posts the report to any HTTP endpoint.
+type Webhook struct {
+	URL         string
+	ContentType string
+	Header      http.Header
+	// Retries is the number of retries after network errors, 429 and 5xx responses.
+	Retries int
+	// RetryWait is the wait before the first retry, doubled for each retry.
+	RetryWait time.Duration
+	// Secret signs the body with HMAC-SHA256 if not empty.
+	// The signature is sent as "sha256=<hex>" in SignatureHeader (DefaultSignatureHeader if empty).
+	Secret          string
+	SignatureHeader string
+
+	HTTPClient *http.Client
+}
+
+// Validate checks that the endpoint is known.
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return errors.New("webhook URL is required")
+	}
+	if w.Retries < 0 {
+		return errors.New("number of retries must not be negative")
+	}
+	return nil
+}
+
+// Post sends body, retrying on transient errors.
+func (w *Webhook) Post(ctx context.Context, body []byte) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	setHeaders := func(req *http.Request) {
+		for k, vs := range w.Header {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+		if w.Secret != "" {
+			header := w.SignatureHeader
+			if header == "" {
+				header = DefaultSignatureHeader
+			}
+			req.Header.Set(header, Signature(w.Secret, body))
+		}
+	}
+
+	wait := w.RetryWait
+	for attempt := 0; ; attempt++ {
+		err := doRequest(ctx, w.HTTPClient, http.MethodPost, w.URL, w.ContentType, bytes.NewReader(body), setHeaders, nil)
+		if err == nil || attempt >= w.Retries || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// Signature returns the HMAC-SHA256 signature of body as "sha256=<hex>".
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable reports whether a request failed with err may succeed later.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	rendererv1 "github.com/reproio/terraform-j2md/api/renderer/v1"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// renderChunkSize is the size of chunks of documents streamed by Render.
+const renderChunkSize = 64 << 10
+
+// GRPCServer implements the RendererService of api/renderer/v1.
+type GRPCServer struct {
+	rendererv1.UnimplementedRendererServiceServer
+	// Defaults are the options of rendering overridden by the options of requests.
+	Defaults terraform.Options
+	// RenderTimeout is the limit of the time to parse and render a plan (default: DefaultRenderTimeout).
+	RenderTimeout time.Duration
+	// Metrics count the renders if not nil, e.g. the metrics of the HTTP server.
+	Metrics *Metrics
+}
+
+// NewGRPCServer returns a service rendering plans with defaults.
+func NewGRPCServer(defaults terraform.Options) *GRPCServer {
+	return &GRPCServer{Defaults: defaults, RenderTimeout: DefaultRenderTimeout}
+}
+
+// Render renders the plan and streams the document in chunks.
+func (s *GRPCServer) Render(req *rendererv1.RenderRequest, stream rendererv1.RendererService_RenderServer) (err error) {
+	start := time.Now()
+	format := "unknown"
+	defer func() { s.observe(format, start, req.GetPlanJson(), err) }()
+	params := make(map[string][]string, len(req.GetOptions()))
+	for name, v := range req.GetOptions() {
+		params[name] = []string{v}
+	}
+	opts, err := terraform.ParseRequestOptions(s.Defaults, params)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid options: %v", err)
+	}
+	format = opts.Format
+	data, err := renderPlan(stream.Context(), s.RenderTimeout, req.GetPlanJson(), opts)
+	if err != nil {
+		return statusError(err)
+	}
+	b := bytes.NewBuffer(data)
+
+	contentType := terraform.ContentType(opts.Format)
+	for first := true; first || b.Len() > 0; first = false {
+		res := &rendererv1.RenderResponse{Data: b.Next(renderChunkSize)}
+		if first {
+			res.ContentType = contentType
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summarize returns the counts and the addresses of changes of the plan.
+// It is bounded by RenderTimeout and counted in Metrics as the format "summary".
+func (s *GRPCServer) Summarize(ctx context.Context, req *rendererv1.SummarizeRequest) (_ *rendererv1.SummarizeResponse, err error) {
+	start := time.Now()
+	defer func() { s.observe("summary", start, req.GetPlanJson(), err) }()
+	planData, err := parsePlan(ctx, s.RenderTimeout, req.GetPlanJson(), s.Defaults)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	summary := planData.Summary()
+	return &rendererv1.SummarizeResponse{
+		Add:                   int32(summary.Add),
+		Change:                int32(summary.Change),
+		Destroy:               int32(summary.Destroy),
+		Replace:               int32(summary.Replace),
+		Moved:                 int32(summary.Moved),
+		HasChanges:            summary.HasChanges(),
+		HasDestructiveChanges: summary.HasDestructiveChanges(),
+		Short:                 summary.Short(),
+		CreatedAddresses:      planData.CreatedAddresses,
+		UpdatedAddresses:      planData.UpdatedAddresses,
+		DeletedAddresses:      planData.DeletedAddresses,
+		ReplacedAddresses:     planData.ReplacedAddresses,
+		MovedAddresses:        planData.MovedAddresses,
+	}, nil
+}
+
+// observe records a call which ended with err in Metrics if not nil.
+func (s *GRPCServer) observe(format string, start time.Time, plan []byte, err error) {
+	if s.Metrics == nil {
+		return
+	}
+	result := ResultOK
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.InvalidArgument:
+		result = ResultInvalid
+	default:
+		result = ResultError
+	}
+	s.Metrics.Observe(format, result, time.Since(start), int64(len(plan)))
+}
+
+// statusError returns the status of an error of renderPlan or parsePlan.
+func statusError(err error) error {
+	var invalid *invalidPlanError
+	switch {
+	case errors.As(err, &invalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
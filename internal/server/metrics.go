@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Results of renders, the label "result" of the metrics
+const (
+	ResultOK      = "ok"
+	ResultInvalid = "invalid"
+	ResultError   = "error"
+)
+
+var (
+	durationBuckets  = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	inputSizeBuckets = []float64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20, 100 << 20}
+)
+
+// Metrics are the counters of renders, exposed in the Prometheus text format.
+type Metrics struct {
+	mu        sync.Mutex
+	renders   map[renderKey]uint64
+	durations map[string]*histogram
+	sizes     map[string]*histogram
+}
+
+type renderKey struct {
+	format string
+	result string
+}
+
+// NewMetrics returns empty metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		renders:   map[renderKey]uint64{},
+		durations: map[string]*histogram{},
+		sizes:     map[string]*histogram{},
+	}
+}
+
+// Observe records a render of a plan of size bytes in format, which took duration and ended with result.
+func (m *Metrics) Observe(format, result string, duration time.Duration, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renders[renderKey{format, result}]++
+	if m.durations[format] == nil {
+		m.durations[format] = newHistogram(durationBuckets)
+		m.sizes[format] = newHistogram(inputSizeBuckets)
+	}
+	m.durations[format].observe(duration.Seconds())
+	m.sizes[format].observe(float64(size))
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("# HELP terraform_j2md_renders_total Number of renders of plans by format and result.\n")
+	b.WriteString("# TYPE terraform_j2md_renders_total counter\n")
+	keys := make([]renderKey, 0, len(m.renders))
+	for k := range m.renders {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].format != keys[j].format {
+			return keys[i].format < keys[j].format
+		}
+		return keys[i].result < keys[j].result
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "terraform_j2md_renders_total{format=%q,result=%q} %d\n", k.format, k.result, m.renders[k])
+	}
+	writeHistograms(&b, "terraform_j2md_render_duration_seconds", "Time to parse and render plans.", m.durations)
+	writeHistograms(&b, "terraform_j2md_render_input_bytes", "Size of posted plans.", m.sizes)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// histogram counts observations in cumulative buckets of upper bounds.
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func writeHistograms(b *strings.Builder, name, help string, histograms map[string]*histogram) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+	formats := make([]string, 0, len(histograms))
+	for format := range histograms {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	for _, format := range formats {
+		h := histograms[format]
+		for i, bound := range h.bounds {
+			fmt.Fprintf(b, "%s_bucket{format=%q,le=\"%g\"} %d\n", name, format, bound, h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{format=%q,le=\"+Inf\"} %d\n", name, format, h.count)
+		fmt.Fprintf(b, "%s_sum{format=%q} %g\n", name, format, h.sum)
+		fmt.Fprintf(b, "%s_count{format=%q} %d\n", name, format, h.count)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// metricsFormat is the label of the format of opts, which is not trusted unless the options are valid.
+func metricsFormat(format string, err error) string {
+	if err != nil {
+		return "unknown"
+	}
+	return format
+}
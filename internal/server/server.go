@@ -0,0 +1,181 @@
+// Package server serves rendering of plans over HTTP.
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// DefaultMaxBodySize is the default maximum size of plans accepted by the server.
+const DefaultMaxBodySize = 64 << 20
+
+// DefaultRenderTimeout is the default limit of the time to parse and render a plan.
+const DefaultRenderTimeout = 30 * time.Second
+
+// Server renders plans posted to /render.
+type Server struct {
+	// Defaults are the options of rendering overridden by the query parameters.
+	Defaults terraform.Options
+	// MaxBodySize is the maximum size of posted plans (default: DefaultMaxBodySize).
+	MaxBodySize int64
+	// RenderTimeout is the limit of the time to parse and render a plan (default: DefaultRenderTimeout).
+	RenderTimeout time.Duration
+	// Metrics count the renders, served on /metrics if not nil.
+	Metrics *Metrics
+}
+
+// New returns a server rendering plans with defaults.
+func New(defaults terraform.Options) *Server {
+	return &Server{Defaults: defaults, MaxBodySize: DefaultMaxBodySize, RenderTimeout: DefaultRenderTimeout, Metrics: NewMetrics()}
+}
+
+// Handler returns the handler of the endpoints:
+//
+//	POST /render   renders the plan JSON in the body; the query parameters are the flags of the command
+//	               allowed by terraform.ParseRequestOptions, e.g. ?format=html&sort=alpha
+//	GET  /healthz  responds 200 OK
+//	GET  /metrics  responds the metrics of renders in the Prometheus text format
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/render", s.render)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	if s.Metrics != nil {
+		mux.Handle("/metrics", s.Metrics)
+	}
+	return mux
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	start := time.Now()
+	opts, err := s.options(r)
+	format := metricsFormat(opts.Format, err)
+	maxBodySize := s.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	body := &countingReader{r: http.MaxBytesReader(w, r.Body, maxBodySize)}
+	result := ResultOK
+	defer func() {
+		duration := time.Since(start)
+		slog.Debug("rendered plan", "format", format, "result", result, "size", body.n, "duration", duration)
+		if s.Metrics != nil {
+			s.Metrics.Observe(format, result, duration, body.n)
+		}
+	}()
+	if err != nil {
+		result = ResultInvalid
+		http.Error(w, fmt.Sprintf("invalid options: %v", err), http.StatusBadRequest)
+		return
+	}
+	plan, err := io.ReadAll(body)
+	if err != nil {
+		result = ResultInvalid
+		http.Error(w, fmt.Sprintf("cannot read the plan: %v", err), http.StatusBadRequest)
+		return
+	}
+	b, err := renderPlan(r.Context(), s.RenderTimeout, plan, opts)
+	var invalid *invalidPlanError
+	switch {
+	case err == nil:
+	case errors.As(err, &invalid):
+		result = ResultInvalid
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		result = ResultError
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	default:
+		result = ResultError
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", terraform.ContentType(opts.Format))
+	_, _ = w.Write(b)
+}
+
+// options returns the defaults overridden by the query parameters.
+func (s *Server) options(r *http.Request) (terraform.Options, error) {
+	return terraform.ParseRequestOptions(s.Defaults, r.URL.Query())
+}
+
+// invalidPlanError is the error of renderPlan for plans which cannot be parsed.
+type invalidPlanError struct {
+	err error
+}
+
+func (e *invalidPlanError) Error() string {
+	return fmt.Sprintf("cannot parse input as Terraform plan JSON: %v", e.err)
+}
+
+func (e *invalidPlanError) Unwrap() error {
+	return e.err
+}
+
+// renderPlan parses and renders the plan, giving up when ctx is done or after timeout (DefaultRenderTimeout if 0).
+// The render is left running in the background after giving up, as rendering is not cancellable,
+// so the options of requests must be bounded by terraform.ParseRequestOptions.
+func renderPlan(ctx context.Context, timeout time.Duration, plan []byte, opts terraform.Options) ([]byte, error) {
+	return withRenderTimeout(ctx, timeout, func() ([]byte, error) {
+		planData, err := terraform.NewPlanData(bytes.NewReader(plan), opts)
+		if err != nil {
+			return nil, &invalidPlanError{err}
+		}
+		var b bytes.Buffer
+		if err := planData.Render(&b); err != nil {
+			return nil, fmt.Errorf("cannot render: %w", err)
+		}
+		return b.Bytes(), nil
+	})
+}
+
+// parsePlan parses the plan without rendering it, giving up like renderPlan.
+func parsePlan(ctx context.Context, timeout time.Duration, plan []byte, opts terraform.Options) (*terraform.PlanData, error) {
+	return withRenderTimeout(ctx, timeout, func() (*terraform.PlanData, error) {
+		planData, err := terraform.NewPlanData(bytes.NewReader(plan), opts)
+		if err != nil {
+			return nil, &invalidPlanError{err}
+		}
+		return planData, nil
+	})
+}
+
+// withRenderTimeout returns the result of f, or gives up when ctx is done or after timeout (DefaultRenderTimeout if 0).
+func withRenderTimeout[T any](ctx context.Context, timeout time.Duration, f func() (T, error)) (T, error) {
+	if timeout == 0 {
+		timeout = DefaultRenderTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := f()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("cannot render in %v: %w", timeout, ctx.Err())
+	}
+}
@@ -0,0 +1,57 @@
+package terraform
+
+import (
+	"fmt"
+	"path"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// AttributeAllowlist limits the diffs of resources of types matching Pattern, a glob like "aws_instance"
+// or "aws_*", to their top-level Attributes.
+type AttributeAllowlist struct {
+	Pattern    string
+	Attributes []string
+}
+
+func (a AttributeAllowlist) validate() error {
+	if _, err := path.Match(a.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern of attribute allowlist %q: %w", a.Pattern, err)
+	}
+	if len(a.Attributes) == 0 {
+		return fmt.Errorf("attribute allowlist of %q has no attributes", a.Pattern)
+	}
+	return nil
+}
+
+// allowlistAttributes removes the attributes not in the first allowlist matching the type of each change
+// from the values before and after it.
+func allowlistAttributes(plan *tfjson.Plan, allowlists []AttributeAllowlist) {
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		for _, a := range allowlists {
+			if ok, _ := path.Match(a.Pattern, rc.Type); !ok {
+				continue
+			}
+			rc.Change.Before = filterAttributes(rc.Change.Before, a.Attributes)
+			rc.Change.After = filterAttributes(rc.Change.After, a.Attributes)
+			break
+		}
+	}
+}
+
+func filterAttributes(v any, attributes []string) any {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	filtered := make(map[string]interface{}, len(attributes))
+	for _, k := range attributes {
+		if value, ok := m[k]; ok {
+			filtered[k] = value
+		}
+	}
+	return filtered
+}
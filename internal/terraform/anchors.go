@@ -0,0 +1,31 @@
+package terraform
+
+import (
+	"regexp"
+)
+
+var anchorUnsafeRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// anchorID returns the ID of the anchor of the details of a resource, e.g. "change-aws_instance.web-0-" for
+// aws_instance.web[0]. Characters other than the ones of identifiers are replaced with "-".
+func anchorID(address string) string {
+	return "change-" + anchorUnsafeRegexp.ReplaceAllString(address, "-")
+}
+
+// anchor returns the ID of the anchor of the details of the resource if Anchors of the options is set
+// and its details are rendered, or "" otherwise.
+func (plan *PlanData) anchor(address string) string {
+	if !plan.options.Anchors || plan.detailsOmitted() {
+		return ""
+	}
+	for _, r := range plan.ResourceChanges {
+		if r.Address() != address {
+			continue
+		}
+		if plan.options.TagOnlyUpdates == TagOnlyUpdatesSummary && r.isTagOnly() {
+			return ""
+		}
+		return anchorID(address)
+	}
+	return ""
+}
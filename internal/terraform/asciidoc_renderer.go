@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const asciidocTemplateBody = `{{.Heading}}
+{{range .Groups}}
+* {{.Label}}
+{{- range .Addresses}}
+** {{literal .}}
+{{- end}}
+{{- end}}
+{{if .ResourceChanges}}
+[%collapsible]
+.{{.ChangeDetails}}
+====
+{{- range .ResourceChanges}}
+
+.{{literal .Header}}
+[source,diff]
+{{listing .Body}}
+{{- end}}
+====
+{{end}}`
+
+type asciidocResource struct {
+	Header string
+	Body   string
+}
+
+// renderAsciiDoc writes the plan as an AsciiDoc document with a collapsible block of diff listings.
+func (plan *PlanData) renderAsciiDoc(w io.Writer) error {
+	funcMap := template.FuncMap{
+		"literal": asciidocLiteral,
+		"listing": asciidocListing,
+	}
+	t, err := template.New("asciidoc").Funcs(funcMap).Parse(asciidocTemplateBody)
+	if err != nil {
+		return fmt.Errorf("invalid template text: %w", err)
+	}
+	heading, err := plan.headingText()
+	if err != nil {
+		return err
+	}
+	if plan.options.HeadingLevel > 0 {
+		// AsciiDoc section levels start from "==" for level 1, where markdown "##" is level 2
+		heading = strings.Repeat("=", plan.options.HeadingLevel) + " " + heading
+	}
+
+	data := struct {
+		Heading         string
+		Groups          []addressGroup
+		ChangeDetails   string
+		ResourceChanges []asciidocResource
+	}{Heading: heading, Groups: plan.addressGroups(), ChangeDetails: plan.options.message("change_details")}
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return err
+		}
+		data.ResourceChanges = append(data.ResourceChanges, asciidocResource{Header: r.Header(), Body: body})
+	}
+
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// asciidocLiteral renders s in monospace without interpreting any AsciiDoc markup in it.
+func asciidocLiteral(s string) string {
+	return "`+" + s + "+`"
+}
+
+// asciidocListing wraps s in a listing block whose delimiter is longer than any line of dashes in s.
+func asciidocListing(s string) string {
+	delimiter := "----"
+	for _, line := range strings.Split(s, "\n") {
+		if len(line) >= len(delimiter) && strings.Trim(line, "-") == "" {
+			delimiter = strings.Repeat("-", len(line)+1)
+		}
+	}
+	return delimiter + "\n" + strings.TrimSuffix(s, "\n") + "\n" + delimiter
+}
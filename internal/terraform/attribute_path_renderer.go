@@ -0,0 +1,137 @@
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// AttributePathRenderer renders the changed values of a resource as flat attribute paths, one per line:
+// "+ path: value" for added values, "- path: value" for removed ones and "~ path: old → new" for changed ones.
+type AttributePathRenderer struct {
+	ResourceChange   *tfjson.ResourceChange
+	EnableEscapeHTML bool
+	// HeaderSuffix follows the address in the header, e.g. "will be created".
+	HeaderSuffix string
+}
+
+func NewAttributePathRenderer(resourceChange *tfjson.ResourceChange, opts Options) *AttributePathRenderer {
+	return &AttributePathRenderer{
+		ResourceChange:   resourceChange,
+		EnableEscapeHTML: opts.EscapeHTML,
+		HeaderSuffix:     opts.message(headerSuffixMessage(resourceChange, opts)),
+	}
+}
+
+func (r *AttributePathRenderer) Render() (string, error) {
+	var b strings.Builder
+	err := r.walk(&b, "", r.ResourceChange.Change.Before, r.ResourceChange.Change.After)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource changes: %w", err)
+	}
+	return b.String(), nil
+}
+
+func (r *AttributePathRenderer) Header() string {
+	return fmt.Sprintf("%s %s", r.ResourceChange.Address, r.HeaderSuffix)
+}
+
+// walk writes the changed values under path, descending into objects and lists which are on either side.
+func (r *AttributePathRenderer) walk(b *strings.Builder, path string, before, after any) error {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && len(beforeMap)+len(afterMap) > 0 {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for k := range beforeMap {
+			keys = append(keys, k)
+		}
+		for k := range afterMap {
+			if _, ok := beforeMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := r.walk(b, path+attributePathKey(path, k), beforeMap[k], afterMap[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if (beforeIsList || before == nil) && (afterIsList || after == nil) && len(beforeList)+len(afterList) > 0 {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var bv, av any
+			if i < len(beforeList) {
+				bv = beforeList[i]
+			}
+			if i < len(afterList) {
+				av = afterList[i]
+			}
+			if err := r.walk(b, fmt.Sprintf("%s[%d]", path, i), bv, av); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	if path == "" {
+		path = "."
+	}
+	switch {
+	case before == nil:
+		v, err := r.value(after)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "+ %s: %s\n", path, v)
+	case after == nil:
+		v, err := r.value(before)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "- %s: %s\n", path, v)
+	default:
+		bv, err := r.value(before)
+		if err != nil {
+			return err
+		}
+		av, err := r.value(after)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "~ %s: %s → %s\n", path, bv, av)
+	}
+	return nil
+}
+
+// value returns a scalar or an empty object or list as JSON, with line breaks of strings escaped to keep it in a line.
+func (r *AttributePathRenderer) value(v any) (string, error) {
+	enc := valueEncoder{escapeHTML: r.EnableEscapeHTML}
+	b, err := enc.marshalScalar(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// attributePathKey returns the step of a key in an attribute path: `.key` for identifiers, `["key"]` otherwise.
+func attributePathKey(path, key string) string {
+	if !identifierRegexp.MatchString(key) {
+		return fmt.Sprintf("[%q]", key)
+	}
+	if path == "" {
+		return key
+	}
+	return "." + key
+}
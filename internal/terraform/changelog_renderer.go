@@ -0,0 +1,59 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderChangelog writes an entry of a changelog in the style of https://keepachangelog.com, for appending to
+// a CHANGELOG.md of infrastructure: created resources are Added, updated, replaced and moved ones Changed,
+// and destroyed ones Removed. The entry is Unreleased until the plan is applied.
+func (plan *PlanData) renderChangelog(w io.Writer) error {
+	var changed []string
+	for _, a := range plan.UpdatedAddresses {
+		changed = append(changed, codeSpan(a))
+	}
+	for _, a := range plan.ReplacedAddresses {
+		changed = append(changed, codeSpan(a)+" (replaced)")
+	}
+	for _, r := range plan.ResourceChanges {
+		if isMovedBlock(r.ResourceChange) {
+			changed = append(changed, fmt.Sprintf("%s (moved from %s)", codeSpan(r.ResourceChange.Address), codeSpan(r.ResourceChange.PreviousAddress)))
+		}
+	}
+	var added, removed []string
+	for _, a := range plan.CreatedAddresses {
+		added = append(added, codeSpan(a))
+	}
+	for _, a := range plan.DeletedAddresses {
+		removed = append(removed, codeSpan(a))
+	}
+
+	var b strings.Builder
+	b.WriteString("## [Unreleased]\n")
+	if len(added)+len(changed)+len(removed) == 0 {
+		b.WriteString("\nNo infrastructure changes.\n")
+	}
+	for _, section := range []struct {
+		title   string
+		entries []string
+	}{
+		{"Added", added},
+		{"Changed", changed},
+		{"Removed", removed},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n### %s\n\n", section.title))
+		for _, e := range section.entries {
+			b.WriteString("- " + e + "\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderCommitMsg writes a paragraph summarizing the plan in words, e.g. "Replaces 2 aws_instance in module.web;
+// updates 3 aws_security_group.", for descriptions of pull requests and bodies of squashed commits.
+// Resources are counted by type and module for each action.
+func (plan *PlanData) renderCommitMsg(w io.Writer) error {
+	var moved []string
+	for _, r := range plan.ResourceChanges {
+		if isMovedBlock(r.ResourceChange) {
+			moved = append(moved, r.ResourceChange.Address)
+		}
+	}
+	var clauses []string
+	for _, action := range []struct {
+		verb      string
+		addresses []string
+	}{
+		{"creates", plan.CreatedAddresses},
+		{"updates", plan.UpdatedAddresses},
+		{"replaces", plan.ReplacedAddresses},
+		{"destroys", plan.DeletedAddresses},
+		{"moves", moved},
+	} {
+		if groups := plan.typeCounts(action.addresses); len(groups) > 0 {
+			clauses = append(clauses, action.verb+" "+strings.Join(groups, ", "))
+		}
+	}
+
+	text := "No changes."
+	if len(clauses) > 0 {
+		text = capitalize(strings.Join(clauses, "; ")) + "."
+	}
+	if _, err := io.WriteString(w, text+"\n"); err != nil {
+		return fmt.Errorf("failed to write commit message: %w", err)
+	}
+	return nil
+}
+
+// typeCounts returns the number of resources of each type and module among addresses, e.g. "2 aws_instance in module.web",
+// in the order of their first resources.
+func (plan *PlanData) typeCounts(addresses []string) []string {
+	changes := map[string]ResourceChangeData{}
+	for _, r := range plan.ResourceChanges {
+		changes[r.ResourceChange.Address] = r
+	}
+	var keys []string
+	counts := map[string]int{}
+	for _, a := range addresses {
+		r, ok := changes[a]
+		if !ok {
+			continue
+		}
+		key := r.ResourceChange.Type
+		if module := r.ResourceChange.ModuleAddress; module != "" {
+			key += " in " + module
+		}
+		if counts[key] == 0 {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+	groups := make([]string, len(keys))
+	for i, key := range keys {
+		groups[i] = fmt.Sprintf("%d %s", counts[key], key)
+	}
+	return groups
+}
@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Condition is a result of the preconditions, postconditions or check blocks of an object which did not pass.
+type Condition struct {
+	// Address is the address of the object, e.g. "aws_instance.web[0]" or "output.url".
+	Address string
+	// Kind is the kind of the object: resource, output_value or check.
+	Kind string
+	// Status is fail, error or unknown. Unknown conditions are checked during apply.
+	Status string
+	// Messages are the error messages of the failing conditions.
+	Messages []string
+}
+
+// conditionResults returns the results of conditions which did not pass, for each instance of the objects.
+func conditionResults(checks []tfjson.CheckResultStatic) []Condition {
+	var conditions []Condition
+	for _, check := range checks {
+		if len(check.Instances) == 0 {
+			if check.Status != tfjson.CheckStatusPass {
+				conditions = append(conditions, Condition{Address: check.Address.ToDisplay, Kind: string(check.Address.Kind), Status: string(check.Status)})
+			}
+			continue
+		}
+		for _, instance := range check.Instances {
+			if instance.Status == tfjson.CheckStatusPass {
+				continue
+			}
+			c := Condition{Address: instance.Address.ToDisplay, Kind: string(check.Address.Kind), Status: string(instance.Status)}
+			for _, p := range instance.Problems {
+				c.Messages = append(c.Messages, strings.Join(strings.Fields(p.Message), " "))
+			}
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
+// conditionMessage returns the message ID describing the status of a condition in headers.
+func conditionMessage(status string) string {
+	if status == string(tfjson.CheckStatusUnknown) {
+		return "condition_unknown"
+	}
+	return "condition_failed"
+}
@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const confluenceTemplateBody = `{{.Heading}}
+{{- if .Groups}}
+<ul>
+{{- range .Groups}}
+<li>{{escape .Label}}<ul>
+{{- range .Addresses}}
+<li><code>{{escape .}}</code></li>
+{{- end}}
+</ul></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .ResourceChanges}}
+<ac:structured-macro ac:name="expand">
+<ac:parameter ac:name="title">{{escape .ChangeDetails}}</ac:parameter>
+<ac:rich-text-body>
+{{- range .ResourceChanges}}
+<ac:structured-macro ac:name="code">
+<ac:parameter ac:name="title">{{escape .Header}}</ac:parameter>
+<ac:parameter ac:name="language">diff</ac:parameter>
+<ac:plain-text-body>{{cdata .Body}}</ac:plain-text-body>
+</ac:structured-macro>
+{{- end}}
+</ac:rich-text-body>
+</ac:structured-macro>
+{{- end}}
+`
+
+type confluenceResource struct {
+	Header string
+	Body   string
+}
+
+// renderConfluence writes the plan in Confluence storage format,
+// with an expand macro instead of <details> and code macros for diffs.
+func (plan *PlanData) renderConfluence(w io.Writer) error {
+	funcMap := template.FuncMap{
+		"escape": html.EscapeString,
+		"cdata":  cdata,
+	}
+	t, err := template.New("confluence").Funcs(funcMap).Parse(confluenceTemplateBody)
+	if err != nil {
+		return fmt.Errorf("invalid template text: %w", err)
+	}
+	heading, err := plan.headingText()
+	if err != nil {
+		return err
+	}
+	if plan.options.HeadingLevel > 0 {
+		heading = fmt.Sprintf("<h%d>%s</h%d>", plan.options.HeadingLevel, html.EscapeString(heading), plan.options.HeadingLevel)
+	} else {
+		heading = fmt.Sprintf("<p>%s</p>", html.EscapeString(heading))
+	}
+
+	data := struct {
+		Heading         string
+		Groups          []addressGroup
+		ChangeDetails   string
+		ResourceChanges []confluenceResource
+	}{Heading: heading, Groups: plan.addressGroups(), ChangeDetails: plan.options.message("change_details")}
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return err
+		}
+		data.ResourceChanges = append(data.ResourceChanges, confluenceResource{Header: r.Header(), Body: body})
+	}
+
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// cdata wraps s in a CDATA section, splitting any "]]>" in s.
+func cdata(s string) string {
+	return "<![CDATA[" + strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
@@ -0,0 +1,35 @@
+package terraform
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+var csvHeader = []string{"address", "type", "provider", "action", "action_reason", "module"}
+
+// renderCSV writes one row per resource change, for importing into spreadsheets.
+func (plan *PlanData) renderCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	for _, r := range plan.ResourceChanges {
+		record := []string{
+			r.ResourceChange.Address,
+			r.ResourceChange.Type,
+			r.ResourceChange.ProviderName,
+			r.Action(),
+			r.ActionReason,
+			r.ResourceChange.ModuleAddress,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const mermaidClassDefs = `  classDef add fill:#e6ffec,stroke:#1a7f37
+  classDef change fill:#fff8c5,stroke:#9a6700
+  classDef destroy fill:#ffebe9,stroke:#cf222e
+  classDef replace fill:#fff1e5,stroke:#bc4c00
+`
+
+// DependencyGraph returns a mermaid flowchart of changed resources.
+// An edge is drawn from a resource to the resources whose configuration refers to it within the same module instance.
+func (plan *PlanData) DependencyGraph() string {
+	var nodes []ResourceChangeData
+	for _, r := range plan.ResourceChanges {
+		if !isMovedBlock(r.ResourceChange) {
+			nodes = append(nodes, r)
+		}
+	}
+
+	nodesByConfig := map[string][]int{}
+	for i, r := range nodes {
+		key := moduleInstanceAddress(r.ResourceChange.ModuleAddress, resourceConfigAddress(r.ResourceChange))
+		nodesByConfig[key] = append(nodesByConfig[key], i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, r := range nodes {
+		label := r.Address()
+		if marker := r.Marker(); marker != "" {
+			label = marker + " " + label
+		}
+		b.WriteString(fmt.Sprintf("  n%d[\"%s\"]:::%s\n", i, mermaidLabel(label), r.Action()))
+	}
+	edges := map[[2]int]struct{}{}
+	for i, r := range nodes {
+		cr := plan.configResource(r.ResourceChange)
+		if cr == nil {
+			continue
+		}
+		for _, ref := range configResourceReferences(cr) {
+			for _, from := range nodesByConfig[moduleInstanceAddress(r.ResourceChange.ModuleAddress, ref)] {
+				if from != i {
+					edges[[2]int{from, i}] = struct{}{}
+				}
+			}
+		}
+	}
+	sortedEdges := make([][2]int, 0, len(edges))
+	for e := range edges {
+		sortedEdges = append(sortedEdges, e)
+	}
+	sort.Slice(sortedEdges, func(i, j int) bool {
+		if sortedEdges[i][0] != sortedEdges[j][0] {
+			return sortedEdges[i][0] < sortedEdges[j][0]
+		}
+		return sortedEdges[i][1] < sortedEdges[j][1]
+	})
+	for _, e := range sortedEdges {
+		b.WriteString(fmt.Sprintf("  n%d --> n%d\n", e[0], e[1]))
+	}
+	b.WriteString(mermaidClassDefs)
+	return b.String()
+}
+
+// configResource returns the configuration of a resource change, or nil if the plan does not include it.
+func (plan *PlanData) configResource(rc *tfjson.ResourceChange) *tfjson.ConfigResource {
+	if plan.config == nil || plan.config.RootModule == nil {
+		return nil
+	}
+	module := plan.config.RootModule
+	if rc.ModuleAddress != "" {
+		calls := moduleCalls(plan.config, rc.ModuleAddress)
+		if len(calls) != len(moduleCallNames(rc.ModuleAddress)) || calls[len(calls)-1].Module == nil {
+			return nil
+		}
+		module = calls[len(calls)-1].Module
+	}
+	address := resourceConfigAddress(rc)
+	for _, r := range module.Resources {
+		if r.Address == address {
+			return r
+		}
+	}
+	return nil
+}
+
+// resourceConfigAddress returns the address of a resource in its module, without the instance key.
+func resourceConfigAddress(rc *tfjson.ResourceChange) string {
+	if rc.Mode == tfjson.DataResourceMode {
+		return fmt.Sprintf("data.%s.%s", rc.Type, rc.Name)
+	}
+	return fmt.Sprintf("%s.%s", rc.Type, rc.Name)
+}
+
+// configAddress returns the address of a resource in the configuration of a module,
+// without the instance keys of modules, e.g. module.a.aws_instance.web for module.a[0].
+func configAddress(moduleAddress, address string) string {
+	var prefix strings.Builder
+	for _, name := range moduleCallNames(moduleAddress) {
+		prefix.WriteString("module." + name + ".")
+	}
+	return prefix.String() + address
+}
+
+// moduleInstanceAddress returns the address of a resource in a module instance, keeping the instance keys of modules,
+// e.g. module.a[0].aws_instance.web, as configurations refer to the resources of their own module instances.
+func moduleInstanceAddress(moduleAddress, address string) string {
+	if moduleAddress == "" {
+		return address
+	}
+	return moduleAddress + "." + address
+}
+
+// configResourceReferences returns the addresses of resources referred to by a resource configuration.
+func configResourceReferences(cr *tfjson.ConfigResource) []string {
+	var refs []string
+	collect := func(e *tfjson.Expression) {
+		refs = append(refs, expressionReferences(e)...)
+	}
+	for _, e := range cr.Expressions {
+		collect(e)
+	}
+	collect(cr.CountExpression)
+	collect(cr.ForEachExpression)
+	refs = append(refs, cr.DependsOn...)
+
+	var addresses []string
+	for _, ref := range refs {
+		if address, ok := referenceResourceAddress(ref); ok {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+func expressionReferences(e *tfjson.Expression) []string {
+	if e == nil || e.ExpressionData == nil {
+		return nil
+	}
+	refs := append([]string{}, e.References...)
+	for _, block := range e.NestedBlocks {
+		for _, nested := range block {
+			refs = append(refs, expressionReferences(nested)...)
+		}
+	}
+	return refs
+}
+
+// referenceResourceAddress returns the resource address of a reference such as `aws_instance.web[0].id`.
+func referenceResourceAddress(ref string) (string, bool) {
+	parts := strings.Split(ref, ".")
+	switch parts[0] {
+	case "var", "local", "module", "path", "terraform", "count", "each", "self":
+		return "", false
+	case "data":
+		if len(parts) < 3 {
+			return "", false
+		}
+		return "data." + parts[1] + "." + trimIndex(parts[2]), true
+	}
+	if len(parts) < 2 {
+		return "", false
+	}
+	return parts[0] + "." + trimIndex(parts[1]), true
+}
+
+func trimIndex(s string) string {
+	if i := strings.Index(s, "["); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func mermaidLabel(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,121 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+var instanceKeyRegexp = regexp.MustCompile(`\[(?:"(?:[^"\\]|\\.)*"|[0-9]+)\]`)
+
+// driftedAttributes returns the attributes which changed outside of terraform and which terraform reported as relevant
+// to the plan, by the addresses of their resources, e.g. {"aws_vpc.main": ["aws_vpc.main.tags.Name"]}.
+func driftedAttributes(plan *tfjson.Plan) map[string][]string {
+	drifts := map[string]*tfjson.ResourceChange{}
+	for _, rc := range plan.ResourceDrift {
+		drifts[rc.Address] = rc
+	}
+	attributes := map[string][]string{}
+	for _, ra := range plan.RelevantAttributes {
+		rc, ok := drifts[ra.Resource]
+		if !ok || rc.Change == nil {
+			continue
+		}
+		path, ok := decodeAttributePath(ra.Attribute)
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(valueAtPath(rc.Change.Before, path), valueAtPath(rc.Change.After, path)) {
+			continue
+		}
+		attribute := ra.Resource + formatAttributePath(path)
+		if !containsString(attributes[ra.Resource], attribute) {
+			attributes[ra.Resource] = append(attributes[ra.Resource], attribute)
+		}
+	}
+	return attributes
+}
+
+// driftCauses returns the drifted attributes of a resource change and of the resources its configuration refers to.
+func (plan *PlanData) driftCauses(rc *tfjson.ResourceChange, drifted map[string][]string) []string {
+	if len(drifted) == 0 {
+		return nil
+	}
+	var causes []string
+	causes = append(causes, drifted[rc.Address]...)
+	if cr := plan.configResource(rc); cr != nil {
+		refs := map[string]bool{}
+		for _, ref := range configResourceReferences(cr) {
+			refs[configAddress(rc.ModuleAddress, ref)] = true
+		}
+		var resources []string
+		for resource := range drifted {
+			if resource != rc.Address && refs[instanceKeyRegexp.ReplaceAllString(resource, "")] {
+				resources = append(resources, resource)
+			}
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			causes = append(causes, drifted[resource]...)
+		}
+	}
+	return causes
+}
+
+// decodeAttributePath decodes the steps of an attribute path in the plan, which are names or indices.
+func decodeAttributePath(raw []json.RawMessage) ([]interface{}, bool) {
+	path := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		var step interface{}
+		if err := json.Unmarshal(r, &step); err != nil {
+			return nil, false
+		}
+		switch step.(type) {
+		case string, float64:
+			path = append(path, step)
+		default:
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// valueAtPath returns the value at an attribute path, or nil if it does not exist.
+func valueAtPath(v interface{}, path []interface{}) interface{} {
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[s]
+		case float64:
+			l, ok := v.([]interface{})
+			if !ok || int(s) < 0 || int(s) >= len(l) {
+				return nil
+			}
+			v = l[int(s)]
+		}
+	}
+	return v
+}
+
+// formatAttributePath returns an attribute path as a suffix of an address, e.g. `.ingress[0].cidr_blocks`.
+func formatAttributePath(path []interface{}) string {
+	var b strings.Builder
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			b.WriteString("." + s)
+		case float64:
+			b.WriteString(fmt.Sprintf("[%d]", int(s)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EmbeddedValue is a string value of an attribute recognized as a document of a language, e.g. a JSON policy
+// or a shell script of user_data.
+type EmbeddedValue struct {
+	// Path is the attribute path of the value, e.g. "policy" or "container[0].command".
+	Path string
+	// Language is the language hint of the value, "json", "yaml" or "shell".
+	Language string
+	Text     string
+}
+
+// EmbeddedValues returns the changed string values of the resource which are JSON, YAML or shell scripts,
+// in the order of their paths. The values after the change are used, or the ones before it for destroyed attributes.
+func (r ResourceChangeData) EmbeddedValues() []EmbeddedValue {
+	var values []EmbeddedValue
+	walkEmbeddedValues(&values, "", r.ResourceChange.Change.Before, r.ResourceChange.Change.After)
+	return values
+}
+
+// walkEmbeddedValues appends the embedded values changed under path, descending into objects and lists
+// in the same way as AttributePathRenderer.
+func walkEmbeddedValues(values *[]EmbeddedValue, p string, before, after any) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && len(beforeMap)+len(afterMap) > 0 {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for k := range beforeMap {
+			keys = append(keys, k)
+		}
+		for k := range afterMap {
+			if _, ok := beforeMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkEmbeddedValues(values, p+attributePathKey(p, k), beforeMap[k], afterMap[k])
+		}
+		return
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if (beforeIsList || before == nil) && (afterIsList || after == nil) && len(beforeList)+len(afterList) > 0 {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var bv, av any
+			if i < len(beforeList) {
+				bv = beforeList[i]
+			}
+			if i < len(afterList) {
+				av = afterList[i]
+			}
+			walkEmbeddedValues(values, fmt.Sprintf("%s[%d]", p, i), bv, av)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	v := after
+	if v == nil {
+		v = before
+	}
+	s, ok := v.(string)
+	if !ok {
+		return
+	}
+	if language := embeddedLanguage(s); language != "" {
+		*values = append(*values, EmbeddedValue{Path: p, Language: language, Text: strings.TrimRight(s, "\n")})
+	}
+}
+
+// embeddedLanguage returns the language of a string of several lines, or "" if it is not recognized.
+// Scripts are recognized by shebangs of shells, e.g. "#!/bin/bash", and YAML documents by keys at the top level,
+// including cloud-config of user_data.
+func embeddedLanguage(s string) string {
+	text := strings.TrimSpace(s)
+	if !strings.Contains(text, "\n") {
+		return ""
+	}
+	if (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Valid([]byte(text)) {
+		return "json"
+	}
+	if shebang, _, _ := strings.Cut(text, "\n"); strings.HasPrefix(shebang, "#!") {
+		fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+		if len(fields) > 1 && path.Base(fields[0]) == "env" {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && strings.HasSuffix(path.Base(fields[0]), "sh") {
+			return "shell"
+		}
+		return ""
+	}
+	var document map[string]interface{}
+	if yaml.Unmarshal([]byte(text), &document) == nil && len(document) > 0 {
+		return "yaml"
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package terraform
+
+import "fmt"
+
+// Conditions of plans mapped to exit codes by Options.ExitCodes, in the order of precedence
+const (
+	ExitPolicyViolation = "policy-violation"
+	ExitDestroysPresent = "destroys-present"
+	ExitChangesPresent  = "changes-present"
+)
+
+var exitConditions = []string{ExitPolicyViolation, ExitDestroysPresent, ExitChangesPresent}
+
+// DefaultPolicyViolationExitCode is the exit code of plans violating policies, e.g. over MaxDestroys, unless overridden.
+const DefaultPolicyViolationExitCode = 3
+
+// ExitCode returns the exit code of the first condition of the plan mapped by the options, or 0 if there is none,
+// so that scripts can tell outcomes apart without parsing the output.
+func (plan *PlanData) ExitCode() int {
+	summary := plan.Summary()
+	holds := map[string]bool{
+		ExitPolicyViolation: plan.HasPolicyViolations(),
+		ExitDestroysPresent: summary.HasDestructiveChanges(),
+		ExitChangesPresent:  summary.HasChanges(),
+	}
+	for _, condition := range exitConditions {
+		if code, ok := plan.options.ExitCodes[condition]; ok && holds[condition] {
+			return code
+		}
+	}
+	return 0
+}
+
+func validateExitCodes(codes map[string]int) error {
+	for condition, code := range codes {
+		if !containsString(exitConditions, condition) {
+			return fmt.Errorf("unknown exit condition %q (must be one of %v)", condition, exitConditions)
+		}
+		if code < 0 || code > 255 {
+			return fmt.Errorf("exit code of %s must be between 0 and 255: %d", condition, code)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package terraform
+
+import "fmt"
+
+// Levels of findings, which are the same as SARIF levels
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Rule is a check applied to every resource change.
+type Rule struct {
+	ID          string
+	Description string
+	Level       string
+	// check returns a message if the resource change violates the rule.
+	check func(r ResourceChangeData) (string, bool)
+}
+
+// Finding is a violation of a rule by a resource change.
+type Finding struct {
+	Rule           Rule
+	Message        string
+	ResourceChange ResourceChangeData
+}
+
+// changeKey identifies a resource change, as deposed objects share the address of the current object.
+type changeKey struct {
+	address    string
+	deposedKey string
+}
+
+func changeKeyOf(r ResourceChangeData) changeKey {
+	return changeKey{address: r.ResourceChange.Address, deposedKey: r.ResourceChange.DeposedKey}
+}
+
+// findingsByChange groups findings by their resource changes.
+func findingsByChange(findings []Finding) map[changeKey][]Finding {
+	m := map[changeKey][]Finding{}
+	for _, f := range findings {
+		key := changeKeyOf(f.ResourceChange)
+		m[key] = append(m[key], f)
+	}
+	return m
+}
+
+var builtinRules = []Rule{
+	{
+		ID:          "destroy",
+		Description: "Resource will be destroyed",
+		Level:       LevelError,
+		check: func(r ResourceChangeData) (string, bool) {
+			if !r.ResourceChange.Change.Actions.Delete() {
+				return "", false
+			}
+			return fmt.Sprintf("%s will be destroyed", r.Address()), true
+		},
+	},
+	{
+		ID:          "replace",
+		Description: "Resource will be replaced",
+		Level:       LevelWarning,
+		check: func(r ResourceChangeData) (string, bool) {
+			if !r.ResourceChange.Change.Actions.Replace() {
+				return "", false
+			}
+			return fmt.Sprintf("%s will be replaced", r.Address()), true
+		},
+	},
+}
+
+// Rules returns the rules checked against the plan.
+func (plan *PlanData) Rules() []Rule {
+	return builtinRules
+}
+
+// Findings returns the violations of the rules, in the order of resource changes.
+func (plan *PlanData) Findings() []Finding {
+	var findings []Finding
+	for _, r := range plan.ResourceChanges {
+		if isMovedBlock(r.ResourceChange) {
+			continue
+		}
+		for _, rule := range plan.Rules() {
+			if msg, ok := rule.check(r); ok {
+				findings = append(findings, Finding{Rule: rule, Message: msg, ResourceChange: r})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,338 @@
+package terraform
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AddFlags defines the flags of the options, defaulting to the current values, so that
+// the command line and the parameters of the server share the same names.
+func (o *Options) AddFlags(flags *flag.FlagSet) {
+	flags.Var((*invertedBool)(&o.EscapeHTML), "no-escape-html", "prevent <, >, and & from being escaped in JSON strings")
+	flags.StringVar(&o.Format, "format", o.Format, "output format: markdown, html, asciidoc, confluence, teams, csv, sarif, junit, tap, term, jsonpatch, changelog, commit-msg, title or status")
+	flags.StringVar(&o.Label, "label", o.Label, "label of the plan among others, e.g. a workspace or a directory like prod/network, appended to the title and status formats")
+	flags.BoolVar(&o.RawValues, "raw-values", o.RawValues, "render values exactly as terraform stores them, without pretty printing embedded JSON")
+	flags.BoolVar(&o.LegacyUnescape, "legacy-unescape", o.LegacyUnescape, "unescape line breaks and quotes in the whole JSON document like older versions")
+	flags.StringVar(&o.Sort, "sort", o.Sort, "order of addresses and resource changes: alpha, action, type or module (default: terraform's order)")
+	flags.IntVar(&o.HeadingLevel, "heading-level", o.HeadingLevel, "markdown heading level of the summary line (0 renders it as plain text)")
+	flags.StringVar(&o.HeadingFormat, "heading-format", o.HeadingFormat, "template of the summary line, e.g. '{{len .CreatedAddresses}} added' (default: terraform-like counts)")
+	flags.StringVar(&o.CodeFenceChar, "code-fence-char", o.CodeFenceChar, "character of code fences, ` or ~")
+	flags.IntVar(&o.CodeFenceLength, "code-fence-length", o.CodeFenceLength, "number of characters of code fences")
+	flags.StringVar(&o.CodeLanguage, "code-language", o.CodeLanguage, "language hint of diff blocks (empty for none)")
+	flags.StringVar(&o.SummaryStyle, "summary-style", o.SummaryStyle, "style of the summary: list or table")
+	flags.StringVar(&o.DetailStyle, "detail-style", o.DetailStyle, "style of the changes of each resource: diff (unified diffs), paths (changed values by attribute path, e.g. 'tags.Name: \"a\" → \"b\"') full (the whole documents before and after) or hcl (resource blocks like the output of terraform plan)")
+	flags.BoolVar(&o.CollapseUnchanged, "collapse-unchanged", o.CollapseUnchanged, "diff the whole documents and replace runs of unchanged lines beyond the context of changes with '… N unchanged lines …' instead of hunk headers")
+	flags.IntVar(&o.MaxDiffLines, "max-diff-lines", o.MaxDiffLines, "truncate the diffs of resources longer than this many lines with a note of the number of the rest (0 for no limit)")
+	flags.BoolVar(&o.DiffStats, "diff-stats", o.DiffStats, "append the numbers of added and removed lines of the diffs to the headers of resources, e.g. '(+12 / -4 lines)'")
+	flags.BoolVar(&o.Anchors, "anchors", o.Anchors, "link the addresses in the summary to anchors at the details of their resources")
+	flags.Var((*listFlag)(&o.FullDocumentTypes), "full-documents", "comma-separated globs of resource types rendered as the whole documents before and after their changes, as --detail-style full (repeatable)")
+	flags.BoolVar(&o.PieChart, "pie-chart", o.PieChart, "render a mermaid pie chart of action counts below the heading")
+	flags.BoolVar(&o.DependencyGraph, "dependency-graph", o.DependencyGraph, "render a mermaid flowchart of changed resources and their dependencies")
+	flags.StringVar(&o.Profile, "profile", o.Profile, "output profile: screen, or print for printing to PDF (no collapsed sections, full diffs)")
+	flags.StringVar(&o.Theme, "theme", o.Theme, "color theme of html output: light, dark, auto or high-contrast")
+	flags.BoolVar(&o.Accessible, "accessible", o.Accessible, "never convey actions by colors or emoji alone: text markers like '[ADD]' in headers and graphs, and accessible html")
+	flags.Var((*invertedBool)(&o.Color), "no-color", "disable ANSI colors of the term format")
+	flags.BoolVar(&o.RegistryLinks, "registry-links", o.RegistryLinks, "link the types of resources in the details to their documentation on registry.terraform.io")
+	flags.BoolVar(&o.ModuleLinks, "module-links", o.ModuleLinks, "list the modules of changed resources in the summary, linked to their git or registry sources")
+	flags.Var((*linkTemplatesFlag)(&o.LinkTemplates), "link-template", "link of addresses of resources as 'pattern=template', where pattern is a glob of types or of module addresses starting with 'module.' (repeatable)")
+	flags.BoolVar(&o.ShowProvider, "show-provider", o.ShowProvider, "append the provider of each resource, with the alias if any, to the headers of the details")
+	flags.StringVar(&o.GroupBy, "group-by", o.GroupBy, "group the summary list in addition to actions: provider or module (default: actions only)")
+	flags.IntVar(&o.ModuleDepth, "module-depth", o.ModuleDepth, "number of module calls of module addresses grouped by --group-by module, e.g. 1 groups module.a.module.b into module.a (0 for no limit)")
+	flags.BoolVar(&o.ModuleTotals, "module-totals", o.ModuleTotals, "add a table of the number of changes for each top-level module to the summary")
+	flags.BoolVar(&o.ReplaceOrder, "replace-order", o.ReplaceOrder, "distinguish replacements creating the new resource first (+/-) from the ones destroying the old one first (-/+)")
+	flags.BoolVar(&o.HideComputedChurn, "hide-computed-churn", o.HideComputedChurn, "collapse the diffs of updates which only change computed attributes into a line")
+	flags.StringVar(&o.TagOnlyUpdates, "tag-only-updates", o.TagOnlyUpdates, "list updates changing only tags or labels separately: group (with their diffs in their own section) or summary (without diffs)")
+	flags.BoolVar(&o.ReplaceMarkers, "replace-markers", o.ReplaceMarkers, "append \"# forces replacement\" to the lines of attributes forcing replacement in diffs, as terraform does")
+	flags.StringVar(&o.Query, "query", o.Query, "jq expression applied to the values before and after each change before diffing, e.g. 'del(.tags_all)'")
+	flags.Var((*attributeAllowlistsFlag)(&o.AttributeAllowlists), "attributes", "attributes shown in the diffs of resources of types matching a glob as 'pattern=attr,...', e.g. 'aws_instance=ami,instance_type' (repeatable)")
+	flags.Var((*stringsFlag)(&o.RedactPatterns), "redact", "regular expression whose matches in values are replaced with [REDACTED], e.g. 'AKIA[0-9A-Z]{16}' (repeatable)")
+	flags.BoolVar(&o.Checklist, "checklist", o.Checklist, "append a checklist for reviewers with an item for each destroyed or replaced resource")
+	flags.IntVar(&o.MaxDestroys, "max-destroys", o.MaxDestroys, "warn about plans destroying more resources than this, a policy violation exiting with 3 by default (0 for no limit)")
+	flags.IntVar(&o.MaxReplaces, "max-replaces", o.MaxReplaces, "warn about plans replacing more resources than this, a policy violation exiting with 3 by default (0 for no limit)")
+	flags.IntVar(&o.MaxChanges, "max-changes", o.MaxChanges, "warn about plans adding, changing, destroying and replacing more resources in total than this, a policy violation exiting with 3 by default (0 for no limit)")
+	flags.Var((*listFlag)(&o.FailOnTypes), "fail-on-types", "comma-separated globs of resource types which must not be updated, destroyed or replaced, e.g. 'aws_db_instance,aws_s3_bucket', a policy violation exiting with 3 by default (repeatable)")
+	flags.Var((*listFlag)(&o.WarnOnTypes), "warn-on-types", "comma-separated globs of resource types whose updates, destructions and replacements are annotated with \"⚠ review required\" without failing (repeatable)")
+	flags.Var((*severitiesFlag)(&o.Severities), "severity", "severity of an action as 'action=severity': create, update, delete or replace, and low, medium or high (default create=low,update=medium,delete=high,replace=high, repeatable)")
+	flags.BoolVar(&o.ShowSeverity, "show-severity", o.ShowSeverity, "append the severities of actions to their labels in the summary, e.g. '🔴 high'")
+	flags.DurationVar(&o.MaxPlanAge, "max-plan-age", o.MaxPlanAge, "warn about plans generated longer ago than this, e.g. 24h (0 for no limit)")
+	flags.BoolVar(&o.DriftOnly, "drift-only", o.DriftOnly, "render the changes of resources outside of terraform (resource_drift) instead of the planned changes, for drift reports")
+	flags.IntVar(&o.MaxDetailedResources, "max-detailed-resources", o.MaxDetailedResources, "omit the details of plans changing more resources than this, leaving the summary and a note (0 for no limit)")
+	flags.BoolVar(&o.DestructiveFirst, "destructive-first", o.DestructiveFirst, "move the details of destroyed and replaced resources to a section at the top, so that the riskiest changes are read first")
+	flags.BoolVar(&o.HighlightEmbedded, "highlight-embedded", o.HighlightEmbedded, "render changed values which are JSON, YAML or shell scripts, e.g. policies and user_data, in their own code blocks with language hints below the diffs")
+	flags.Var((*exitCodesFlag)(&o.ExitCodes), "exit-code", "exit code of a condition as 'condition=code': changes-present, destroys-present or policy-violation (default policy-violation=3, repeatable)")
+	flags.StringVar(&o.Workspace, "workspace", o.Workspace, "terraform workspace the plan targets, rendered below the heading")
+	flags.StringVar(&o.Backend, "backend", o.Backend, "type of the backend of the state the plan targets, e.g. s3, rendered below the heading")
+	flags.StringVar(&o.StateAddress, "state-address", o.StateAddress, "location of the state the plan targets in the backend, e.g. s3://bucket/key, rendered below the heading")
+	flags.StringVar(&o.Lang, "lang", o.Lang, "language of generated text: en or ja")
+	flags.Var((*messagesFlag)(&o.Messages), "message", "override of generated text as 'ID=text', e.g. 'created=será creado' (repeatable)")
+	flags.StringVar(&o.DetailsURL, "details-url", o.DetailsURL, "URL of the full report linked from the footer of markdown and from summary formats like teams")
+}
+
+// ParseOptions returns defaults overridden by params, whose names and values are the flags of AddFlags,
+// e.g. the query parameters of the server.
+func ParseOptions(defaults Options, params map[string][]string) (Options, error) {
+	opts := defaults
+	flags := flag.NewFlagSet("options", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opts.AddFlags(flags)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var args []string
+	for _, name := range names {
+		for _, v := range params[name] {
+			args = append(args, "-"+name+"="+v)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, opts.Validate()
+}
+
+// requestOptions are the flags of AddFlags which untrusted requests may set. Templates, jq expressions,
+// regular expressions and overrides of messages are not, as their values may take unbounded time or
+// memory to render; they are left to the defaults of the server.
+var requestOptions = map[string]bool{
+	"no-escape-html": true, "format": true, "label": true, "raw-values": true, "legacy-unescape": true,
+	"sort": true, "heading-level": true, "code-fence-char": true, "code-fence-length": true, "code-language": true,
+	"summary-style": true, "detail-style": true, "collapse-unchanged": true, "max-diff-lines": true,
+	"diff-stats": true, "anchors": true, "full-documents": true, "pie-chart": true, "dependency-graph": true,
+	"profile": true, "theme": true, "accessible": true, "no-color": true, "registry-links": true,
+	"module-links": true, "show-provider": true, "group-by": true, "module-depth": true, "module-totals": true,
+	"replace-order": true, "hide-computed-churn": true, "tag-only-updates": true, "replace-markers": true,
+	"checklist": true, "warn-on-types": true, "severity": true, "show-severity": true, "drift-only": true,
+	"max-detailed-resources": true, "destructive-first": true, "highlight-embedded": true, "lang": true,
+}
+
+// ParseRequestOptions is ParseOptions for params of untrusted requests, e.g. of the server,
+// rejecting the flags which requests may not set.
+func ParseRequestOptions(defaults Options, params map[string][]string) (Options, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !requestOptions[name] {
+			return defaults, fmt.Errorf("option %q cannot be set by requests", name)
+		}
+	}
+	return ParseOptions(defaults, params)
+}
+
+// messagesFlag adds 'ID=text' flags to the overrides of messages.
+type messagesFlag map[string]string
+
+func (m *messagesFlag) String() string {
+	if m == nil {
+		return ""
+	}
+	var pairs []string
+	for id, text := range *m {
+		pairs = append(pairs, id+"="+text)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m *messagesFlag) Set(s string) error {
+	id, text, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("message must be 'ID=text': %q", s)
+	}
+	// Copy the map, which may be shared with the defaults the options were copied from.
+	messages := make(messagesFlag, len(*m)+1)
+	for k, v := range *m {
+		messages[k] = v
+	}
+	messages[strings.TrimSpace(id)] = text
+	*m = messages
+	return nil
+}
+
+// severitiesFlag adds 'action=severity' flags to the severities of actions.
+type severitiesFlag map[string]string
+
+func (f *severitiesFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	var pairs []string
+	for action, severity := range *f {
+		pairs = append(pairs, action+"="+severity)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *severitiesFlag) Set(s string) error {
+	action, severity, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("severity must be 'action=severity': %q", s)
+	}
+	// Copy the map, which may be shared with the defaults the options were copied from.
+	severities := make(severitiesFlag, len(*f)+1)
+	for k, v := range *f {
+		severities[k] = v
+	}
+	severities[strings.TrimSpace(action)] = strings.TrimSpace(severity)
+	*f = severities
+	return nil
+}
+
+// exitCodesFlag adds 'condition=code' flags to the exit codes.
+type exitCodesFlag map[string]int
+
+func (e *exitCodesFlag) String() string {
+	if e == nil {
+		return ""
+	}
+	var pairs []string
+	for condition, code := range *e {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", condition, code))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (e *exitCodesFlag) Set(s string) error {
+	condition, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("exit code must be 'condition=code': %q", s)
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("exit code must be 'condition=code': %q", s)
+	}
+	// Copy the map, which may be shared with the defaults the options were copied from.
+	codes := make(exitCodesFlag, len(*e)+1)
+	for k, v := range *e {
+		codes[k] = v
+	}
+	codes[strings.TrimSpace(condition)] = code
+	*e = codes
+	return nil
+}
+
+// linkTemplatesFlag appends 'pattern=template' flags to the link templates.
+type linkTemplatesFlag []LinkTemplate
+
+func (l *linkTemplatesFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	var pairs []string
+	for _, t := range *l {
+		pairs = append(pairs, t.Pattern+"="+t.Template)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l *linkTemplatesFlag) Set(s string) error {
+	pattern, tmpl, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("link template must be 'pattern=template': %q", s)
+	}
+	// Copy the slice, which may be shared with the defaults the options were copied from.
+	templates := append(linkTemplatesFlag{}, *l...)
+	*l = append(templates, LinkTemplate{Pattern: strings.TrimSpace(pattern), Template: tmpl})
+	return nil
+}
+
+// attributeAllowlistsFlag appends 'pattern=attr,...' flags to the attribute allowlists.
+type attributeAllowlistsFlag []AttributeAllowlist
+
+func (a *attributeAllowlistsFlag) String() string {
+	if a == nil {
+		return ""
+	}
+	var pairs []string
+	for _, l := range *a {
+		pairs = append(pairs, l.Pattern+"="+strings.Join(l.Attributes, ","))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (a *attributeAllowlistsFlag) Set(s string) error {
+	pattern, list, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("attribute allowlist must be 'pattern=attr,...': %q", s)
+	}
+	var attributes []string
+	for _, attr := range strings.Split(list, ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+	// Copy the slice, which may be shared with the defaults the options were copied from.
+	allowlists := append(attributeAllowlistsFlag{}, *a...)
+	*a = append(allowlists, AttributeAllowlist{Pattern: strings.TrimSpace(pattern), Attributes: attributes})
+	return nil
+}
+
+// stringsFlag appends the values of a repeatable flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(*s, " ")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	// Copy the slice, which may be shared with the defaults the options were copied from.
+	*s = append(append(stringsFlag{}, *s...), v)
+	return nil
+}
+
+// listFlag appends the comma-separated values of a repeatable flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	// Copy the slice, which may be shared with the defaults the options were copied from.
+	list := append(listFlag{}, *l...)
+	for _, e := range strings.Split(v, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// invertedBool is a boolean flag which sets false to the value.
+type invertedBool bool
+
+func (b *invertedBool) String() string {
+	if b == nil {
+		return "false"
+	}
+	return fmt.Sprint(!bool(*b))
+}
+
+func (b *invertedBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b = invertedBool(!v)
+	return nil
+}
+
+func (b *invertedBool) IsBoolFlag() bool { return true }
@@ -0,0 +1,50 @@
+package terraform
+
+import (
+	"fmt"
+	"path"
+)
+
+// FullDocuments are the whole values of a resource before and after its change, in the documents of unified diffs.
+// Before is empty for created resources and After for destroyed ones.
+type FullDocuments struct {
+	Before string
+	After  string
+}
+
+// IsFullDocuments reports whether the resource is rendered as its documents before and after the change instead of
+// a diff, for DetailStyleFull or a type in FullDocumentTypes of the options.
+func (r ResourceChangeData) IsFullDocuments() bool {
+	return r.options.DetailStyle == DetailStyleFull || r.typeIn(r.options.FullDocumentTypes)
+}
+
+// FullDocuments returns the whole values of the resource before and after the change.
+func (r ResourceChangeData) FullDocuments() (FullDocuments, error) {
+	renderer := NewUnifiedDiffRenderer(r.ResourceChange, r.options)
+	var documents FullDocuments
+	if r.ResourceChange.Change.Before != nil {
+		before, _, err := renderer.marshalChangeBefore()
+		if err != nil {
+			return FullDocuments{}, fmt.Errorf("invalid resource changes (before): %w", err)
+		}
+		documents.Before = string(before)
+	}
+	if r.ResourceChange.Change.After != nil {
+		after, _, err := renderer.marshalChangeAfter()
+		if err != nil {
+			return FullDocuments{}, fmt.Errorf("invalid resource changes (after): %w", err)
+		}
+		documents.After = string(after)
+	}
+	return documents, nil
+}
+
+// typeIn reports whether the type of the resource matches one of patterns.
+func (r ResourceChangeData) typeIn(patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, r.ResourceChange.Type); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,407 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Graph is a graph of the output of `terraform graph` in the DOT language, rendered as a mermaid flowchart.
+type Graph struct {
+	// Direction is the direction of the flowchart, e.g. "RL" from rankdir of the graph.
+	Direction string
+	Nodes     []GraphNode
+	// Edges are the pairs of the indices of the nodes of edges in the order of the graph.
+	Edges    [][2]int
+	Clusters []*GraphCluster
+	options  Options
+}
+
+type GraphNode struct {
+	// Address is the address of the node without the decorations of older versions of terraform,
+	// e.g. "aws_instance.web" for `[root] aws_instance.web (expand)`.
+	Address string
+	Label   string
+	// Action is the action of the changes of the resource in the highlighted plan, or "" if there is none.
+	Action string
+	// Cluster is the cluster of the node, or nil if the node is not in any clusters.
+	Cluster *GraphCluster
+}
+
+// GraphCluster is a subgraph drawn as a box, whose name starts with "cluster", e.g. a module of terraform.
+type GraphCluster struct {
+	Label    string
+	Clusters []*GraphCluster
+}
+
+var graphNodeDecoration = regexp.MustCompile(`^\[root\] | \([^()]*\)$`)
+
+// NewGraph reads a graph in the DOT language from input. If plan is not nil, the nodes of resources
+// changed by the plan are highlighted with their actions. Instances of the same resource share the node of the
+// resource, which is highlighted with the most destructive action of the instances.
+func NewGraph(input io.Reader, plan *PlanData, opts Options) (*Graph, error) {
+	b, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	g := &Graph{Direction: "LR", options: opts}
+	p := &dotParser{tokens: dotTokens(string(b)), graph: g, nodes: map[string]int{}}
+	if err := p.parseGraph(); err != nil {
+		return nil, fmt.Errorf("cannot parse graph: %w", err)
+	}
+	if plan == nil {
+		return g, nil
+	}
+
+	actions := map[string]string{}
+	ranks := map[string]int{}
+	for _, r := range plan.ResourceChanges {
+		if isMovedBlock(r.ResourceChange) {
+			continue
+		}
+		address := configAddress(r.ResourceChange.ModuleAddress, resourceConfigAddress(r.ResourceChange))
+		if rank, ok := ranks[address]; !ok || actionRank(r.ResourceChange) > rank {
+			actions[address] = r.Action()
+			ranks[address] = actionRank(r.ResourceChange)
+		}
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].Action = actions[g.Nodes[i].Address]
+	}
+	return g, nil
+}
+
+// Render writes the graph as a mermaid flowchart in a code block. Clusters are rendered as subgraphs.
+func (g *Graph) Render(w io.Writer) error {
+	o := g.options
+	fence := strings.Repeat(o.CodeFenceChar, o.CodeFenceLength)
+	var b strings.Builder
+	b.WriteString(fence + "mermaid\nflowchart " + g.Direction + "\n")
+	highlighted := false
+	writeNodes := func(cluster *GraphCluster, indent string) {
+		for i, n := range g.Nodes {
+			if n.Cluster != cluster {
+				continue
+			}
+			label := n.Label
+			if o.Accessible && n.Action != "" {
+				label = o.message("marker_"+n.Action) + " " + label
+			}
+			fmt.Fprintf(&b, "%sn%d[\"%s\"]", indent, i, mermaidLabel(label))
+			if n.Action != "" {
+				b.WriteString(":::" + n.Action)
+				highlighted = true
+			}
+			b.WriteString("\n")
+		}
+	}
+	ids := map[*GraphCluster]int{}
+	var writeCluster func(c *GraphCluster, indent string)
+	writeCluster = func(c *GraphCluster, indent string) {
+		ids[c] = len(ids)
+		fmt.Fprintf(&b, "%ssubgraph c%d[\"%s\"]\n", indent, ids[c], mermaidLabel(c.Label))
+		writeNodes(c, indent+"  ")
+		for _, child := range c.Clusters {
+			writeCluster(child, indent+"  ")
+		}
+		b.WriteString(indent + "end\n")
+	}
+	writeNodes(nil, "  ")
+	for _, c := range g.Clusters {
+		writeCluster(c, "  ")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  n%d --> n%d\n", e[0], e[1])
+	}
+	if highlighted {
+		b.WriteString(mermaidClassDefs)
+	}
+	b.WriteString(fence + "\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
+
+// dotToken is a token of the DOT language. Quoted strings are unquoted.
+type dotToken struct {
+	text   string
+	quoted bool
+}
+
+// dotTokens splits a graph in the DOT language into tokens, skipping comments.
+func dotTokens(s string) []dotToken {
+	var tokens []dotToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)) || c == ',' || c == ';':
+			i++
+		case strings.HasPrefix(s[i:], "//") || c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case strings.HasPrefix(s[i:], "->") || strings.HasPrefix(s[i:], "--"):
+			tokens = append(tokens, dotToken{text: "->"})
+			i += 2
+		case strings.ContainsRune("{}[]=:", rune(c)):
+			tokens = append(tokens, dotToken{text: string(c)})
+			i++
+		case c == '"':
+			var text strings.Builder
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && s[i+1] == '"' {
+					i++
+				}
+				text.WriteByte(s[i])
+			}
+			i++
+			tokens = append(tokens, dotToken{text: text.String(), quoted: true})
+		case c == '<':
+			// HTML strings nest angle brackets.
+			depth, start := 0, i
+			for ; i < len(s); i++ {
+				if s[i] == '<' {
+					depth++
+				} else if s[i] == '>' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			tokens = append(tokens, dotToken{text: s[start+1 : min(i, len(s))], quoted: true})
+			i++
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune("{}[]=:,;\"<", rune(s[i])) && !strings.HasPrefix(s[i:], "->") && !strings.HasPrefix(s[i:], "--") {
+				i++
+			}
+			tokens = append(tokens, dotToken{text: s[start:i]})
+		}
+	}
+	return tokens
+}
+
+type dotParser struct {
+	tokens []dotToken
+	pos    int
+	graph  *Graph
+	// nodes are the indices of the nodes by ID.
+	nodes map[string]int
+}
+
+func (p *dotParser) peek() (dotToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return dotToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword reports whether the next token is the keyword, case-insensitively as in the DOT language.
+func (p *dotParser) keyword(k string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && strings.EqualFold(t.text, k)
+}
+
+// symbol reports whether the next token is the symbol.
+func (p *dotParser) symbol(s string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && t.text == s
+}
+
+func (p *dotParser) expect(s string) error {
+	if !p.symbol(s) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+
+func (p *dotParser) unexpected() error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("unexpected end of graph")
+	}
+	return fmt.Errorf("unexpected %q", t.text)
+}
+
+// id returns the next token as an ID.
+func (p *dotParser) id() (string, error) {
+	t, ok := p.peek()
+	if !ok || (!t.quoted && (strings.ContainsAny(t.text, "{}[]=:") || t.text == "->")) {
+		return "", p.unexpected()
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *dotParser) parseGraph() error {
+	if p.keyword("strict") {
+		p.pos++
+	}
+	if !p.keyword("digraph") && !p.keyword("graph") {
+		return p.unexpected()
+	}
+	p.pos++
+	if !p.symbol("{") {
+		if _, err := p.id(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseStatements(nil)
+}
+
+// parseStatements parses the statements of a graph or a subgraph in the cluster until "}".
+func (p *dotParser) parseStatements(cluster *GraphCluster) error {
+	for !p.symbol("}") {
+		if err := p.parseStatement(cluster); err != nil {
+			return err
+		}
+	}
+	p.pos++
+	return nil
+}
+
+func (p *dotParser) parseStatement(cluster *GraphCluster) error {
+	switch {
+	case p.keyword("graph") || p.keyword("node") || p.keyword("edge"):
+		p.pos++
+		_, err := p.parseAttributes()
+		return err
+	case p.keyword("subgraph") || p.symbol("{"):
+		return p.parseSubgraph(cluster)
+	}
+	id, err := p.id()
+	if err != nil {
+		return err
+	}
+	if p.symbol("=") {
+		p.pos++
+		value, err := p.id()
+		if err != nil {
+			return err
+		}
+		if cluster == nil && id == "rankdir" {
+			p.graph.Direction = strings.ToUpper(value)
+		} else if cluster != nil && id == "label" {
+			cluster.Label = value
+		}
+		return nil
+	}
+	if err := p.skipPort(); err != nil {
+		return err
+	}
+	ids := []string{id}
+	for p.symbol("->") {
+		p.pos++
+		id, err := p.id()
+		if err != nil {
+			return err
+		}
+		if err := p.skipPort(); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	attributes, err := p.parseAttributes()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 1 {
+		i := p.node(id, cluster)
+		if label, ok := attributes["label"]; ok {
+			p.graph.Nodes[i].Label = graphNodeDecoration.ReplaceAllString(label, "")
+		}
+		return nil
+	}
+	for i := 1; i < len(ids); i++ {
+		p.graph.Edges = append(p.graph.Edges, [2]int{p.node(ids[i-1], cluster), p.node(ids[i], cluster)})
+	}
+	return nil
+}
+
+// parseSubgraph parses a subgraph. Subgraphs whose names start with "cluster" become clusters;
+// the nodes of other subgraphs belong to the cluster of the subgraph.
+func (p *dotParser) parseSubgraph(cluster *GraphCluster) error {
+	name := ""
+	if p.keyword("subgraph") {
+		p.pos++
+		if !p.symbol("{") {
+			id, err := p.id()
+			if err != nil {
+				return err
+			}
+			name = id
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(name, "cluster") {
+		return p.parseStatements(cluster)
+	}
+	c := &GraphCluster{Label: strings.TrimLeft(strings.TrimPrefix(name, "cluster"), "_")}
+	if cluster == nil {
+		p.graph.Clusters = append(p.graph.Clusters, c)
+	} else {
+		cluster.Clusters = append(cluster.Clusters, c)
+	}
+	return p.parseStatements(c)
+}
+
+// parseAttributes parses attribute lists, e.g. `[label = "a", shape = "box"]`, if any.
+func (p *dotParser) parseAttributes() (map[string]string, error) {
+	attributes := map[string]string{}
+	for p.symbol("[") {
+		p.pos++
+		for !p.symbol("]") {
+			key, err := p.id()
+			if err != nil {
+				return nil, err
+			}
+			value := "true"
+			if p.symbol("=") {
+				p.pos++
+				if value, err = p.id(); err != nil {
+					return nil, err
+				}
+			}
+			attributes[key] = value
+		}
+		p.pos++
+	}
+	return attributes, nil
+}
+
+// skipPort skips the port of a node ID, e.g. `:n` of `a:n`, which does not matter to flowcharts.
+func (p *dotParser) skipPort() error {
+	for p.symbol(":") {
+		p.pos++
+		if _, err := p.id(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// node returns the index of the node of the ID, adding the node to the cluster if the ID is new.
+func (p *dotParser) node(id string, cluster *GraphCluster) int {
+	if i, ok := p.nodes[id]; ok {
+		return i
+	}
+	address := graphNodeDecoration.ReplaceAllString(id, "")
+	p.graph.Nodes = append(p.graph.Nodes, GraphNode{Address: address, Label: address, Cluster: cluster})
+	p.nodes[id] = len(p.graph.Nodes) - 1
+	return p.nodes[id]
+}
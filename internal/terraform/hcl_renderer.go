@@ -0,0 +1,447 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-json/sanitize"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// sensitiveHCLValue replaces sensitive values as in the output of `terraform plan`.
+const sensitiveHCLValue = "(sensitive value)"
+
+// identifyingAttributes are shown in updated resources even if they are unchanged, as terraform does.
+var identifyingAttributes = []string{"id", "name"}
+
+// HCLRenderer renders the changes of a resource in the style of the output of `terraform plan`: a resource block
+// whose attributes are marked with +, - and ~, values becoming unknown as (known after apply) and the unchanged
+// attributes of updated resources hidden. The markers are moved to the start of lines to keep diffs highlighted.
+type HCLRenderer struct {
+	ResourceChange   *tfjson.ResourceChange
+	EnableEscapeHTML bool
+	// HeaderSuffix follows the address in the header, e.g. "will be created".
+	HeaderSuffix string
+	// ReplacePaths are the attribute paths forcing replacement, e.g. [["ami"]].
+	ReplacePaths [][]interface{}
+	// ReplaceMarker follows the attributes forcing replacement, e.g. "forces replacement".
+	ReplaceMarker string
+	// HiddenMessages replace the unchanged attributes and elements of updated values, formatted with their number,
+	// by message ID: hidden_attribute(s) and hidden_element(s), e.g. "(%d unchanged attributes hidden)".
+	HiddenMessages map[string]string
+}
+
+func NewHCLRenderer(resourceChange *tfjson.ResourceChange, opts Options) *HCLRenderer {
+	r := &HCLRenderer{
+		ResourceChange:   resourceChange,
+		EnableEscapeHTML: opts.EscapeHTML,
+		HeaderSuffix:     opts.message(headerSuffixMessage(resourceChange, opts)),
+		ReplaceMarker:    opts.message("forces_replacement"),
+		HiddenMessages:   map[string]string{},
+	}
+	for _, id := range []string{"hidden_attribute", "hidden_attributes", "hidden_element", "hidden_elements"} {
+		r.HiddenMessages[id] = opts.message(id)
+	}
+	return r
+}
+
+func (r *HCLRenderer) Header() string {
+	return fmt.Sprintf("%s %s", r.ResourceChange.Address, r.HeaderSuffix)
+}
+
+// hclLine is a line of the block of a resource. Depth is the level of nesting, 0 for the resource.
+type hclLine struct {
+	marker string
+	depth  int
+	text   string
+}
+
+func (r *HCLRenderer) Render() (string, error) {
+	rc := r.ResourceChange
+	change := rc.Change
+	marker := "~"
+	switch {
+	case change.Actions.Create():
+		marker = "+"
+	case change.Actions.Delete():
+		marker = "-"
+	case change.Actions.CreateBeforeDestroy():
+		marker = "+/-"
+	case change.Actions.DestroyBeforeCreate():
+		marker = "-/+"
+	}
+	kind := "resource"
+	if rc.Mode == tfjson.DataResourceMode {
+		kind = "data"
+	}
+	lines := []hclLine{{marker: marker, text: fmt.Sprintf("%s %q %q {", kind, rc.Type, rc.Name)}}
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	body, err := r.object(1, nil, before, after, change.Before != nil, change.After != nil, change.AfterUnknown, true)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource changes: %w", err)
+	}
+	lines = append(lines, body...)
+	lines = append(lines, hclLine{marker: " ", text: "}"})
+
+	var b strings.Builder
+	for _, l := range lines {
+		// Terraform indents markers by 2 + 4 * depth columns, which are moved after the markers.
+		indent := max(2+4*l.depth-(len(l.marker)-1), 0)
+		b.WriteString(strings.TrimRight(l.marker+strings.Repeat(" ", indent)+" "+l.text, " ") + "\n")
+	}
+	return b.String(), nil
+}
+
+// object returns the lines of the attributes of objects at depth. Attributes are aligned by their names, and the
+// unchanged ones are hidden if both sides exist, except identifying attributes of resources.
+func (r *HCLRenderer) object(depth int, path []interface{}, before, after map[string]interface{}, hasBefore, hasAfter bool, unknown any, resource bool) ([]hclLine, error) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	if u, ok := unknown.(map[string]interface{}); ok {
+		for k, v := range u {
+			if _, ok := before[k]; !ok && v == true {
+				if _, ok := after[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	update := hasBefore && hasAfter
+	var shown []string
+	hidden := 0
+	for _, k := range keys {
+		bv, av, u := before[k], after[k], unknownAt(unknown, k)
+		switch {
+		case bv == nil && av == nil && u != true:
+			// Null attributes are omitted as in terraform.
+			continue
+		case update && u != true && reflect.DeepEqual(bv, av) && !(resource && containsString(identifyingAttributes, k)):
+			hidden++
+			continue
+		}
+		shown = append(shown, k)
+	}
+
+	width := 0
+	for _, k := range shown {
+		width = max(width, len(hclKey(k)))
+	}
+	var lines []hclLine
+	for _, k := range shown {
+		name := hclKey(k)
+		name += strings.Repeat(" ", width-len(name))
+		// The attributes of resources are removed with -> null, unlike the ones in removed values.
+		attribute, err := r.value(depth, appendPath(path, k), name+" = ", before[k], after[k], hasBefore, hasAfter || resource, unknownAt(unknown, k))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, attribute...)
+	}
+	if hidden > 0 {
+		noun := "element"
+		if resource {
+			noun = "attribute"
+		}
+		lines = append(lines, r.hiddenLine(depth, noun, hidden))
+	}
+	return lines, nil
+}
+
+// value returns the lines of a value at depth, starting with prefix, e.g. "name = ".
+func (r *HCLRenderer) value(depth int, path []interface{}, prefix string, before, after any, hasBefore, hasAfter bool, unknown any) ([]hclLine, error) {
+	marker := " "
+	switch {
+	case !hasBefore || before == nil:
+		marker, before = "+", nil
+	case (!hasAfter || after == nil) && unknown != true:
+		marker, after = "-", nil
+	case unknown == true || !reflect.DeepEqual(before, after):
+		marker = "~"
+	}
+	comment := ""
+	if r.ResourceChange.Change.Actions.Replace() && r.isReplacePath(path) {
+		comment = " # " + r.ReplaceMarker
+	}
+	suffix := ""
+	if marker == "-" && hasAfter {
+		suffix = " -> null"
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	beforeString, beforeIsString := before.(string)
+	afterString, afterIsString := after.(string)
+	_, unknownIsMap := unknown.(map[string]interface{})
+	_, unknownIsList := unknown.([]interface{})
+	switch {
+	case unknown == true:
+	case (beforeIsMap || before == nil) && (afterIsMap || after == nil) && (len(beforeMap)+len(afterMap) > 0 || unknownIsMap):
+		lines := []hclLine{{marker: marker, depth: depth, text: prefix + "{" + comment}}
+		body, err := r.object(depth+1, path, beforeMap, afterMap, before != nil, after != nil, unknown, false)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, body...)
+		return append(lines, hclLine{marker: " ", depth: depth, text: "}" + suffix}), nil
+	case (beforeIsList || before == nil) && (afterIsList || after == nil) && (len(beforeList)+len(afterList) > 0 || unknownIsList):
+		lines := []hclLine{{marker: marker, depth: depth, text: prefix + "[" + comment}}
+		body, err := r.list(depth+1, path, beforeList, afterList, unknown)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, body...)
+		return append(lines, hclLine{marker: " ", depth: depth, text: "]" + suffix}), nil
+	case (beforeIsString || before == nil) && (afterIsString || after == nil) &&
+		(strings.Contains(beforeString, "\n") || strings.Contains(afterString, "\n")):
+		lines := []hclLine{{marker: marker, depth: depth, text: prefix + "<<-EOT" + comment}}
+		lines = append(lines, heredocLines(depth+1, beforeString, afterString, before != nil, after != nil)...)
+		return append(lines, hclLine{marker: " ", depth: depth, text: "EOT" + suffix}), nil
+	case marker == "~" && (beforeIsMap || beforeIsList || afterIsMap || afterIsList):
+		// Values changing their types are replaced.
+		removed, err := r.value(depth, path, prefix, before, nil, true, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		added, err := r.value(depth, path, prefix, nil, after, false, true, unknown)
+		if err != nil {
+			return nil, err
+		}
+		return append(removed, added...), nil
+	}
+
+	text := prefix
+	switch marker {
+	case "+":
+		v, err := r.scalar(after, unknown)
+		if err != nil {
+			return nil, err
+		}
+		text += v
+	case "-", " ":
+		v, err := r.scalar(before, nil)
+		if err != nil {
+			return nil, err
+		}
+		text += v + suffix
+	default:
+		bv, err := r.scalar(before, nil)
+		if err != nil {
+			return nil, err
+		}
+		av, err := r.scalar(after, unknown)
+		if err != nil {
+			return nil, err
+		}
+		text += bv + " -> " + av
+	}
+	return []hclLine{{marker: marker, depth: depth, text: text + comment}}, nil
+}
+
+// list returns the lines of the elements of lists at depth. Elements are matched as lines of diffs, and
+// the elements replaced at the same positions are updated if both are objects or lists.
+func (r *HCLRenderer) list(depth int, path []interface{}, before, after []interface{}, unknown any) ([]hclLine, error) {
+	if u, ok := unknown.([]interface{}); ok && len(after) < len(u) {
+		after = append(after, make([]interface{}, len(u)-len(after))...)
+	}
+	beforeKeys, err := r.elementKeys(before)
+	if err != nil {
+		return nil, err
+	}
+	afterKeys, err := r.elementKeys(after)
+	if err != nil {
+		return nil, err
+	}
+	for j := range after {
+		if unknownAt(unknown, j) == true {
+			afterKeys[j] = unknownOutputValue
+		}
+	}
+
+	var lines []hclLine
+	hidden := 0
+	element := func(i, j int) error {
+		var bv, av any
+		hasBefore, hasAfter := i >= 0, j >= 0
+		if hasBefore {
+			bv = before[i]
+		}
+		var u any
+		index := i
+		if hasAfter {
+			av, u, index = after[j], unknownAt(unknown, j), j
+		}
+		element, err := r.value(depth, appendPath(path, index), "", bv, av, hasBefore, hasAfter, u)
+		if err != nil {
+			return err
+		}
+		element[len(element)-1].text += ","
+		lines = append(lines, element...)
+		return nil
+	}
+	for _, op := range difflib.NewMatcher(beforeKeys, afterKeys).GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			hidden += op.I2 - op.I1
+		case 'r':
+			i, j := op.I1, op.J1
+			for ; i < op.I2 && j < op.J2 && isCollection(before[i]) && isCollection(after[j]); i, j = i+1, j+1 {
+				if err := element(i, j); err != nil {
+					return nil, err
+				}
+			}
+			for ; i < op.I2; i++ {
+				if err := element(i, -1); err != nil {
+					return nil, err
+				}
+			}
+			for ; j < op.J2; j++ {
+				if err := element(-1, j); err != nil {
+					return nil, err
+				}
+			}
+		case 'd':
+			for i := op.I1; i < op.I2; i++ {
+				if err := element(i, -1); err != nil {
+					return nil, err
+				}
+			}
+		case 'i':
+			for j := op.J1; j < op.J2; j++ {
+				if err := element(-1, j); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if hidden > 0 {
+		lines = append(lines, r.hiddenLine(depth, "element", hidden))
+	}
+	return lines, nil
+}
+
+// hiddenLine returns the comment of the number of hidden attributes or elements, the noun.
+func (r *HCLRenderer) hiddenLine(depth int, noun string, count int) hclLine {
+	id := "hidden_" + noun
+	if count != 1 {
+		id += "s"
+	}
+	return hclLine{marker: " ", depth: depth, text: "# " + fmt.Sprintf(r.HiddenMessages[id], count)}
+}
+
+// elementKeys returns the elements of a list in JSON to match them.
+func (r *HCLRenderer) elementKeys(list []interface{}) ([]string, error) {
+	keys := make([]string, len(list))
+	for i, v := range list {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = string(b)
+	}
+	return keys, nil
+}
+
+// scalar returns a value in a line: (known after apply) if unknown is true, (sensitive value) for masked values,
+// or JSON otherwise.
+func (r *HCLRenderer) scalar(v any, unknown any) (string, error) {
+	if unknown == true {
+		return unknownOutputValue, nil
+	}
+	if v == sanitize.DefaultSensitiveValue {
+		return sensitiveHCLValue, nil
+	}
+	enc := valueEncoder{escapeHTML: r.EnableEscapeHTML}
+	b, err := enc.marshalScalar(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *HCLRenderer) isReplacePath(path []interface{}) bool {
+	for _, p := range r.ReplacePaths {
+		if len(p) == len(path) && fmt.Sprint(p) == fmt.Sprint(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// heredocLines returns the lines of heredocs of multi-line strings at depth, with the changed lines marked.
+func heredocLines(depth int, before, after string, hasBefore, hasAfter bool) []hclLine {
+	split := func(s string, ok bool) []string {
+		if !ok {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	beforeLines, afterLines := split(before, hasBefore), split(after, hasAfter)
+	var lines []hclLine
+	for _, op := range difflib.NewMatcher(beforeLines, afterLines).GetOpCodes() {
+		if op.Tag == 'e' {
+			for _, l := range beforeLines[op.I1:op.I2] {
+				lines = append(lines, hclLine{marker: " ", depth: depth, text: l})
+			}
+			continue
+		}
+		for _, l := range beforeLines[op.I1:op.I2] {
+			lines = append(lines, hclLine{marker: "-", depth: depth, text: l})
+		}
+		for _, l := range afterLines[op.J1:op.J2] {
+			lines = append(lines, hclLine{marker: "+", depth: depth, text: l})
+		}
+	}
+	return lines
+}
+
+// hclKey returns a key as an attribute name if it is an identifier, or a quoted string otherwise.
+func hclKey(k string) string {
+	if identifierRegexp.MatchString(k) {
+		return k
+	}
+	return fmt.Sprintf("%q", k)
+}
+
+// unknownAt returns the unknown marks of the element at key of the unknown marks of a collection.
+func unknownAt(unknown any, key any) any {
+	switch u := unknown.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			return u[k]
+		}
+	case []interface{}:
+		if i, ok := key.(int); ok && i < len(u) {
+			return u[i]
+		}
+	}
+	return nil
+}
+
+func isCollection(v any) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// appendPath returns a new attribute path of the step after path.
+func appendPath(path []interface{}, step any) []interface{} {
+	return append(path[:len(path):len(path)], step)
+}
@@ -0,0 +1,346 @@
+package terraform
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Themes of HTML output
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+	ThemeAuto  = "auto"
+	// ThemeHighContrast is light text on black with saturated colors of actions, for low vision.
+	ThemeHighContrast = "high-contrast"
+)
+
+var themes = []string{ThemeLight, ThemeDark, ThemeAuto, ThemeHighContrast}
+
+const htmlColorsTemplateBody = `{{define "lightColors"}}  --fg: #1f2328;
+  --bg: #ffffff;
+  --border: #d0d7de;
+  --code-bg: #f6f8fa;
+  --add: #1a7f37;
+  --add-bg: #dafbe1;
+  --add-word-bg: #aceebb;
+  --change: #9a6700;
+  --destroy: #cf222e;
+  --destroy-bg: #ffebe9;
+  --destroy-word-bg: #ffcecb;
+  --replace: #bc4c00;
+  --moved: #0969da;
+  --hunk: #8250df;{{end}}
+{{- define "darkColors"}}  --fg: #e6edf3;
+  --bg: #0d1117;
+  --border: #30363d;
+  --code-bg: #161b22;
+  --add: #3fb950;
+  --add-bg: #12261e;
+  --add-word-bg: #1f5a30;
+  --change: #d29922;
+  --destroy: #f85149;
+  --destroy-bg: #25171c;
+  --destroy-word-bg: #6e2228;
+  --replace: #db6d28;
+  --moved: #58a6ff;
+  --hunk: #bc8cff;{{end}}
+{{- define "highContrastColors"}}  --fg: #ffffff;
+  --bg: #000000;
+  --border: #ffffff;
+  --code-bg: #000000;
+  --add: #7ee787;
+  --add-bg: #002d11;
+  --add-word-bg: #006222;
+  --change: #ffdf5d;
+  --destroy: #ff9492;
+  --destroy-bg: #3d0008;
+  --destroy-word-bg: #86061d;
+  --replace: #ffb77c;
+  --moved: #91cbff;
+  --hunk: #dbb7ff;{{end}}`
+
+const htmlTemplateBody = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+{{- if eq .Theme "dark"}}
+:root {
+{{template "darkColors"}}
+}
+{{- else if eq .Theme "high-contrast"}}
+:root {
+{{template "highContrastColors"}}
+}
+{{- else}}
+:root {
+{{template "lightColors"}}
+}
+{{- if eq .Theme "auto"}}
+@media (prefers-color-scheme: dark) {
+  :root {
+{{template "darkColors"}}
+  }
+}
+{{- end}}
+{{- end}}
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: var(--fg); background: var(--bg); }
+h1 { font-size: 1.5em; }
+.toolbar { display: flex; flex-wrap: wrap; gap: 0.5em 1em; align-items: center; margin: 1em 0; }
+.toolbar input[type=search] { min-width: 20em; padding: 0.3em; color: var(--fg); background: var(--bg); border: 1px solid var(--border); }
+.resource { border: 1px solid var(--border); border-radius: 6px; margin: 0.5em 0; }
+.resource summary { cursor: pointer; padding: 0.5em; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
+.resource pre { margin: 0; padding: 0.5em; overflow-x: auto; background: var(--code-bg); border-top: 1px solid var(--border); }
+.action { display: inline-block; min-width: 5em; font-weight: bold; }
+.action-add { color: var(--add); }
+.action-change { color: var(--change); }
+.action-destroy { color: var(--destroy); }
+.action-replace { color: var(--replace); }
+.action-moved { color: var(--moved); }
+.line { display: inline-block; min-width: 100%; }
+.line-add { color: var(--add); background: var(--add-bg); }
+.line-destroy { color: var(--destroy); background: var(--destroy-bg); }
+.line-change { color: var(--change); }
+.line-replace { color: var(--replace); }
+.line-hunk { color: var(--hunk); }
+.line ins, .line del { text-decoration: none; border-radius: 2px; }
+.line ins { background: var(--add-word-bg); }
+.line del { background: var(--destroy-word-bg); }
+.hidden { display: none; }
+{{- if .Accessible}}
+.line ins { text-decoration: underline; }
+.line del { text-decoration: line-through; }
+a:focus-visible, button:focus-visible, input:focus-visible, summary:focus-visible { outline: 3px solid var(--fg); outline-offset: 2px; }
+{{- end}}
+@media print {
+  .toolbar { display: none; }
+  .resource { break-inside: avoid; }
+  .resource pre { white-space: pre-wrap; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if not .Print}}
+<div class="toolbar"{{if .Accessible}} role="search"{{end}}>
+<input type="search" id="search" placeholder="{{.Messages.search}}" aria-label="{{.Messages.search}}">
+{{- range .Actions}}
+<label><input type="checkbox" class="filter" value="{{.}}" checked> {{index $.Messages .}}</label>
+{{- end}}
+<button type="button" id="expand">{{.Messages.expand_all}}</button>
+<button type="button" id="collapse">{{.Messages.collapse_all}}</button>
+<span id="count"{{if .Accessible}} aria-live="polite"{{end}}></span>
+</div>
+{{- end}}
+<div id="resources">
+{{- range $r := .Resources}}
+<details class="resource" data-action="{{.Action}}"{{if $.Print}} open{{end}}>
+<summary><span class="action action-{{.Action}}">{{.Label}}</span> {{.Header}}{{with .DocsURL}} <a class="docs" href="{{.}}">{{$r.Type}}</a>{{end}}</summary>
+<pre>{{.Body}}</pre>
+</details>
+{{- end}}
+</div>
+{{- if not .Print}}
+<script>
+(function () {
+  var search = document.getElementById("search");
+  var filters = document.querySelectorAll(".filter");
+  var resources = document.querySelectorAll(".resource");
+  var count = document.getElementById("count");
+  function update() {
+    var query = search.value.toLowerCase();
+    var actions = {};
+    filters.forEach(function (f) { actions[f.value] = f.checked; });
+    var shown = 0;
+    resources.forEach(function (r) {
+      var visible = actions[r.dataset.action] && r.textContent.toLowerCase().indexOf(query) >= 0;
+      r.classList.toggle("hidden", !visible);
+      if (visible) { shown++; }
+    });
+    count.textContent = shown + " / " + resources.length + " {{.Messages.resources}}";
+  }
+  function toggleAll(open) {
+    resources.forEach(function (r) { if (!r.classList.contains("hidden")) { r.open = open; } });
+  }
+  search.addEventListener("input", update);
+  filters.forEach(function (f) { f.addEventListener("change", update); });
+  document.getElementById("expand").addEventListener("click", function () { toggleAll(true); });
+  document.getElementById("collapse").addEventListener("click", function () { toggleAll(false); });
+  update();
+})();
+</script>
+{{- end}}
+</body>
+</html>
+`
+
+type htmlResource struct {
+	Action string
+	// Label is the label of the action, its text marker if Accessible.
+	Label   string
+	Header  string
+	Type    string
+	DocsURL string
+	Body    template.HTML
+}
+
+// renderHTML writes a single-file HTML report with client-side search, filtering by action and expanding/collapsing all.
+func (plan *PlanData) renderHTML(w io.Writer) error {
+	t, err := template.New("html").Parse(htmlTemplateBody)
+	if err == nil {
+		_, err = t.Parse(htmlColorsTemplateBody)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid template text: %w", err)
+	}
+	title, err := plan.headingText()
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Title      string
+		Lang       string
+		Theme      string
+		Print      bool
+		Accessible bool
+		Messages   map[string]string
+		Actions    []string
+		Resources  []htmlResource
+	}{Title: title, Lang: plan.options.Lang, Theme: plan.options.Theme, Print: plan.options.Profile == ProfilePrint,
+		Accessible: plan.options.Accessible, Messages: map[string]string{}}
+	for _, id := range []string{"add", "change", "destroy", "replace", "moved", "search", "expand_all", "collapse_all", "resources"} {
+		data.Messages[id] = plan.options.message(id)
+	}
+	seen := map[string]bool{}
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return err
+		}
+		resource := htmlResource{Action: r.Action(), Label: plan.options.message(r.Action()), Header: r.Header(), Type: r.ResourceChange.Type, Body: htmlDiffBody(body)}
+		if marker := r.Marker(); marker != "" {
+			// The marker is the label instead of repeating it in the header.
+			resource.Label, resource.Header = marker, strings.TrimPrefix(resource.Header, marker+" ")
+		}
+		if plan.options.RegistryLinks {
+			resource.DocsURL = r.DocsURL()
+		}
+		data.Resources = append(data.Resources, resource)
+		seen[r.Action()] = true
+	}
+	for _, a := range []string{"add", "change", "destroy", "replace", "moved"} {
+		if seen[a] {
+			data.Actions = append(data.Actions, a)
+		}
+	}
+
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// htmlDiffBody returns the lines of the changes of a resource in spans of their kinds, e.g. "line line-add" for added
+// lines. The changed parts of runs of removed lines followed by as many added lines are highlighted with del and ins.
+func htmlDiffBody(text string) template.HTML {
+	lines := strings.SplitAfter(text, "\n")
+	var b strings.Builder
+	for i := 0; i < len(lines); {
+		removed := i
+		for removed < len(lines) && htmlLineKind(lines[removed]) == "destroy" {
+			removed++
+		}
+		added := removed
+		for added < len(lines) && htmlLineKind(lines[added]) == "add" {
+			added++
+		}
+		if removed == i {
+			writeHTMLLine(&b, lines[i], -1, -1, "")
+			i++
+			continue
+		}
+		n := removed - i
+		for j := i; j < added; j++ {
+			switch {
+			case added-removed != n:
+				writeHTMLLine(&b, lines[j], -1, -1, "")
+			case j < removed:
+				start, oldEnd, _ := changedRange(lines[j], lines[j+n])
+				writeHTMLLine(&b, lines[j], start, oldEnd, "del")
+			default:
+				start, _, newEnd := changedRange(lines[j-n], lines[j])
+				writeHTMLLine(&b, lines[j], start, newEnd, "ins")
+			}
+		}
+		i = added
+	}
+	return template.HTML(b.String())
+}
+
+// htmlLineKind returns the kind of a line of changes by its marker, or "" for unchanged lines.
+func htmlLineKind(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return "hunk"
+	case strings.HasPrefix(line, "-/+"), strings.HasPrefix(line, "+/-"):
+		return "replace"
+	case strings.HasPrefix(line, "+"):
+		return "add"
+	case strings.HasPrefix(line, "-"):
+		return "destroy"
+	case strings.HasPrefix(line, "~"):
+		return "change"
+	}
+	return ""
+}
+
+// changedRange returns the byte offsets of the changed part of a removed line and the added line replacing it,
+// between their common prefix after the markers and their common suffix. The start is -1 if they have nothing
+// in common, which is not worth highlighting.
+func changedRange(oldLine, newLine string) (start, oldEnd, newEnd int) {
+	oldLine, newLine = strings.TrimSuffix(oldLine, "\n"), strings.TrimSuffix(newLine, "\n")
+	start = 1
+	for start < len(oldLine) && start < len(newLine) && oldLine[start] == newLine[start] {
+		start++
+	}
+	for start > 1 && !utf8.RuneStart(oldLine[min(start, len(oldLine)-1)]) {
+		start--
+	}
+	oldEnd, newEnd = len(oldLine), len(newLine)
+	for oldEnd > start && newEnd > start && oldLine[oldEnd-1] == newLine[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+	for oldEnd < len(oldLine) && !utf8.RuneStart(oldLine[oldEnd]) {
+		oldEnd++
+		newEnd++
+	}
+	if start == 1 && oldEnd == len(oldLine) {
+		return -1, -1, -1
+	}
+	return start, oldEnd, newEnd
+}
+
+// writeHTMLLine writes a line of changes in a span of its kind, wrapping the bytes from start to end in tag.
+func writeHTMLLine(b *strings.Builder, line string, start, end int, tag string) {
+	content := strings.TrimSuffix(line, "\n")
+	kind := htmlLineKind(content)
+	if kind == "" {
+		b.WriteString(template.HTMLEscapeString(line))
+		return
+	}
+	b.WriteString(`<span class="line line-` + kind + `">`)
+	if start >= 0 && start < end {
+		b.WriteString(template.HTMLEscapeString(content[:start]) + "<" + tag + ">" + template.HTMLEscapeString(content[start:end]) +
+			"</" + tag + ">" + template.HTMLEscapeString(content[end:]))
+	} else {
+		b.WriteString(template.HTMLEscapeString(content))
+	}
+	b.WriteString("</span>" + line[len(content):])
+}
@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+type jsonPatchResource struct {
+	Address         string               `json:"address"`
+	PreviousAddress string               `json:"previous_address,omitempty"`
+	Actions         []string             `json:"actions"`
+	Patch           []jsonPatchOperation `json:"patch"`
+}
+
+// jsonPatchOperation is an operation of RFC 6902. Value is kept even if null.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// renderJSONPatch writes a JSON array of the resource changes, each with a JSON Patch (RFC 6902)
+// which transforms the values before the change into the values after it.
+// Created and destroyed resources are patched as a whole, from and to null.
+func (plan *PlanData) renderJSONPatch(w io.Writer) error {
+	resources := make([]jsonPatchResource, 0, len(plan.ResourceChanges))
+	for _, r := range plan.ResourceChanges {
+		rc := r.ResourceChange
+		actions := make([]string, len(rc.Change.Actions))
+		for i, a := range rc.Change.Actions {
+			actions[i] = string(a)
+		}
+		patch, err := jsonPatch("", rc.Change.Before, rc.Change.After)
+		if err != nil {
+			return fmt.Errorf("failed to create patch of %s: %w", rc.Address, err)
+		}
+		resources = append(resources, jsonPatchResource{
+			Address:         rc.Address,
+			PreviousAddress: rc.PreviousAddress,
+			Actions:         actions,
+			Patch:           patch,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(resources); err != nil {
+		return fmt.Errorf("failed to write jsonpatch: %w", err)
+	}
+	return nil
+}
+
+// jsonPatch returns the operations transforming before into after at the JSON pointer path.
+// Objects are patched by key and lists of the same length by index; other values are replaced.
+func jsonPatch(path string, before, after any) ([]jsonPatchOperation, error) {
+	if reflect.DeepEqual(before, after) {
+		return []jsonPatchOperation{}, nil
+	}
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for k := range beforeMap {
+			keys = append(keys, k)
+		}
+		for k := range afterMap {
+			if _, ok := beforeMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		ops := []jsonPatchOperation{}
+		for _, k := range keys {
+			p := path + "/" + jsonPointerEscaper.Replace(k)
+			bv, inBefore := beforeMap[k]
+			av, inAfter := afterMap[k]
+			switch {
+			case !inAfter:
+				ops = append(ops, jsonPatchOperation{Op: "remove", Path: p})
+			case !inBefore:
+				op, err := newJSONPatchOperation("add", p, av)
+				if err != nil {
+					return nil, err
+				}
+				ops = append(ops, op)
+			default:
+				nested, err := jsonPatch(p, bv, av)
+				if err != nil {
+					return nil, err
+				}
+				ops = append(ops, nested...)
+			}
+		}
+		return ops, nil
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		ops := []jsonPatchOperation{}
+		for i := range beforeList {
+			nested, err := jsonPatch(fmt.Sprintf("%s/%d", path, i), beforeList[i], afterList[i])
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, nested...)
+		}
+		return ops, nil
+	}
+	op, err := newJSONPatchOperation("replace", path, after)
+	if err != nil {
+		return nil, err
+	}
+	return []jsonPatchOperation{op}, nil
+}
+
+func newJSONPatchOperation(op, path string, value any) (jsonPatchOperation, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return jsonPatchOperation{}, err
+	}
+	return jsonPatchOperation{Op: op, Path: path, Value: b}, nil
+}
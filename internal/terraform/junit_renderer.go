@@ -0,0 +1,85 @@
+package terraform
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	SystemOut *junitOutput   `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnit writes one test case per resource change with its diff as the output.
+// Findings of error level fail the test case, and findings of other levels are noted in the output.
+func (plan *PlanData) renderJUnit(w io.Writer) error {
+	findings := findingsByChange(plan.Findings())
+
+	suite := junitTestSuite{Name: "terraform plan"}
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return err
+		}
+		var out strings.Builder
+		tc := junitTestCase{Name: r.Address(), ClassName: r.ResourceChange.Type}
+		for _, f := range findings[changeKeyOf(r)] {
+			if f.Rule.Level == LevelError {
+				tc.Failures = append(tc.Failures, junitFailure{Message: f.Message, Type: f.Rule.ID, Text: f.Rule.Description})
+				continue
+			}
+			out.WriteString(fmt.Sprintf("%s: %s\n", f.Rule.Level, f.Message))
+		}
+		out.WriteString(fmt.Sprintf("# %s\n%s", r.Header(), body))
+		tc.SystemOut = &junitOutput{Text: out.String()}
+
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+		if len(tc.Failures) > 0 {
+			suite.Failures++
+		}
+	}
+	suites := junitTestSuites{Name: toolName, Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write junit: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return fmt.Errorf("failed to write junit: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write junit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package terraform
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"text/template"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// publicRegistry is the host of providers documented on registry.terraform.io.
+const publicRegistry = "registry.terraform.io"
+
+// registryDocsURL returns the documentation page of the type of a resource on the public registry,
+// e.g. https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/instance for aws_instance.
+// It returns "" for providers of other registries, whose documentation cannot be located.
+func registryDocsURL(rc *tfjson.ResourceChange) string {
+	parts := strings.Split(rc.ProviderName, "/")
+	if len(parts) != 3 || parts[0] != publicRegistry {
+		return ""
+	}
+	// Pages are named after the type without the prefix of the provider.
+	_, name, ok := strings.Cut(rc.Type, "_")
+	if !ok {
+		return ""
+	}
+	kind := "resources"
+	if rc.Mode == tfjson.DataResourceMode {
+		kind = "data-sources"
+	}
+	return "https://" + publicRegistry + "/providers/" + parts[1] + "/" + parts[2] + "/latest/docs/" + kind + "/" + name
+}
+
+// moduleSourceURL returns the page of the source of a module call at the pinned version:
+// the registry page of public registry modules, or the tree of git repositories on GitHub and GitLab.
+// It returns "" for local and other sources.
+func moduleSourceURL(call *tfjson.ModuleCall) string {
+	source := call.Source
+	if isLocalSource(source) {
+		return ""
+	}
+	if strings.HasPrefix(source, publicRegistry+"/") {
+		source = strings.TrimPrefix(source, publicRegistry+"/")
+	}
+	if parts := strings.Split(source, "/"); len(parts) == 3 && !strings.ContainsAny(source, ":.?") {
+		version := "latest"
+		if v := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(call.VersionConstraint), "=")); isExactVersion(v) {
+			version = v
+		}
+		return "https://" + publicRegistry + "/modules/" + source + "/" + version
+	}
+	return gitSourceURL(source)
+}
+
+// gitSourceURL returns the URL of the tree of a git source like git::https://github.com/org/repo.git//dir?ref=v1.0.0.
+func gitSourceURL(source string) string {
+	source = strings.TrimPrefix(source, "git::")
+	source, query, _ := strings.Cut(source, "?")
+	ref := ""
+	for _, param := range strings.Split(query, "&") {
+		if v, ok := strings.CutPrefix(param, "ref="); ok {
+			ref = v
+		}
+	}
+	switch {
+	case strings.HasPrefix(source, "git@"):
+		// git@github.com:org/repo.git
+		source = "https://" + strings.Replace(strings.TrimPrefix(source, "git@"), ":", "/", 1)
+	case strings.HasPrefix(source, "ssh://git@"):
+		source = "https://" + strings.TrimPrefix(source, "ssh://git@")
+	case strings.HasPrefix(source, "github.com/"), strings.HasPrefix(source, "gitlab.com/"):
+		source = "https://" + source
+	}
+	rest, ok := strings.CutPrefix(source, "https://")
+	if !ok {
+		return ""
+	}
+	// The subdirectory follows the repository after "//".
+	repo, dir, _ := strings.Cut(rest, "//")
+	repo = strings.TrimSuffix(repo, ".git")
+	host, _, _ := strings.Cut(repo, "/")
+	if ref == "" && dir == "" {
+		return "https://" + repo
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	switch host {
+	case "github.com":
+		return "https://" + repo + "/tree/" + ref + "/" + dir
+	case "gitlab.com":
+		return "https://" + repo + "/-/tree/" + ref + "/" + dir
+	}
+	return "https://" + repo
+}
+
+// isExactVersion reports whether a version constraint pins a single version, like "1.2.3".
+func isExactVersion(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if (r < '0' || r > '9') && r != '.' && r != '-' && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return v[0] >= '0' && v[0] <= '9'
+}
+
+// moduleLink is a module of changed resources with the page of its source.
+type moduleLink struct {
+	Address string
+	URL     string
+}
+
+// moduleLinks returns the modules of changed resources whose sources have pages, in the order of the changes.
+func (plan *PlanData) moduleLinks() []moduleLink {
+	var links []moduleLink
+	seen := map[string]bool{}
+	for _, r := range plan.ResourceChanges {
+		address := r.ResourceChange.ModuleAddress
+		calls := moduleCalls(plan.config, address)
+		// Nested modules are linked with their ancestors, e.g. module.a and module.a.module.b.
+		steps := moduleStepRegexp.FindAllString(address, -1)
+		for i, call := range calls {
+			a := strings.Join(steps[:i+1], ".")
+			if seen[a] {
+				continue
+			}
+			seen[a] = true
+			if u := moduleSourceURL(call); u != "" {
+				links = append(links, moduleLink{Address: a, URL: u})
+			}
+		}
+	}
+	return links
+}
+
+// LinkTemplate links addresses of resources matching Pattern to the URL of Template.
+type LinkTemplate struct {
+	// Pattern is a glob of the type of resources, e.g. "aws_instance" or "aws_*",
+	// or of the module address if it starts with "module.", e.g. "module.network*".
+	Pattern string
+	// Template is a text/template of the URL executed with linkData,
+	// e.g. "https://cmdb.example.com/hosts?name={{.Values.tags.Name}}".
+	Template string
+}
+
+// linkData is the resource change given to link templates.
+type linkData struct {
+	Address       string
+	Type          string
+	Name          string
+	ModuleAddress string
+	ProviderName  string
+	// Values are the attributes after the change, or before it if the resource is destroyed.
+	Values map[string]any
+}
+
+func (l LinkTemplate) validate() error {
+	if _, err := path.Match(l.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern of link template %q: %w", l.Pattern, err)
+	}
+	if _, err := template.New("link").Parse(l.Template); err != nil {
+		return fmt.Errorf("invalid link template %q: %w", l.Template, err)
+	}
+	return nil
+}
+
+// matches reports whether the pattern matches the type or the module of rc.
+func (l LinkTemplate) matches(rc *tfjson.ResourceChange) bool {
+	subject := rc.Type
+	if strings.HasPrefix(l.Pattern, "module.") {
+		subject = rc.ModuleAddress
+	}
+	ok, _ := path.Match(l.Pattern, subject)
+	return ok
+}
+
+// addressURL returns the URL of the first link template matching the resource of address, or "" if none matches.
+// Templates failing to execute, e.g. by missing values, are skipped.
+func (plan *PlanData) addressURL(address string) string {
+	if len(plan.options.LinkTemplates) == 0 {
+		return ""
+	}
+	for _, r := range plan.ResourceChanges {
+		if r.Address() != address {
+			continue
+		}
+		rc := r.ResourceChange
+		values, ok := rc.Change.After.(map[string]any)
+		if !ok {
+			values, _ = rc.Change.Before.(map[string]any)
+		}
+		data := linkData{Address: rc.Address, Type: rc.Type, Name: rc.Name, ModuleAddress: rc.ModuleAddress, ProviderName: rc.ProviderName, Values: values}
+		for _, l := range plan.options.LinkTemplates {
+			if !l.matches(rc) {
+				continue
+			}
+			t, err := template.New("link").Option("missingkey=error").Parse(l.Template)
+			if err != nil {
+				continue
+			}
+			var b strings.Builder
+			if err := t.Execute(&b, data); err != nil {
+				slog.Debug("cannot execute link template", "address", address, "pattern", l.Pattern, "error", err)
+				continue
+			}
+			return b.String()
+		}
+		break
+	}
+	return ""
+}
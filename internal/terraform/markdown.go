@@ -0,0 +1,37 @@
+package terraform
+
+import (
+	"strings"
+)
+
+// codeSpan quotes s as a markdown code span so that it is rendered literally.
+// The delimiter is made longer than any run of backticks within s.
+func codeSpan(s string) string {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	delimiter := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return delimiter + s + delimiter
+}
+
+// tableCell escapes s so that it can be placed in a markdown table cell.
+// Pipes must be escaped even within code spans.
+func tableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// markdownLink returns a markdown link of text to url. Parentheses and spaces of url are escaped.
+func markdownLink(text, url string) string {
+	return "[" + text + "](" + strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(url) + ")"
+}
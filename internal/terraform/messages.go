@@ -0,0 +1,309 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Languages of generated text
+const (
+	LangEnglish  = "en"
+	LangJapanese = "ja"
+)
+
+var langs = []string{LangEnglish, LangJapanese}
+
+// catalogs are the human-readable strings of reports for each language, by message ID.
+// The IDs of actions are the labels of the summary, and the IDs of action reasons are terraform's
+// (https://developer.hashicorp.com/terraform/internals/json-format#change-representation).
+// Messages missing in a language fall back to English.
+var catalogs = map[string]map[string]string{
+	LangEnglish: {
+		"heading":                        DefaultHeadingFormat,
+		"drift_heading":                  "{{len .UpdatedAddresses}} changed, {{len .DeletedAddresses}} deleted outside of Terraform.",
+		"add":                            "add",
+		"change":                         "change",
+		"destroy":                        "destroy",
+		"replace":                        "replace",
+		"moved":                          "moved",
+		"marker_add":                     "[ADD]",
+		"marker_change":                  "[CHANGE]",
+		"marker_destroy":                 "[DESTROY]",
+		"marker_replace":                 "[REPLACE]",
+		"marker_moved":                   "[MOVED]",
+		"marker_warning":                 "[WARNING]",
+		"marker_error":                   "[ERROR]",
+		"severity_label":                 "severity: %s",
+		"module":                         "module",
+		"modules":                        "modules",
+		"provider":                       "provider",
+		"root_module":                    "root module",
+		"created":                        "will be created",
+		"updated":                        "will be updated in-place",
+		"destroyed":                      "will be destroyed",
+		"replaced":                       "will be replaced",
+		"replaced_create_before_destroy": "will be replaced (new before old)",
+		"replaced_destroy_before_create": "will be replaced (old destroyed first)",
+		"replace_create_before_destroy":  "+/- replace (new before old)",
+		"replace_destroy_before_create":  "-/+ replace (old destroyed first)",
+		"has_moved":                      "%s has moved to %s",
+		"moved_from":                     "moved from %s",
+		"imported":                       "imported with ID %s",
+		"imported_updated":               "imported with ID %s, then updated",
+		"drift":                          "planned due to drift in %s",
+		"drift_updated":                  "has changed outside of Terraform",
+		"drift_deleted":                  "has been deleted outside of Terraform",
+		"drift_change":                   "changed",
+		"drift_destroy":                  "deleted",
+		"conditions":                     "Conditions",
+		"condition_failed":               "condition failed",
+		"condition_unknown":              "condition unknown until apply",
+		"computed_only":                  "only computed attributes change: %s",
+		"tag_only":                       "tag-only change",
+		"tag_only_updates":               "Tag-only updates",
+		"forces_replacement":             "forces replacement",
+		"hidden_attribute":               "(%d unchanged attribute hidden)",
+		"hidden_attributes":              "(%d unchanged attributes hidden)",
+		"hidden_element":                 "(%d unchanged element hidden)",
+		"hidden_elements":                "(%d unchanged elements hidden)",
+		"secrets":                        "⚠ possible secrets detected in plan output",
+		"checklist":                      "Review checklist",
+		"checklist_destroy":              "confirmed destroy of %s is intended",
+		"checklist_replace":              "confirmed replacement of %s is intended",
+		"thresholds":                     "⚠ plan exceeds the limits of changes",
+		"threshold_destroy":              "resources to destroy",
+		"threshold_replace":              "resources to replace",
+		"threshold_changes":              "changes in total",
+		"type_violations":                "⛔ changes of resource types which must not change",
+		"review_required":                "⚠ review required",
+		"stale_plan":                     "⚠ plan generated %s ago — consider re-planning",
+		"age_minutes":                    "%d minutes",
+		"age_hour":                       "1 hour",
+		"age_hours":                      "%d hours",
+		"age_day":                        "1 day",
+		"age_days":                       "%d days",
+		"destructive_changes":            "⚠ Destructive changes",
+		"change_details":                 "Change details",
+		"document_before":                "Before",
+		"document_after":                 "After",
+		"unchanged_lines":                "… %d unchanged lines …",
+		"diff_stats":                     "+%d / -%d lines",
+		"outputs_only":                   "Output changes only",
+		"workspace":                      "Workspace",
+		"backend":                        "Backend",
+		"inventory_heading":              "%d resources in %d modules",
+		"terraform_version":              "Terraform %s",
+		"resource_type":                  "Type",
+		"resource_count":                 "Count",
+		"address":                        "Address",
+		"state_diff_heading":             "%d added, %d removed, %d changed.",
+		"state_added":                    "added",
+		"state_removed":                  "removed",
+		"state_changed":                  "changed",
+		"state_resource_added":           "was added",
+		"state_resource_removed":         "was removed",
+		"state_resource_changed":         "was changed",
+		"sensitive_only":                 "only sensitive values changed",
+		"output":                         "Output",
+		"action":                         "Action",
+		"diff_truncated":                 "… %d more lines truncated; run terraform show locally for the full diff",
+		"details_omitted":                "Details of %d resources are omitted over the limit of %d.",
+		"full_report":                    "Full report",
+		"show_details":                   "Show details",
+		"search":                         "Search addresses and diffs",
+		"expand_all":                     "Expand all",
+		"collapse_all":                   "Collapse all",
+		"resources":                      "resources",
+
+		"replace_because_tainted":           "tainted",
+		"replace_because_cannot_update":     "cannot update in-place",
+		"replace_by_request":                "replacement requested",
+		"replace_by_triggers":               "replace_triggered_by",
+		"delete_because_no_resource_config": "no resource configuration",
+		"delete_because_no_module":          "module removed from configuration",
+		"delete_because_wrong_repetition":   "repetition mode changed",
+		"delete_because_count_index":        "count index out of range",
+		"delete_because_each_key":           "key not in for_each",
+		"delete_because_no_move_target":     "move target does not exist",
+		"read_because_config_unknown":       "configuration unknown until apply",
+		"read_because_dependency_pending":   "dependency has pending changes",
+	},
+	LangJapanese: {
+		"heading":                        `追加 {{len .CreatedAddresses}} 件、変更 {{len .UpdatedAddresses}} 件、削除 {{len .DeletedAddresses}} 件、置換 {{len .ReplacedAddresses}} 件`,
+		"drift_heading":                  "Terraform の外部で変更 {{len .UpdatedAddresses}} 件、削除 {{len .DeletedAddresses}} 件",
+		"add":                            "追加",
+		"change":                         "変更",
+		"destroy":                        "削除",
+		"replace":                        "置換",
+		"moved":                          "移動",
+		"marker_add":                     "[追加]",
+		"marker_change":                  "[変更]",
+		"marker_destroy":                 "[削除]",
+		"marker_replace":                 "[置換]",
+		"marker_moved":                   "[移動]",
+		"marker_warning":                 "[警告]",
+		"marker_error":                   "[エラー]",
+		"severity_label":                 "重大度: %s",
+		"module":                         "モジュール",
+		"modules":                        "モジュール",
+		"provider":                       "プロバイダ",
+		"root_module":                    "ルートモジュール",
+		"created":                        "は作成されます",
+		"updated":                        "はその場で更新されます",
+		"destroyed":                      "は削除されます",
+		"replaced":                       "は置換されます",
+		"replaced_create_before_destroy": "は置換されます (新規作成が先)",
+		"replaced_destroy_before_create": "は置換されます (削除が先)",
+		"replace_create_before_destroy":  "+/- 置換 (新規作成が先)",
+		"replace_destroy_before_create":  "-/+ 置換 (削除が先)",
+		"has_moved":                      "%s は %s に移動しました",
+		"moved_from":                     "%s から移動",
+		"imported":                       "ID %s からインポート",
+		"imported_updated":               "ID %s からインポートした後に更新",
+		"drift":                          "%s のドリフトによる変更",
+		"drift_updated":                  "は Terraform の外部で変更されました",
+		"drift_deleted":                  "は Terraform の外部で削除されました",
+		"drift_change":                   "変更",
+		"drift_destroy":                  "削除",
+		"conditions":                     "条件",
+		"condition_failed":               "条件を満たしていない",
+		"condition_unknown":              "条件は apply まで不明",
+		"computed_only":                  "computed な属性が変わるだけ: %s",
+		"tag_only":                       "タグのみの変更",
+		"tag_only_updates":               "タグのみの更新",
+		"forces_replacement":             "置換の原因",
+		"hidden_attribute":               "(変更のない%d個の属性を省略)",
+		"hidden_attributes":              "(変更のない%d個の属性を省略)",
+		"hidden_element":                 "(変更のない%d個の要素を省略)",
+		"hidden_elements":                "(変更のない%d個の要素を省略)",
+		"secrets":                        "⚠ プランの出力に秘密情報と思われる値があります",
+		"checklist":                      "レビューのチェックリスト",
+		"checklist_destroy":              "%s の削除が意図したものであることを確認した",
+		"checklist_replace":              "%s の置換が意図したものであることを確認した",
+		"thresholds":                     "⚠ プランの変更数が上限を超えています",
+		"threshold_destroy":              "削除するリソース",
+		"threshold_replace":              "置換するリソース",
+		"threshold_changes":              "変更の合計",
+		"type_violations":                "⛔ 変更してはいけないリソースタイプの変更",
+		"review_required":                "⚠ 要レビュー",
+		"stale_plan":                     "⚠ %s前に作成されたプランです。再度 plan することを検討してください",
+		"age_minutes":                    "%d 分",
+		"age_hour":                       "1 時間",
+		"age_hours":                      "%d 時間",
+		"age_day":                        "1 日",
+		"age_days":                       "%d 日",
+		"destructive_changes":            "⚠ 破壊的な変更",
+		"change_details":                 "変更の詳細",
+		"document_before":                "変更前",
+		"document_after":                 "変更後",
+		"unchanged_lines":                "… 変更のない %d 行 …",
+		"diff_stats":                     "+%d / -%d 行",
+		"outputs_only":                   "出力値のみの変更",
+		"workspace":                      "ワークスペース",
+		"backend":                        "バックエンド",
+		"inventory_heading":              "%d 個のリソース (%d モジュール)",
+		"terraform_version":              "Terraform %s",
+		"resource_type":                  "タイプ",
+		"resource_count":                 "数",
+		"address":                        "アドレス",
+		"state_diff_heading":             "%d 個追加、%d 個削除、%d 個変更。",
+		"state_added":                    "追加",
+		"state_removed":                  "削除",
+		"state_changed":                  "変更",
+		"state_resource_added":           "が追加されました",
+		"state_resource_removed":         "が削除されました",
+		"state_resource_changed":         "が変更されました",
+		"sensitive_only":                 "機密値のみが変更されました",
+		"output":                         "出力値",
+		"action":                         "アクション",
+		"diff_truncated":                 "… 残りの %d 行は省略しました。完全な差分はローカルで terraform show を実行して確認してください",
+		"details_omitted":                "%d 件のリソースの詳細は上限 %d 件を超えるため省略しました。",
+		"full_report":                    "完全なレポート",
+		"show_details":                   "詳細を表示",
+		"search":                         "アドレスと差分を検索",
+		"expand_all":                     "すべて展開",
+		"collapse_all":                   "すべて折りたたむ",
+		"resources":                      "リソース",
+
+		"replace_because_tainted":           "tainted",
+		"replace_because_cannot_update":     "その場で更新できない",
+		"replace_by_request":                "置換が要求された",
+		"replace_by_triggers":               "replace_triggered_by",
+		"delete_because_no_resource_config": "リソースの設定がない",
+		"delete_because_no_module":          "モジュールが設定から削除された",
+		"delete_because_wrong_repetition":   "繰り返しの方式が変わった",
+		"delete_because_count_index":        "count のインデックスが範囲外",
+		"delete_because_each_key":           "キーが for_each にない",
+		"delete_because_no_move_target":     "移動先が存在しない",
+		"read_because_config_unknown":       "設定が apply まで不明",
+		"read_because_dependency_pending":   "依存先に保留中の変更がある",
+	},
+}
+
+// message returns the text of the message ID: the override of the options, or the text in the language of the options.
+// id itself is returned if unknown.
+func (o Options) message(id string) string {
+	s := id
+	if text, ok := o.Messages[id]; ok {
+		s = text
+	} else if text, ok := catalogs[o.Lang][id]; ok {
+		s = text
+	} else if text, ok := catalogs[LangEnglish][id]; ok {
+		s = text
+	}
+	if o.Accessible {
+		for symbol, marker := range accessibleSymbols {
+			if rest, ok := strings.CutPrefix(s, symbol+" "); ok {
+				return o.message(marker) + " " + rest
+			}
+		}
+	}
+	return s
+}
+
+// accessibleSymbols map the symbols starting messages to the IDs of the text markers replacing them if Accessible,
+// as screen readers announce the names of symbols, e.g. "warning sign".
+var accessibleSymbols = map[string]string{
+	"⚠": "marker_warning",
+	"⛔": "marker_error",
+}
+
+// validateMessages reports whether the overrides are of known messages with the same verbs as the originals.
+func validateMessages(overrides map[string]string) error {
+	for id, text := range overrides {
+		original, ok := catalogs[LangEnglish][id]
+		if !ok {
+			return fmt.Errorf("unknown message %q", id)
+		}
+		if id == "heading" || id == "drift_heading" {
+			if _, err := template.New("heading").Funcs(textFuncs).Parse(text); err != nil {
+				return fmt.Errorf("invalid message %q: %w", id, err)
+			}
+			continue
+		}
+		// Messages with %s are formats of addresses.
+		if verbs := strings.Count(original, "%s"); verbs > 0 && (strings.Count(text, "%s") != verbs || strings.Count(text, "%") != verbs) {
+			return fmt.Errorf("message %q must have %d %%s and no other %%: %q", id, verbs, text)
+		}
+		// Messages with %d are formats of counts.
+		if verbs := strings.Count(original, "%d"); verbs > 0 && (strings.Count(text, "%d") != verbs || strings.Count(text, "%") != verbs) {
+			return fmt.Errorf("message %q must have %d %%d and no other %%: %q", id, verbs, text)
+		}
+	}
+	return nil
+}
+
+// Message returns the text of the message ID for the plan's options,
+// for integrations rendering their own documents, e.g. Message("change_details").
+func (plan *PlanData) Message(id string) string {
+	return plan.options.message(id)
+}
+
+// capitalize upper-cases the first letter of s, for labels of languages with cases.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,56 @@
+package terraform
+
+import (
+	"regexp"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+var moduleStepRegexp = regexp.MustCompile(`module\.([^.\[]+)(\[(?:"(?:[^"\\]|\\.)*"|[0-9]+)\])?`)
+
+// moduleCallNames returns the names of module calls in a module address,
+// e.g. ["a", "b"] for `module.a[0].module.b["x"]`.
+func moduleCallNames(moduleAddress string) []string {
+	var names []string
+	for _, m := range moduleStepRegexp.FindAllStringSubmatch(moduleAddress, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// truncateModuleAddress returns the first depth module calls of a module address,
+// e.g. "module.a" for `module.a.module.b` and depth 1. The address is returned as is if depth is 0.
+func truncateModuleAddress(moduleAddress string, depth int) string {
+	steps := moduleStepRegexp.FindAllString(moduleAddress, -1)
+	if depth == 0 || len(steps) <= depth {
+		return moduleAddress
+	}
+	return strings.Join(steps[:depth], ".")
+}
+
+// moduleCalls returns the module calls of a module address in the configuration, from the root module.
+// Calls which cannot be found in the configuration are omitted with their descendants.
+func moduleCalls(config *tfjson.Config, moduleAddress string) []*tfjson.ModuleCall {
+	if config == nil || config.RootModule == nil {
+		return nil
+	}
+	var calls []*tfjson.ModuleCall
+	module := config.RootModule
+	for _, name := range moduleCallNames(moduleAddress) {
+		if module == nil {
+			break
+		}
+		call, ok := module.ModuleCalls[name]
+		if !ok {
+			break
+		}
+		calls = append(calls, call)
+		module = call.Module
+	}
+	return calls
+}
+
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
@@ -21,10 +21,12 @@ var importantAttributes = []string{
 
 type MovedBlockRenderer struct {
 	ResourceChange *tfjson.ResourceChange
+	// HeaderFormat is the format of the header with the previous and the current addresses.
+	HeaderFormat string
 }
 
-func NewMovedBlockRenderer(resourceChange *tfjson.ResourceChange) *MovedBlockRenderer {
-	return &MovedBlockRenderer{ResourceChange: resourceChange}
+func NewMovedBlockRenderer(resourceChange *tfjson.ResourceChange, opts Options) *MovedBlockRenderer {
+	return &MovedBlockRenderer{ResourceChange: resourceChange, HeaderFormat: opts.message("has_moved")}
 }
 
 func (r *MovedBlockRenderer) Render() (string, error) {
@@ -41,7 +43,7 @@ func (r *MovedBlockRenderer) Render() (string, error) {
 }
 
 func (r *MovedBlockRenderer) Header() string {
-	return fmt.Sprintf("%s has moved to %s", r.ResourceChange.PreviousAddress, r.ResourceChange.Address)
+	return fmt.Sprintf(r.HeaderFormat, r.ResourceChange.PreviousAddress, r.ResourceChange.Address)
 }
 
 func (r *MovedBlockRenderer) Attributes() string {
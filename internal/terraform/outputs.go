@@ -0,0 +1,80 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-json/sanitize"
+)
+
+// unknownOutputValue is the value of outputs which become unknown.
+const unknownOutputValue = "(known after apply)"
+
+// OutputChange is a change of an output value of the root module.
+type OutputChange struct {
+	Name string
+	// Action is the message ID of the action, "add", "change" or "destroy".
+	Action string
+	// Before and After are the values in JSON, or "" if there is none. Sensitive values are masked.
+	Before string
+	After  string
+}
+
+// outputChanges returns the changed outputs of a plan in the order of their names.
+func outputChanges(changes map[string]*tfjson.Change, opts Options) ([]OutputChange, error) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res, err := compileRedactPatterns(opts.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+	enc := valueEncoder{escapeHTML: opts.EscapeHTML}
+	var outputs []OutputChange
+	for _, name := range names {
+		change := changes[name]
+		var action string
+		switch {
+		case change.Actions.Create():
+			action = "add"
+		case change.Actions.Update():
+			action = "change"
+		case change.Actions.Delete():
+			action = "destroy"
+		default:
+			continue
+		}
+		change, err := sanitize.SanitizeChange(change, sanitize.DefaultSensitiveValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sanitize output %s: %w", name, err)
+		}
+		output := OutputChange{Name: name, Action: action}
+		if change.Before != nil {
+			b, err := enc.marshalScalar(redactValue(change.Before, res))
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s (before): %w", name, err)
+			}
+			output.Before = string(b)
+		}
+		if change.AfterUnknown == true {
+			output.After = unknownOutputValue
+		} else if change.After != nil {
+			b, err := enc.marshalScalar(redactValue(change.After, res))
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s (after): %w", name, err)
+			}
+			output.After = string(b)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// outputsOnly reports whether the plan changes outputs but no resources, which is rendered as a compact report
+// of the outputs instead of an empty summary.
+func (plan *PlanData) outputsOnly() bool {
+	return !plan.options.DriftOnly && len(plan.ResourceChanges) == 0 && len(plan.OutputChanges) > 0
+}
@@ -1,46 +1,264 @@
 package terraform
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-json/sanitize"
 	"github.com/reproio/terraform-j2md/internal/format"
 	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
 	"text/template"
+	"time"
 
 	tfjson "github.com/hashicorp/terraform-json"
 )
 
-const planTemplateBody = `### {{len .CreatedAddresses}} to add, {{len .UpdatedAddresses}} to change, {{len .DeletedAddresses}} to destroy, {{len .ReplacedAddresses}} to replace.
+const DefaultHeadingFormat = `{{len .CreatedAddresses}} to add, {{len .UpdatedAddresses}} to change, {{len .DeletedAddresses}} to destroy, {{len .ReplacedAddresses}} to replace.`
+
+const DefaultHeadingLevel = 3
+
+// Output formats
+const (
+	FormatMarkdown   = "markdown"
+	FormatCSV        = "csv"
+	FormatSARIF      = "sarif"
+	FormatJUnit      = "junit"
+	FormatTAP        = "tap"
+	FormatHTML       = "html"
+	FormatAsciiDoc   = "asciidoc"
+	FormatConfluence = "confluence"
+	FormatTeams      = "teams"
+	FormatTerm       = "term"
+	FormatJSONPatch  = "jsonpatch"
+	FormatChangelog  = "changelog"
+	FormatCommitMsg  = "commit-msg"
+	FormatTitle      = "title"
+	FormatStatus     = "status"
+)
+
+var formats = []string{FormatMarkdown, FormatHTML, FormatAsciiDoc, FormatConfluence, FormatTeams, FormatCSV, FormatSARIF, FormatJUnit, FormatTAP, FormatTerm, FormatJSONPatch, FormatChangelog, FormatCommitMsg, FormatTitle, FormatStatus}
+
+// Output profiles
+const (
+	ProfileScreen = "screen"
+	ProfilePrint  = "print"
+)
+
+// Summary styles
+const (
+	SummaryStyleList  = "list"
+	SummaryStyleTable = "table"
+)
+
+// Detail styles
+const (
+	DetailStyleDiff  = "diff"
+	DetailStylePaths = "paths"
+	DetailStyleFull  = "full"
+	DetailStyleHCL   = "hcl"
+)
+
+const (
+	DefaultCodeFenceChar   = "`"
+	DefaultCodeFenceLength = 8
+	DefaultCodeLanguage    = "diff"
+)
+
+// Named templates of markdown which Options.Templates override
+const (
+	// TemplateSummary is the summary list or table of addresses.
+	TemplateSummary = "summary"
+	// TemplateDetails is the collapsed section of the details of resources.
+	TemplateDetails = "details"
+	// TemplateResource is the details of a resource, executed with a ResourceChangeData.
+	TemplateResource = "resource"
+)
+
+var templateNames = []string{TemplateSummary, TemplateDetails, TemplateResource}
+
+const planTemplateBody = `{{.Heading}}
+{{- with stateContext}}
+
+{{.}}
+{{end}}
+{{- with staleWarning}}
+
+**{{.}}**
+{{end}}
+{{- with .ThresholdViolations}}
+
+**{{message "thresholds"}}**
+{{range .}}
+- {{message .Message}} ({{.Count}} > {{.Limit}})
+{{- end}}
+{{end}}
+{{- with .TypeViolations}}
+
+**{{message "type_violations"}}**
+{{range .}}
+- {{code .Address}}: {{message .Action}}
+{{- end}}
+{{end}}
+{{- with destructiveChanges}}
+
+**{{message "destructive_changes"}}**
+{{range .}}
+{{template "resource" .}}
+{{end}}
+{{- end}}
+{{- if and pieChart .ResourceChanges}}
+
+{{codeFence}}mermaid
+{{.PieChart}}{{codeFence}}
+{{end}}
+{{- template "summary" .}}
+{{- with moduleTotals}}
+
+| {{message "module"}} | {{message "add"}} | {{message "change"}} | {{message "destroy"}} | {{message "replace"}} | {{message "moved"}} |
+| --- | ---: | ---: | ---: | ---: | ---: |
+{{- range .}}
+| {{with .Module}}{{code .}}{{else}}{{message "root_module"}}{{end}} | {{.Add}} | {{.Change}} | {{.Destroy}} | {{.Replace}} | {{.Moved}} |
+{{- end}}
+{{end}}
+{{- with .Conditions}}
+
+**{{message "conditions"}}**
+{{range .}}
+- {{code .Address}}: {{conditionMessage .Status}}{{range .Messages}}
+    - {{.}}{{end}}
+{{- end}}
+{{end}}
+{{- if detailsOmitted}}
+
+{{printf (message "details_omitted") (len .ResourceChanges) maxDetailedResources}}
+{{- end}}
+{{if and dependencyGraph .ResourceChanges}}
+{{codeFence}}mermaid
+{{.DependencyGraph}}{{codeFence}}
+
+{{end -}}
+{{template "details" .}}
+{{- if and checklist (or .DeletedAddresses .ReplacedAddresses)}}
+**{{message "checklist"}}**
+{{range .DeletedAddresses}}
+- [ ] {{printf (message "checklist_destroy") (code .)}}
+{{- end}}
+{{- range .ReplacedAddresses}}
+- [ ] {{printf (message "checklist_replace") (code .)}}
+{{- end}}
+{{end}}
+{{- with .SecretWarnings}}
+**{{message "secrets"}}**
+{{range .}}
+- {{code .Address}}: {{code .Path}} ({{.Kind}})
+{{- end}}
+{{end}}
+{{- with detailsURL}}
+[{{message "full_report"}}]({{.}})
+{{end}}
+{{- define "summary"}}{{- if eq summaryStyle "table"}}{{if .ResourceChanges}}
+| Address | Action | Changed attributes | Reason |
+| --- | --- | --- | --- |
+{{- range .ResourceChanges}}
+| {{tableCell (address .Address)}} | {{.Action}}{{severity .Action}} | {{tableCell (codeList .ChangedAttributes)}} | {{tableCell .Reason}} |
+{{- end}}
+{{end}}
+{{- else if groupBy}}{{range summaryGroups}}
+- {{with .Key}}{{code .}}{{else}}{{message "root_module"}}{{end}}{{range .Groups}}
+    - {{.Label}}{{range .Addresses}}
+        - {{address . -}}
+{{end}}{{end}}{{end}}
+{{- else}}
 {{- if .CreatedAddresses}}
-- add{{ range .CreatedAddresses }}
-    - {{. -}}
+- {{message "add"}}{{severity "add"}}{{ range .CreatedAddresses }}
+    - {{address . -}}
 {{end}}{{end}}
-{{- if .UpdatedAddresses}}
-- change{{ range .UpdatedAddresses }}
-    - {{. -}}
+{{- with updatedAddresses}}
+- {{actionMessage "change"}}{{severity "change"}}{{ range . }}
+    - {{address . -}}
 {{end}}{{end}}
-{{- if .DeletedAddresses}}
-- destroy{{ range .DeletedAddresses }}
-    - {{. -}}
+{{- with tagOnlyAddresses}}
+- {{message "tag_only"}}{{ range . }}
+    - {{address . -}}
 {{end}}{{end}}
-{{- if .ReplacedAddresses}}
-- replace{{ range .ReplacedAddresses }}
-    - {{. -}}
+{{- if .DeletedAddresses}}
+- {{actionMessage "destroy"}}{{severity "destroy"}}{{ range .DeletedAddresses }}
+    - {{address . -}}
 {{end}}{{end}}
+{{- if .ReplacedAddresses}}{{if replaceOrder}}{{range replaceGroups}}
+- {{.Label}}{{range .Addresses}}
+    - {{address . -}}
+{{end}}{{end}}{{else}}
+- {{message "replace"}}{{severity "replace"}}{{ range .ReplacedAddresses }}
+    - {{address . -}}
+{{end}}{{end}}{{end}}
 {{- if .MovedAddresses}}
-- moved{{ range .MovedAddresses }}
+- {{message "moved"}}{{ range .MovedAddresses }}
     - {{. -}}
 {{end}}{{end}}
-{{if .ResourceChanges -}}
-<details><summary>Change details</summary>
-{{ range .ResourceChanges }}
-{{codeFence}}diff
-# {{.Header}}
-{{.Render}}{{codeFence}}
+{{- with moduleLinks}}
+- {{message "modules"}}{{ range . }}
+    - {{link (code .Address) .URL -}}
+{{end}}{{end}}{{end}}{{end}}
+{{- define "details"}}{{with detailChanges -}}
+{{if printProfile -}}
+<div style="page-break-before: always;"></div>
+
+**{{message "change_details"}}**
+{{else -}}
+<details><summary>{{message "change_details"}}</summary>
+{{end -}}
+{{ range . }}
+{{template "resource" .}}
+{{end}}
+{{if not printProfile -}}
+</details>
+{{end}}{{end}}
+{{- with tagOnlyDetails}}
+<details><summary>{{message "tag_only_updates"}}</summary>
+{{ range . }}
+{{template "resource" .}}
 {{end}}
 </details>
-{{end}}`
+{{end}}{{end}}
+{{- define "resource"}}{{with anchor .Address}}<a id="{{.}}"></a>
+
+{{end}}{{with docsLink .}}{{.}}
+{{end -}}
+{{if .IsFullDocuments}}{{template "documents" .}}{{else -}}
+{{codeFence}}{{codeLanguage}}
+# {{.Header}}
+{{.Render}}{{codeFence}}{{end}}
+{{- range embeddedValues .}}
+
+{{code .Path}}:
+{{codeFence}}{{.Language}}
+{{.Text}}
+{{codeFence}}{{end}}{{end}}
+{{- define "outputs"}}{{outputsHeading}}
+
+| {{message "output"}} | {{message "action"}} | {{message "document_before"}} | {{message "document_after"}} |
+| --- | --- | --- | --- |
+{{- range .OutputChanges}}
+| {{code .Name}} | {{message .Action}} | {{with .Before}}{{tableCell (code .)}}{{end}} | {{with .After}}{{tableCell (code .)}}{{end}} |
+{{- end}}
+{{with detailsURL}}
+[{{message "full_report"}}]({{.}})
+{{end}}{{end}}
+{{- define "documents"}}{{code .Header}}
+{{- with .FullDocuments}}{{with .Before}}
+
+{{message "document_before"}}:
+{{codeFence}}json
+{{.}}{{codeFence}}{{end}}{{with .After}}
+
+{{message "document_after"}}:
+{{codeFence}}json
+{{.}}{{codeFence}}{{end}}{{end}}{{end}}`
 
 type PlanData struct {
 	CreatedAddresses  []string
@@ -49,6 +267,338 @@ type PlanData struct {
 	ReplacedAddresses []string
 	MovedAddresses    []string
 	ResourceChanges   []ResourceChangeData
+	// Conditions are the results of conditions which did not pass.
+	Conditions []Condition
+	// SecretWarnings are the values which look like secrets, in the order of resource changes.
+	SecretWarnings []SecretWarning
+	// OutputChanges are the changed outputs of the root module, in the order of their names.
+	OutputChanges []OutputChange
+	// Timestamp is when terraform generated the plan, or zero if the plan has none.
+	Timestamp time.Time
+	options   Options
+	config    *tfjson.Config
+}
+
+// Options controls how a plan is processed and rendered.
+type Options struct {
+	// Format is the output format of Render.
+	Format string
+	// Label identifies the plan among others, e.g. a workspace or a directory like "prod/network", in FormatTitle and FormatStatus.
+	Label string
+	// EscapeHTML escapes <, >, and & in JSON strings.
+	EscapeHTML bool
+	// RawValues renders values exactly as terraform stores them,
+	// without pretty printing embedded JSON or summarizing PEM blocks.
+	RawValues bool
+	// LegacyUnescape restores the old rendering of string values, which replaced
+	// every `\n` and `\"` in the marshaled document regardless of context.
+	LegacyUnescape bool
+	// HeadingLevel is the markdown heading level of the summary line. The line is rendered as plain text if 0.
+	HeadingLevel int
+	// HeadingFormat is a template of the summary line, executed with PlanData. DefaultHeadingFormat is used if empty.
+	HeadingFormat string
+	// CodeFenceChar is the character of code fences, "`" or "~".
+	CodeFenceChar string
+	// CodeFenceLength is the number of characters of code fences.
+	// It should be longer than any fence included in values.
+	CodeFenceLength int
+	// CodeLanguage is the language hint of diff blocks. No hint is rendered if empty.
+	CodeLanguage string
+	// SummaryStyle is how the summary is rendered, SummaryStyleList or SummaryStyleTable.
+	SummaryStyle string
+	// DetailStyle is how the changes of each resource are rendered, DetailStyleDiff (unified diffs of the documents),
+	// DetailStylePaths (changed values listed by attribute path), DetailStyleFull (the whole documents before and after)
+	// or DetailStyleHCL (resource blocks in the style of the output of terraform plan).
+	DetailStyle string
+	// CollapseUnchanged diffs the whole documents and replaces the runs of unchanged lines beyond the context of changes
+	// with "… N unchanged lines …" instead of splitting diffs into hunks, e.g. for reformatted embedded JSON.
+	CollapseUnchanged bool
+	// MaxDiffLines truncates the diffs of resources longer than this many lines with a note of the number of the rest.
+	// Diffs are not truncated if 0.
+	MaxDiffLines int
+	// DiffStats appends the numbers of added and removed lines of the diffs to the headers of resources,
+	// e.g. "(+12 / -4 lines)", to gauge the size of each change before reading it.
+	DiffStats bool
+	// Anchors links the addresses in the summary of markdown to anchors at the details of their resources,
+	// unless they are linked by LinkTemplates.
+	Anchors bool
+	// Workspace, Backend and StateAddress describe the state the plan targets, e.g. "prod", "s3" and
+	// "s3://bucket/key", which are rendered below the heading of markdown. See ReadWorkingDir.
+	Workspace    string
+	Backend      string
+	StateAddress string
+	// FullDocumentTypes are globs of resource types rendered as the whole documents before and after their changes
+	// as DetailStyleFull, whatever DetailStyle is, for auditors of some resources.
+	FullDocumentTypes []string
+	// PieChart renders a mermaid pie chart of action counts below the heading.
+	PieChart bool
+	// DependencyGraph renders a mermaid flowchart of changed resources and their dependencies.
+	DependencyGraph bool
+	// Profile is ProfileScreen, or ProfilePrint for printing to PDF:
+	// no collapsed sections, page-break hints and diffs with full context.
+	Profile string
+	// Theme is the color theme of HTML output, ThemeLight, ThemeDark, ThemeAuto (follows prefers-color-scheme)
+	// or ThemeHighContrast.
+	Theme string
+	// Accessible never conveys actions by colors or emoji alone, for screen readers and accessibility compliance:
+	// headers of resources and nodes of graphs start with text markers like "[ADD]", emoji of messages are replaced
+	// with markers like "[WARNING]", and HTML output announces filtered results and marks changed words by decoration.
+	Accessible bool
+	// Sort is the order of addresses in the summary and of resource changes in the details.
+	// The order terraform emitted them is kept if empty.
+	Sort string
+	// DetailsURL is the URL of the full report, e.g. an uploaded artifact,
+	// linked from the footer of markdown and from summaries like Teams cards.
+	DetailsURL string
+	// Color colors the term format with ANSI escape sequences.
+	Color bool
+	// Lang is the language of generated text, LangEnglish or LangJapanese.
+	Lang string
+	// RegistryLinks links the types of resources in the details to their documentation on registry.terraform.io.
+	RegistryLinks bool
+	// ModuleLinks lists the modules of changed resources in the summary, linked to their sources at the pinned versions.
+	ModuleLinks bool
+	// LinkTemplates link the addresses of resources in the summary, e.g. to an internal CMDB.
+	// The first template matching a resource is used.
+	LinkTemplates []LinkTemplate
+	// ShowProvider appends the provider of each resource, with the alias if any, to the headers of the details.
+	ShowProvider bool
+	// GroupBy is the grouping of the summary list in addition to actions, GroupByAction (none), GroupByProvider or GroupByModule.
+	GroupBy string
+	// ModuleDepth is the number of module calls of module addresses grouped by GroupByModule,
+	// e.g. module.a.module.b is grouped into module.a if 1. Addresses are not truncated if 0.
+	ModuleDepth int
+	// ModuleTotals adds a table of the number of changes for each top-level module to the summary.
+	ModuleTotals bool
+	// ReplaceOrder distinguishes replacements creating the new resource before destroying the old one
+	// from the ones destroying the old one first, in the summary list and the headers.
+	ReplaceOrder bool
+	// HideComputedChurn collapses the diffs of updates which only make attributes unknown into a line.
+	HideComputedChurn bool
+	// TagOnlyUpdates is how updates changing only tags or labels are rendered: TagOnlyUpdatesNone (as other updates),
+	// TagOnlyUpdatesGroup (in their own group of the summary list and section of the details) or TagOnlyUpdatesSummary
+	// (in their own group of the summary list without details).
+	TagOnlyUpdates string
+	// ReplaceMarkers appends "# forces replacement" to the lines of the attributes forcing replacement in diffs.
+	ReplaceMarkers bool
+	// Query is a jq expression applied to the values before and after each change before diffing,
+	// e.g. `del(.tags_all)`. The first result replaces the values.
+	Query string
+	// AttributeAllowlists limit the diffs of resources to some attributes by type. The first one matching a type is used.
+	AttributeAllowlists []AttributeAllowlist
+	// RedactPatterns are regular expressions whose matches in values are replaced with [REDACTED],
+	// e.g. `ghp_[A-Za-z0-9]+`.
+	RedactPatterns []string
+	// Checklist appends a checklist for reviewers with an item for each destroyed or replaced resource to markdown.
+	Checklist bool
+	// MaxDestroys, MaxReplaces and MaxChanges are limits of the number of destroyed, replaced and changed resources.
+	// Plans over a limit are warned about at the top of markdown, see ThresholdViolations. Limits of 0 are not checked.
+	MaxDestroys int
+	MaxReplaces int
+	MaxChanges  int
+	// FailOnTypes are globs of resource types, e.g. "aws_db_instance", which must not be updated, destroyed or replaced.
+	// Such changes are listed at the top of markdown and are policy violations, see TypeViolations.
+	FailOnTypes []string
+	// WarnOnTypes are globs of resource types whose updates, destructions and replacements are annotated with
+	// "⚠ review required" in their headers without failing, e.g. to roll out FailOnTypes gradually.
+	WarnOnTypes []string
+	// Severities map the actions of terraform, create, update, delete and replace, to severities, e.g. SeverityHigh.
+	// See DefaultSeverities.
+	Severities map[string]string
+	// ShowSeverity appends the severities of actions to their labels in the summary of markdown, e.g. "🔴 high".
+	ShowSeverity bool
+	// MaxPlanAge warns about plans generated longer ago than this at the top of markdown, as applying stale plans
+	// may fail or surprise. Plans are not checked if 0. See PlanAge.
+	MaxPlanAge time.Duration
+	// Now is the time the ages of plans are compared to, or the current time if zero. It is not set by flags.
+	Now time.Time
+	// DriftOnly renders the changes of resources outside of terraform instead of the planned changes,
+	// for scheduled jobs reporting drift.
+	DriftOnly bool
+	// MaxDetailedResources omits the details of plans changing more resources than this, leaving the summary and a note,
+	// to keep comments on pull requests light. Details are not omitted if 0.
+	MaxDetailedResources int
+	// DestructiveFirst moves the details of destroyed and replaced resources from the details to a section
+	// at the top of markdown, so that the riskiest changes are read first.
+	DestructiveFirst bool
+	// HighlightEmbedded renders the changed string values which are JSON, YAML or shell scripts, e.g. policies
+	// and user_data, in their own code blocks with language hints below the diffs of resources. See EmbeddedValues.
+	HighlightEmbedded bool
+	// ExitCodes map conditions of plans, e.g. ExitChangesPresent, to exit codes of the command line tool. See ExitCode.
+	ExitCodes map[string]int
+	// Header and Footer are markdown prepended and appended to markdown, e.g. links to runs or instructions of applies.
+	// They are not set by flags.
+	Header string
+	Footer string
+	// Templates override the named templates of markdown by name, TemplateSummary, TemplateDetails or TemplateResource,
+	// with texts of text/template, keeping the rest of the upstream template. It is not set by flags.
+	Templates map[string]string
+	// ProviderSchemas is the output of `terraform providers schema -json`. Attributes sensitive in the schemas are masked
+	// even if the plan does not mark them, and attributes only computed by providers are churn for HideComputedChurn.
+	// It is not set by flags.
+	ProviderSchemas *tfjson.ProviderSchemas
+	// Messages override texts of the language by message ID, e.g. {"created": "será creado"}.
+	Messages map[string]string
+}
+
+// DefaultOptions returns the options used by the command line tool unless overridden by flags.
+func DefaultOptions() Options {
+	return Options{
+		Format:          FormatMarkdown,
+		EscapeHTML:      true,
+		HeadingLevel:    DefaultHeadingLevel,
+		CodeFenceChar:   DefaultCodeFenceChar,
+		CodeFenceLength: DefaultCodeFenceLength,
+		CodeLanguage:    DefaultCodeLanguage,
+		SummaryStyle:    SummaryStyleList,
+		DetailStyle:     DetailStyleDiff,
+		Theme:           ThemeLight,
+		Profile:         ProfileScreen,
+		Color:           true,
+		Lang:            LangEnglish,
+		Severities:      DefaultSeverities(),
+		ExitCodes:       map[string]int{ExitPolicyViolation: DefaultPolicyViolationExitCode},
+	}
+}
+
+// Validate reports whether the options are consistent.
+func (o Options) Validate() error {
+	if !containsString(formats, o.Format) {
+		return fmt.Errorf("unknown format %q (must be one of %v)", o.Format, formats)
+	}
+	if err := validateSortOrder(o.Sort); err != nil {
+		return err
+	}
+	if o.HeadingLevel < 0 || o.HeadingLevel > 6 {
+		return fmt.Errorf("heading level must be between 0 and 6: %d", o.HeadingLevel)
+	}
+	if o.CodeFenceChar != "`" && o.CodeFenceChar != "~" {
+		return fmt.Errorf("code fence character must be ` or ~: %q", o.CodeFenceChar)
+	}
+	if o.CodeFenceLength < 3 {
+		return fmt.Errorf("code fence length must be 3 or more: %d", o.CodeFenceLength)
+	}
+	if o.SummaryStyle != SummaryStyleList && o.SummaryStyle != SummaryStyleTable {
+		return fmt.Errorf("summary style must be %s or %s: %q", SummaryStyleList, SummaryStyleTable, o.SummaryStyle)
+	}
+	if o.DetailStyle != DetailStyleDiff && o.DetailStyle != DetailStylePaths && o.DetailStyle != DetailStyleFull && o.DetailStyle != DetailStyleHCL {
+		return fmt.Errorf("detail style must be %s, %s, %s or %s: %q", DetailStyleDiff, DetailStylePaths, DetailStyleFull, DetailStyleHCL, o.DetailStyle)
+	}
+	if o.Profile != ProfileScreen && o.Profile != ProfilePrint {
+		return fmt.Errorf("profile must be %s or %s: %q", ProfileScreen, ProfilePrint, o.Profile)
+	}
+	if !containsString(themes, o.Theme) {
+		return fmt.Errorf("unknown theme %q (must be one of %v)", o.Theme, themes)
+	}
+	if !containsString(langs, o.Lang) {
+		return fmt.Errorf("unknown language %q (must be one of %v)", o.Lang, langs)
+	}
+	if !containsString(groupByKeys, o.GroupBy) {
+		return fmt.Errorf("unknown grouping %q (must be one of %v)", o.GroupBy, groupByKeys)
+	}
+	if !containsString(tagOnlyUpdatesModes, o.TagOnlyUpdates) {
+		return fmt.Errorf("unknown rendering of tag-only updates %q (must be one of %v)", o.TagOnlyUpdates, tagOnlyUpdatesModes)
+	}
+	if _, err := compileRedactPatterns(o.RedactPatterns); err != nil {
+		return err
+	}
+	for _, a := range o.AttributeAllowlists {
+		if err := a.validate(); err != nil {
+			return err
+		}
+	}
+	if o.Query != "" {
+		if _, err := compileQuery(o.Query); err != nil {
+			return fmt.Errorf("invalid query: %w", err)
+		}
+	}
+	for name, limit := range map[string]int{"destroys": o.MaxDestroys, "replaces": o.MaxReplaces, "changes": o.MaxChanges} {
+		if limit < 0 {
+			return fmt.Errorf("maximum %s must be 0 or more: %d", name, limit)
+		}
+	}
+	if err := validateTypePatterns(o.FailOnTypes); err != nil {
+		return err
+	}
+	if err := validateTypePatterns(o.WarnOnTypes); err != nil {
+		return err
+	}
+	if err := validateTypePatterns(o.FullDocumentTypes); err != nil {
+		return err
+	}
+	if err := validateSeverities(o.Severities); err != nil {
+		return err
+	}
+	for name := range o.Templates {
+		if !containsString(templateNames, name) {
+			return fmt.Errorf("unknown template %q (must be one of %v)", name, templateNames)
+		}
+	}
+	if err := validateExitCodes(o.ExitCodes); err != nil {
+		return err
+	}
+	if o.MaxDiffLines < 0 {
+		return fmt.Errorf("maximum diff lines must be 0 or more: %d", o.MaxDiffLines)
+	}
+	if o.MaxDetailedResources < 0 {
+		return fmt.Errorf("maximum detailed resources must be 0 or more: %d", o.MaxDetailedResources)
+	}
+	if o.MaxPlanAge < 0 {
+		return fmt.Errorf("maximum age of plans must be 0 or more: %s", o.MaxPlanAge)
+	}
+	if o.ModuleDepth < 0 {
+		return fmt.Errorf("module depth must be 0 or more: %d", o.ModuleDepth)
+	}
+	for _, l := range o.LinkTemplates {
+		if err := l.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateMessages(o.Messages); err != nil {
+		return err
+	}
+	if _, err := template.New("heading").Funcs(textFuncs).Parse(o.HeadingFormat); err != nil {
+		return fmt.Errorf("invalid heading format: %w", err)
+	}
+	return nil
+}
+
+// withDefaults returns DefaultOptions for the zero value of Options, e.g. of callers not starting from
+// DefaultOptions, and otherwise the options with the defaults for empty fields which are invalid when empty.
+// Fields whose zero values are valid, like HeadingLevel 0 rendering the heading as plain text, are kept.
+func (o Options) withDefaults() Options {
+	if reflect.ValueOf(o).IsZero() {
+		return DefaultOptions()
+	}
+	defaults := DefaultOptions()
+	if o.Format == "" {
+		o.Format = defaults.Format
+	}
+	if o.CodeFenceChar == "" {
+		o.CodeFenceChar = defaults.CodeFenceChar
+	}
+	if o.CodeFenceLength == 0 {
+		o.CodeFenceLength = defaults.CodeFenceLength
+	}
+	if o.SummaryStyle == "" {
+		o.SummaryStyle = defaults.SummaryStyle
+	}
+	if o.DetailStyle == "" {
+		o.DetailStyle = defaults.DetailStyle
+	}
+	if o.Profile == "" {
+		o.Profile = defaults.Profile
+	}
+	if o.Theme == "" {
+		o.Theme = defaults.Theme
+	}
+	if o.Lang == "" {
+		o.Lang = defaults.Lang
+	}
+	if o.Severities == nil {
+		o.Severities = defaults.Severities
+	}
+	return o
 }
 
 type ResourceChangeDataRenderer interface {
@@ -59,45 +609,554 @@ type ResourceChangeDataRenderer interface {
 type ResourceChangeData struct {
 	ResourceChange *tfjson.ResourceChange
 	Renderer       ResourceChangeDataRenderer
+	ActionReason   string
+	options        Options
+	provider       string
+	drift          []string
+	condition      string
+	schema         *tfjson.SchemaBlock
 }
 
 func (r ResourceChangeData) Render() (string, error) {
-	return r.Renderer.Render()
+	if r.options.HideComputedChurn {
+		if attributes := r.computedOnlyAttributes(); len(attributes) > 0 {
+			return fmt.Sprintf(r.options.message("computed_only"), strings.Join(attributes, ", ")) + "\n", nil
+		}
+	}
+	text, err := r.Renderer.Render()
+	if err != nil {
+		return "", err
+	}
+	return r.truncateDiff(text), nil
+}
+
+// truncateDiff returns the first MaxDiffLines of the options of a diff followed by a note of the number of the rest,
+// so that a single huge resource does not dominate the report. Diffs are not truncated if MaxDiffLines is 0.
+func (r ResourceChangeData) truncateDiff(text string) string {
+	limit := r.options.MaxDiffLines
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	if limit <= 0 || len(lines) <= limit {
+		return text
+	}
+	note := fmt.Sprintf(r.options.message("diff_truncated"), len(lines)-limit)
+	return strings.Join(lines[:limit], "") + note + "\n"
+}
+
+// computedOnlyAttributes returns the changed attributes of an update if all of them become unknown
+// or are only computed by the provider in the schema, which is typical of resources depending on replaced ones,
+// or nil otherwise.
+func (r ResourceChangeData) computedOnlyAttributes() []string {
+	change := r.ResourceChange.Change
+	if !change.Actions.Update() {
+		return nil
+	}
+	afterUnknown, _ := change.AfterUnknown.(map[string]interface{})
+	attributes := r.ChangedAttributes()
+	for _, k := range attributes {
+		if unknown, _ := afterUnknown[k].(bool); !unknown && !isComputedOnly(r.schema, k) {
+			return nil
+		}
+	}
+	return attributes
 }
 
 func (r ResourceChangeData) Header() string {
-	return r.Renderer.Header()
+	header := r.Renderer.Header()
+	if importing := r.ResourceChange.Change.Importing; importing != nil {
+		id := "imported"
+		if r.ResourceChange.Change.Actions.Update() {
+			id = "imported_updated"
+		}
+		header = fmt.Sprintf("%s (%s)", header, fmt.Sprintf(r.options.message(id), importing.ID))
+	}
+	if r.condition != "" {
+		header = fmt.Sprintf("%s (%s)", header, r.options.message(conditionMessage(r.condition)))
+	}
+	if len(r.drift) > 0 {
+		header = fmt.Sprintf("%s (%s)", header, fmt.Sprintf(r.options.message("drift"), strings.Join(r.drift, ", ")))
+	}
+	if r.ReviewRequired() {
+		header = fmt.Sprintf("%s (%s)", header, r.options.message("review_required"))
+	}
+	if r.options.ShowProvider && r.provider != "" {
+		header = fmt.Sprintf("%s (%s: %s)", header, r.options.message("provider"), r.provider)
+	}
+	if r.options.DiffStats {
+		if added, removed, ok := r.diffStats(); ok {
+			header = fmt.Sprintf("%s (%s)", header, fmt.Sprintf(r.options.message("diff_stats"), added, removed))
+		}
+	}
+	if marker := r.Marker(); marker != "" {
+		header = marker + " " + header
+	}
+	return header
+}
+
+// Marker returns the text marker of the action of the resource, e.g. "[ADD]", if Accessible, or "" otherwise.
+func (r ResourceChangeData) Marker() string {
+	if !r.options.Accessible || r.Action() == "" {
+		return ""
+	}
+	return r.options.message("marker_" + r.Action())
+}
+
+// diffStats returns the numbers of added and removed lines of the unified diff of the resource, whatever DetailStyle
+// of the options is, and false if the diff cannot be rendered.
+func (r ResourceChangeData) diffStats() (int, int, bool) {
+	diffText, err := NewUnifiedDiffRenderer(r.ResourceChange, r.options).Render()
+	if err != nil {
+		return 0, 0, false
+	}
+	var added, removed int
+	for _, line := range strings.Split(diffText, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	// The documents of created and destroyed resources are diffed with "null", which is not a change of lines.
+	if r.ResourceChange.Change.Before == nil {
+		removed = 0
+	}
+	if r.ResourceChange.Change.After == nil {
+		added = 0
+	}
+	return added, removed, true
+}
+
+// Drift returns the attributes changed outside of terraform which may have caused the change,
+// of the resource itself or of the resources its configuration refers to, e.g. "aws_vpc.main.tags.Name".
+func (r ResourceChangeData) Drift() []string {
+	return r.drift
+}
+
+// Provider returns the provider of the resource without the host of the public registry, with the alias if any,
+// e.g. "hashicorp/aws.west".
+func (r ResourceChangeData) Provider() string {
+	return r.provider
+}
+
+func (r ResourceChangeData) Address() string {
+	return r.ResourceChange.Address
+}
+
+// Action returns the label of the action, which is the same as the one in the summary list.
+func (r ResourceChangeData) Action() string {
+	return actionLabel(r.ResourceChange)
+}
+
+// Reason returns why the action is planned, if terraform reported it.
+func (r ResourceChangeData) Reason() string {
+	if isMovedBlock(r.ResourceChange) {
+		return fmt.Sprintf(r.options.message("moved_from"), codeSpan(r.ResourceChange.PreviousAddress))
+	}
+	if r.ActionReason == "" {
+		return ""
+	}
+	return r.options.message(r.ActionReason)
+}
+
+// DocsURL returns the documentation page of the type of the resource on registry.terraform.io,
+// or "" if the provider is not of the public registry.
+func (r ResourceChangeData) DocsURL() string {
+	return registryDocsURL(r.ResourceChange)
+}
+
+// ChangedAttributes returns the sorted names of top-level attributes which are updated in-place or replaced.
+func (r ResourceChangeData) ChangedAttributes() []string {
+	change := r.ResourceChange.Change
+	if !change.Actions.Update() && !change.Actions.Replace() {
+		return nil
+	}
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	afterUnknown, _ := change.AfterUnknown.(map[string]interface{})
+
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	var changed []string
+	for k := range keys {
+		if unknown, ok := afterUnknown[k].(bool); ok && unknown {
+			changed = append(changed, k)
+			continue
+		}
+		if !reflect.DeepEqual(before[k], after[k]) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
 }
 
+func actionLabel(rc *tfjson.ResourceChange) string {
+	switch {
+	case isMovedBlock(rc):
+		return "moved"
+	case rc.Change.Actions.Create():
+		return "add"
+	case rc.Change.Actions.Update():
+		return "change"
+	case rc.Change.Actions.Delete():
+		return "destroy"
+	case rc.Change.Actions.Replace():
+		return "replace"
+	}
+	return ""
+}
+
+// Render writes the plan in the output format of the options.
 func (plan *PlanData) Render(w io.Writer) error {
+	switch plan.options.Format {
+	case FormatHTML:
+		return plan.renderHTML(w)
+	case FormatAsciiDoc:
+		return plan.renderAsciiDoc(w)
+	case FormatConfluence:
+		return plan.renderConfluence(w)
+	case FormatTeams:
+		return plan.renderTeams(w)
+	case FormatCSV:
+		return plan.renderCSV(w)
+	case FormatSARIF:
+		return plan.renderSARIF(w)
+	case FormatJUnit:
+		return plan.renderJUnit(w)
+	case FormatTAP:
+		return plan.renderTAP(w)
+	case FormatTerm:
+		return plan.renderTerm(w)
+	case FormatJSONPatch:
+		return plan.renderJSONPatch(w)
+	case FormatChangelog:
+		return plan.renderChangelog(w)
+	case FormatCommitMsg:
+		return plan.renderCommitMsg(w)
+	case FormatTitle:
+		return plan.renderTitle(w)
+	case FormatStatus:
+		return plan.renderStatus(w)
+	default:
+		return plan.renderMarkdown(w)
+	}
+}
+
+func (plan *PlanData) renderMarkdown(w io.Writer) error {
 	funcMap := template.FuncMap{
+		"printProfile": func() bool {
+			return plan.options.Profile == ProfilePrint
+		},
+		"pieChart": func() bool {
+			return plan.options.PieChart
+		},
+		"dependencyGraph": func() bool {
+			return plan.options.DependencyGraph
+		},
+		"severity": func(action string) string {
+			if !plan.options.ShowSeverity {
+				return ""
+			}
+			if label := plan.options.severityLabel(action); label != "" {
+				return " " + label
+			}
+			return ""
+		},
+		"staleWarning": plan.staleWarning,
+		"actionMessage": func(id string) string {
+			if plan.options.DriftOnly {
+				id = "drift_" + id
+			}
+			return plan.options.message(id)
+		},
+		"checklist": func() bool {
+			return plan.options.Checklist
+		},
+		"summaryStyle": func() string {
+			return plan.options.SummaryStyle
+		},
+		"tableCell": tableCell,
+		"codeList": func(ss []string) string {
+			quoted := make([]string, len(ss))
+			for i, s := range ss {
+				quoted[i] = codeSpan(s)
+			}
+			return strings.Join(quoted, ", ")
+		},
 		"codeFence": func() string {
-			return "````````"
+			return strings.Repeat(plan.options.CodeFenceChar, plan.options.CodeFenceLength)
+		},
+		"code": codeSpan,
+		"embeddedValues": func(r ResourceChangeData) []EmbeddedValue {
+			if !plan.options.HighlightEmbedded {
+				return nil
+			}
+			return r.EmbeddedValues()
+		},
+		"codeLanguage": func() string {
+			return plan.options.CodeLanguage
+		},
+		"detailsURL": func() string {
+			return plan.options.DetailsURL
+		},
+		"message": plan.options.message,
+		"link":    markdownLink,
+		"groupBy": func() string {
+			return plan.options.GroupBy
+		},
+		"summaryGroups": plan.summaryGroups,
+		"updatedAddresses": func() []string {
+			if plan.options.TagOnlyUpdates == TagOnlyUpdatesNone {
+				return plan.UpdatedAddresses
+			}
+			var addresses []string
+			for _, r := range plan.ResourceChanges {
+				if r.Action() == "change" && !r.isTagOnly() {
+					addresses = append(addresses, r.Address())
+				}
+			}
+			return addresses
+		},
+		"tagOnlyAddresses": func() []string {
+			var addresses []string
+			for _, r := range plan.tagOnlyChanges() {
+				addresses = append(addresses, r.Address())
+			}
+			return addresses
+		},
+		"detailsOmitted": plan.detailsOmitted,
+		"maxDetailedResources": func() int {
+			return plan.options.MaxDetailedResources
+		},
+		"destructiveChanges": func() []ResourceChangeData {
+			if !plan.options.DestructiveFirst || plan.detailsOmitted() {
+				return nil
+			}
+			return plan.destructiveChanges()
+		},
+		"detailChanges": func() []ResourceChangeData {
+			if plan.detailsOmitted() {
+				return nil
+			}
+			if plan.options.TagOnlyUpdates == TagOnlyUpdatesNone && !plan.options.DestructiveFirst {
+				return plan.ResourceChanges
+			}
+			var changes []ResourceChangeData
+			for _, r := range plan.ResourceChanges {
+				if plan.options.DestructiveFirst && r.isDestructive() {
+					continue
+				}
+				if plan.options.TagOnlyUpdates == TagOnlyUpdatesNone || !r.isTagOnly() {
+					changes = append(changes, r)
+				}
+			}
+			return changes
+		},
+		"tagOnlyDetails": func() []ResourceChangeData {
+			if plan.options.TagOnlyUpdates != TagOnlyUpdatesGroup || plan.detailsOmitted() {
+				return nil
+			}
+			return plan.tagOnlyChanges()
+		},
+		"conditionMessage": func(status string) string {
+			return plan.options.message(conditionMessage(status))
+		},
+		"replaceOrder": func() bool {
+			return plan.options.ReplaceOrder
+		},
+		"replaceGroups": plan.replaceGroups,
+		"moduleTotals": func() []moduleTotal {
+			if !plan.options.ModuleTotals {
+				return nil
+			}
+			return plan.moduleTotals()
+		},
+		"address": func(address string) string {
+			if u := plan.addressURL(address); u != "" {
+				return markdownLink(codeSpan(address), u)
+			}
+			if id := plan.anchor(address); id != "" {
+				return markdownLink(codeSpan(address), "#"+id)
+			}
+			return codeSpan(address)
+		},
+		"anchor":       plan.anchor,
+		"stateContext": plan.stateContext,
+		"outputsHeading": func() string {
+			if plan.options.HeadingLevel == 0 {
+				return plan.options.message("outputs_only")
+			}
+			return strings.Repeat("#", plan.options.HeadingLevel) + " " + plan.options.message("outputs_only")
+		},
+		"moduleLinks": func() []moduleLink {
+			if !plan.options.ModuleLinks {
+				return nil
+			}
+			return plan.moduleLinks()
+		},
+		"docsLink": func(r ResourceChangeData) string {
+			if !plan.options.RegistryLinks {
+				return ""
+			}
+			u := r.DocsURL()
+			if u == "" {
+				return ""
+			}
+			return markdownLink(codeSpan(r.ResourceChange.Type), u)
 		},
 	}
-	planTemplate, err := template.New("plan").Funcs(funcMap).Parse(planTemplateBody)
+	planTemplate, err := template.New("plan").Funcs(textFuncs).Funcs(funcMap).Parse(planTemplateBody)
 	if err != nil {
 		return fmt.Errorf("invalid template text: %w", err)
 	}
+	for _, name := range templateNames {
+		if text, ok := plan.options.Templates[name]; ok {
+			if _, err := planTemplate.New(name).Parse(text); err != nil {
+				return fmt.Errorf("invalid template %q: %w", name, err)
+			}
+		}
+	}
 
-	if err := planTemplate.Execute(w, plan); err != nil {
+	if plan.options.Header != "" {
+		if _, err := io.WriteString(w, strings.TrimRight(plan.options.Header, "\n")+"\n\n"); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+	name := "plan"
+	if plan.outputsOnly() {
+		name = "outputs"
+	}
+	if err := planTemplate.ExecuteTemplate(w, name, plan); err != nil {
 		return fmt.Errorf("failed to render template: %w", err)
 	}
+	if plan.options.Footer != "" {
+		if _, err := io.WriteString(w, "\n"+strings.TrimRight(plan.options.Footer, "\n")+"\n"); err != nil {
+			return fmt.Errorf("failed to write footer: %w", err)
+		}
+	}
 	return nil
 }
 
-func processPlan(plan *tfjson.Plan) (*tfjson.Plan, error) {
+// stateContext returns the workspace and the backend of the options in a line, e.g. "Workspace: `prod` · Backend: `s3`
+// (`s3://bucket/key`)", or "" if none is set.
+func (plan *PlanData) stateContext() string {
+	o := plan.options
+	var parts []string
+	if o.Workspace != "" {
+		parts = append(parts, fmt.Sprintf("%s: %s", o.message("workspace"), codeSpan(o.Workspace)))
+	}
+	switch {
+	case o.Backend != "" && o.StateAddress != "":
+		parts = append(parts, fmt.Sprintf("%s: %s (%s)", o.message("backend"), codeSpan(o.Backend), codeSpan(o.StateAddress)))
+	case o.Backend != "":
+		parts = append(parts, fmt.Sprintf("%s: %s", o.message("backend"), codeSpan(o.Backend)))
+	case o.StateAddress != "":
+		parts = append(parts, fmt.Sprintf("%s: %s", o.message("backend"), codeSpan(o.StateAddress)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// destructiveChanges returns the destroyed and replaced resources in the order of resource changes.
+func (plan *PlanData) destructiveChanges() []ResourceChangeData {
+	var changes []ResourceChangeData
+	for _, r := range plan.ResourceChanges {
+		if r.isDestructive() {
+			changes = append(changes, r)
+		}
+	}
+	return changes
+}
+
+// isDestructive reports whether the resource is destroyed or replaced.
+func (r ResourceChangeData) isDestructive() bool {
+	actions := r.ResourceChange.Change.Actions
+	return actions.Delete() || actions.Replace()
+}
+
+// detailsOmitted reports whether the plan changes more resources than MaxDetailedResources of the options.
+func (plan *PlanData) detailsOmitted() bool {
+	return plan.options.MaxDetailedResources > 0 && len(plan.ResourceChanges) > plan.options.MaxDetailedResources
+}
+
+// PieChart returns a mermaid pie chart of the number of resources for each action.
+func (plan *PlanData) PieChart() string {
+	var b strings.Builder
+	b.WriteString("pie title Resource changes\n")
+	for _, slice := range []struct {
+		label     string
+		addresses []string
+	}{
+		{"add", plan.CreatedAddresses},
+		{"change", plan.UpdatedAddresses},
+		{"destroy", plan.DeletedAddresses},
+		{"replace", plan.ReplacedAddresses},
+		{"moved", plan.MovedAddresses},
+	} {
+		if len(slice.addresses) > 0 {
+			b.WriteString(fmt.Sprintf("  \"%s\" : %d\n", slice.label, len(slice.addresses)))
+		}
+	}
+	return b.String()
+}
+
+// Heading returns the summary line of the plan.
+func (plan *PlanData) Heading() (string, error) {
+	text, err := plan.headingText()
+	if err != nil {
+		return "", err
+	}
+	if plan.options.HeadingLevel == 0 {
+		return text, nil
+	}
+	return strings.Repeat("#", plan.options.HeadingLevel) + " " + text, nil
+}
+
+// headingText returns the summary line without markdown.
+func (plan *PlanData) headingText() (string, error) {
+	headingFormat := plan.options.HeadingFormat
+	if headingFormat == "" && plan.options.DriftOnly {
+		headingFormat = plan.options.message("drift_heading")
+	} else if headingFormat == "" {
+		headingFormat = plan.options.message("heading")
+	}
+	t, err := template.New("heading").Funcs(textFuncs).Parse(headingFormat)
+	if err != nil {
+		return "", fmt.Errorf("invalid heading format: %w", err)
+	}
+	var buff strings.Builder
+	if err := t.Execute(&buff, plan); err != nil {
+		return "", fmt.Errorf("failed to render heading: %w", err)
+	}
+	return buff.String(), nil
+}
+
+func processPlan(plan *tfjson.Plan, opts Options) (*tfjson.Plan, error) {
 	var err error
 
 	for i := range plan.ResourceChanges {
+		if schema := opts.resourceSchema(plan.ResourceChanges[i]); schema != nil {
+			markSchemaSensitivity(plan.ResourceChanges[i].Change, schema)
+		}
 		plan.ResourceChanges[i].Change, err = sanitize.SanitizeChange(plan.ResourceChanges[i].Change, sanitize.DefaultSensitiveValue)
 		if err != nil {
 			return nil, fmt.Errorf("failed to sanitize change: %w", err)
 		}
 
-		plan.ResourceChanges[i].Change, err = format.FormatJsonChange(plan.ResourceChanges[i].Change)
-		if err != nil {
-			return nil, fmt.Errorf("failed to format json change: %w", err)
+		if !opts.RawValues {
+			plan.ResourceChanges[i].Change, err = format.FormatPemChange(plan.ResourceChanges[i].Change)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format pem change: %w", err)
+			}
+
+			plan.ResourceChanges[i].Change, err = format.FormatJsonChange(plan.ResourceChanges[i].Change)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format json change: %w", err)
+			}
 		}
 
 		plan.ResourceChanges[i].Change, err = format.FormatUnknownChange(plan.ResourceChanges[i].Change)
@@ -106,33 +1165,89 @@ func processPlan(plan *tfjson.Plan) (*tfjson.Plan, error) {
 		}
 	}
 
+	if len(opts.RedactPatterns) > 0 {
+		if err := redactChanges(plan, opts.RedactPatterns); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.AttributeAllowlists) > 0 {
+		allowlistAttributes(plan, opts.AttributeAllowlists)
+	}
+	if opts.Query != "" {
+		if err := queryChanges(plan, opts.Query); err != nil {
+			return nil, err
+		}
+	}
 	return plan, nil
 }
 
-func NewPlanData(input io.Reader, escapeHTML bool) (*PlanData, error) {
+// NewPlanData parses the plan JSON in input. Empty fields of opts which are invalid when empty are filled with
+// the defaults of DefaultOptions, and other invalid options are errors.
+func NewPlanData(input io.Reader, opts Options) (*PlanData, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
 	var err error
+	b, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read input: %w", err)
+	}
 	var plan tfjson.Plan
-	if err := json.NewDecoder(input).Decode(&plan); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&plan); err != nil {
 		return nil, fmt.Errorf("cannot parse input: %w", err)
 	}
+	var extras planExtras
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&extras); err != nil {
+		return nil, fmt.Errorf("cannot parse input: %w", err)
+	}
+
+	if opts.DriftOnly {
+		plan.ResourceChanges, plan.ResourceDrift = plan.ResourceDrift, nil
+		extras.ResourceChanges = extras.ResourceDrift
+	}
 
-	processedPlan, err := processPlan(&plan)
+	processedPlan, err := processPlan(&plan, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	planData := PlanData{}
+	sortResourceChanges(processedPlan.ResourceChanges, opts.Sort)
+
+	planData := PlanData{options: opts, config: processedPlan.Config}
+	if processedPlan.Timestamp != "" {
+		planData.Timestamp, err = time.Parse(time.RFC3339, processedPlan.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp of plan: %w", err)
+		}
+	}
+	planData.OutputChanges, err = outputChanges(processedPlan.OutputChanges, opts)
+	if err != nil {
+		return nil, err
+	}
+	drifted := driftedAttributes(processedPlan)
+	planData.Conditions = conditionResults(processedPlan.Checks)
+	conditions := map[string]string{}
+	for _, c := range planData.Conditions {
+		if c.Kind == string(tfjson.CheckKindResource) {
+			conditions[c.Address] = c.Status
+		}
+	}
 	for _, c := range processedPlan.ResourceChanges {
 		if isMovedBlock(c) {
-			planData.MovedAddresses = append(planData.MovedAddresses, fmt.Sprintf("%s (from %s)", c.Address, c.PreviousAddress))
+			planData.MovedAddresses = append(planData.MovedAddresses, fmt.Sprintf("%s (from %s)", codeSpan(c.Address), codeSpan(c.PreviousAddress)))
 			planData.ResourceChanges = append(planData.ResourceChanges, ResourceChangeData{
 				ResourceChange: c,
-				Renderer:       NewMovedBlockRenderer(c),
+				Renderer:       NewMovedBlockRenderer(c, opts),
+				ActionReason:   extras.resourceChange(c).ActionReason,
+				options:        opts,
+				provider:       planData.providerLabel(c),
 			})
 			continue
 		}
 
 		if c.Change.Actions.NoOp() || c.Change.Actions.Read() {
+			slog.Debug("skipping resource without changes", "address", c.Address, "actions", c.Change.Actions)
 			continue
 		}
 
@@ -146,14 +1261,84 @@ func NewPlanData(input io.Reader, escapeHTML bool) (*PlanData, error) {
 		case c.Change.Actions.Replace():
 			planData.ReplacedAddresses = append(planData.ReplacedAddresses, c.Address)
 		}
+		planData.SecretWarnings = append(planData.SecretWarnings, detectSecrets(c)...)
+		var renderer ResourceChangeDataRenderer
+		if opts.DetailStyle == DetailStylePaths {
+			renderer = NewAttributePathRenderer(c, opts)
+		} else if opts.DetailStyle == DetailStyleHCL {
+			hclRenderer := NewHCLRenderer(c, opts)
+			hclRenderer.ReplacePaths = extras.resourceChange(c).Change.ReplacePaths
+			renderer = hclRenderer
+		} else {
+			diffRenderer := NewUnifiedDiffRenderer(c, opts)
+			diffRenderer.ReplacePaths = extras.resourceChange(c).Change.ReplacePaths
+			renderer = diffRenderer
+		}
 		planData.ResourceChanges = append(planData.ResourceChanges, ResourceChangeData{
 			ResourceChange: c,
-			Renderer:       NewUnifiedDiffRenderer(c, escapeHTML),
+			Renderer:       renderer,
+			ActionReason:   extras.resourceChange(c).ActionReason,
+			options:        opts,
+			provider:       planData.providerLabel(c),
+			drift:          planData.driftCauses(c, drifted),
+			condition:      conditions[c.Address],
+			schema:         opts.resourceSchema(c),
 		})
 	}
 	return &planData, nil
 }
 
+func containsString(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
 func isMovedBlock(rc *tfjson.ResourceChange) bool {
 	return rc.Change.Actions.NoOp() && rc.PreviousAddress != ""
 }
+
+// ContentType returns the media type of output in format.
+func ContentType(format string) string {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatConfluence:
+		return "application/xhtml+xml; charset=utf-8"
+	case FormatTeams, FormatSARIF, FormatJSONPatch:
+		return "application/json"
+	case FormatJUnit:
+		return "application/xml"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatAsciiDoc:
+		return "text/asciidoc; charset=utf-8"
+	case FormatTAP, FormatTerm, FormatCommitMsg, FormatTitle, FormatStatus:
+		return "text/plain; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// FileExtension returns the extension of files of the format, without the dot.
+func FileExtension(format string) string {
+	switch format {
+	case FormatMarkdown, FormatChangelog:
+		return "md"
+	case FormatAsciiDoc:
+		return "adoc"
+	case FormatConfluence:
+		return "xhtml"
+	case FormatTeams, FormatJSONPatch:
+		return "json"
+	case FormatJUnit:
+		return "xml"
+	case FormatTerm, FormatCommitMsg, FormatTitle, FormatStatus:
+		return "txt"
+	default:
+		return format
+	}
+}
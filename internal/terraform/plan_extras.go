@@ -0,0 +1,29 @@
+package terraform
+
+import (
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// planExtras holds fields of the plan JSON which terraform-json does not decode.
+type planExtras struct {
+	ResourceChanges []resourceChangeExtras `json:"resource_changes"`
+	ResourceDrift   []resourceChangeExtras `json:"resource_drift"`
+}
+
+type resourceChangeExtras struct {
+	Address      string `json:"address"`
+	DeposedKey   string `json:"deposed"`
+	ActionReason string `json:"action_reason"`
+	Change       struct {
+		ReplacePaths [][]interface{} `json:"replace_paths"`
+	} `json:"change"`
+}
+
+func (e *planExtras) resourceChange(rc *tfjson.ResourceChange) resourceChangeExtras {
+	for _, c := range e.ResourceChanges {
+		if c.Address == rc.Address && c.DeposedKey == rc.DeposedKey {
+			return c
+		}
+	}
+	return resourceChangeExtras{}
+}
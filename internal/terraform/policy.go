@@ -0,0 +1,46 @@
+package terraform
+
+import (
+	"fmt"
+	"path"
+)
+
+// HasPolicyViolations reports whether the plan violates a policy of the options, e.g. MaxDestroys or FailOnTypes.
+func (plan *PlanData) HasPolicyViolations() bool {
+	return len(plan.ThresholdViolations()) > 0 || len(plan.TypeViolations()) > 0
+}
+
+// TypeViolations returns the resources of types in FailOnTypes of the options which are changed other than created,
+// in the order of resource changes.
+func (plan *PlanData) TypeViolations() []ResourceChangeData {
+	var violations []ResourceChangeData
+	for _, r := range plan.ResourceChanges {
+		if r.changesTypeIn(plan.options.FailOnTypes) {
+			violations = append(violations, r)
+		}
+	}
+	return violations
+}
+
+// ReviewRequired reports whether the resource is of a type in WarnOnTypes of the options and is changed other than
+// created, which is annotated in its header without failing.
+func (r ResourceChangeData) ReviewRequired() bool {
+	return r.changesTypeIn(r.options.WarnOnTypes)
+}
+
+// changesTypeIn reports whether the resource is updated, destroyed or replaced and its type matches one of patterns.
+func (r ResourceChangeData) changesTypeIn(patterns []string) bool {
+	if isMovedBlock(r.ResourceChange) || r.ResourceChange.Change.Actions.Create() {
+		return false
+	}
+	return r.typeIn(patterns)
+}
+
+func validateTypePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern of resource types %q: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package terraform
+
+import (
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// providerLabel returns the provider of a resource change, without the host of the public registry
+// and with the alias of its configuration if any, e.g. "hashicorp/aws.west".
+func (plan *PlanData) providerLabel(rc *tfjson.ResourceChange) string {
+	label := strings.TrimPrefix(rc.ProviderName, publicRegistry+"/")
+	if cr := plan.configResource(rc); cr != nil && plan.config.ProviderConfigs != nil {
+		if pc, ok := plan.config.ProviderConfigs[cr.ProviderConfigKey]; ok && pc.Alias != "" {
+			label += "." + pc.Alias
+		}
+	}
+	return label
+}
@@ -0,0 +1,61 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/itchyny/gojq"
+)
+
+// queryTimeout is the limit of the time to apply a query to all the changes of a plan,
+// as expressions like 'range(1e12)' never end.
+const queryTimeout = 10 * time.Second
+
+// compileQuery compiles a jq expression of Options.Query.
+func compileQuery(query string) (*gojq.Code, error) {
+	q, err := gojq.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return gojq.Compile(q)
+}
+
+// applyQuery returns the first result of the query on a value before or after a change, or nil if there is none.
+// Absent values, e.g. the value before a creation, are not queried.
+func applyQuery(ctx context.Context, code *gojq.Code, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	result, ok := code.RunWithContext(ctx, v).Next()
+	if !ok {
+		return nil, nil
+	}
+	if err, ok := result.(error); ok {
+		return nil, err
+	}
+	return result, nil
+}
+
+// queryChanges replaces the values before and after the changes with the results of the query.
+func queryChanges(plan *tfjson.Plan, query string) error {
+	code, err := compileQuery(query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		if rc.Change.Before, err = applyQuery(ctx, code, rc.Change.Before); err != nil {
+			return fmt.Errorf("failed to query the values of %s before the change: %w", rc.Address, err)
+		}
+		if rc.Change.After, err = applyQuery(ctx, code, rc.Change.After); err != nil {
+			return fmt.Errorf("failed to query the values of %s after the change: %w", rc.Address, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// redactedValue replaces the matches of redaction patterns.
+const redactedValue = "[REDACTED]"
+
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// redactChanges replaces the matches of the patterns in the strings before and after the changes,
+// for secrets which providers do not mark sensitive.
+func redactChanges(plan *tfjson.Plan, patterns []string) error {
+	res, err := compileRedactPatterns(patterns)
+	if err != nil {
+		return err
+	}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		rc.Change.Before = redactValue(rc.Change.Before, res)
+		rc.Change.After = redactValue(rc.Change.After, res)
+	}
+	return nil
+}
+
+func redactValue(v any, res []*regexp.Regexp) any {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = redactValue(e, res)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = redactValue(e, res)
+		}
+	case string:
+		for _, re := range res {
+			x = re.ReplaceAllLiteralString(x, redactedValue)
+		}
+		return x
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// resourceSchema returns the schema of the type of a resource change in the provider schemas of the options,
+// or nil if there is none.
+func (o Options) resourceSchema(rc *tfjson.ResourceChange) *tfjson.SchemaBlock {
+	if o.ProviderSchemas == nil {
+		return nil
+	}
+	provider, ok := o.ProviderSchemas.Schemas[rc.ProviderName]
+	if !ok {
+		return nil
+	}
+	schemas := provider.ResourceSchemas
+	if rc.Mode == tfjson.DataResourceMode {
+		schemas = provider.DataSourceSchemas
+	}
+	if s, ok := schemas[rc.Type]; ok && s.Block != nil {
+		return s.Block
+	}
+	return nil
+}
+
+// markSchemaSensitivity marks the values of attributes which are sensitive in the schema as sensitive,
+// in addition to the ones terraform marked in the plan.
+func markSchemaSensitivity(change *tfjson.Change, block *tfjson.SchemaBlock) {
+	change.BeforeSensitive = mergeSensitivity(change.BeforeSensitive, schemaSensitivity(block, change.Before))
+	change.AfterSensitive = mergeSensitivity(change.AfterSensitive, schemaSensitivity(block, change.After))
+}
+
+// schemaSensitivity returns the sensitivity of a value of a block, in the form of the sensitivity of plans:
+// true for sensitive values, and objects and lists of the nested values.
+func schemaSensitivity(block *tfjson.SchemaBlock, v any) any {
+	m, ok := v.(map[string]interface{})
+	if !ok || block == nil {
+		return nil
+	}
+	sensitivity := map[string]interface{}{}
+	for name, attr := range block.Attributes {
+		if attr.Sensitive && m[name] != nil {
+			sensitivity[name] = true
+		}
+	}
+	for name, nested := range block.NestedBlocks {
+		switch x := m[name].(type) {
+		case []interface{}:
+			elems := make([]interface{}, len(x))
+			for i, e := range x {
+				elems[i] = schemaSensitivity(nested.Block, e)
+			}
+			sensitivity[name] = elems
+		case map[string]interface{}:
+			if nested.NestingMode == tfjson.SchemaNestingModeMap {
+				elems := map[string]interface{}{}
+				for k, e := range x {
+					elems[k] = schemaSensitivity(nested.Block, e)
+				}
+				sensitivity[name] = elems
+			} else {
+				sensitivity[name] = schemaSensitivity(nested.Block, x)
+			}
+		}
+	}
+	return sensitivity
+}
+
+// mergeSensitivity returns the sensitivity of values which are sensitive in either a or b.
+func mergeSensitivity(a, b any) any {
+	if a == true || b == true {
+		return true
+	}
+	if a == nil || a == false {
+		return b
+	}
+	if b == nil || b == false {
+		return a
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			return a
+		}
+		merged := make(map[string]interface{}, len(x)+len(y))
+		for k, v := range x {
+			merged[k] = v
+		}
+		for k, v := range y {
+			merged[k] = mergeSensitivity(merged[k], v)
+		}
+		return merged
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			return a
+		}
+		merged := make([]interface{}, max(len(x), len(y)))
+		copy(merged, x)
+		for i, v := range y {
+			merged[i] = mergeSensitivity(merged[i], v)
+		}
+		return merged
+	}
+	return a
+}
+
+// isComputedOnly reports whether an attribute of the schema is set by the provider only, e.g. arn or id.
+func isComputedOnly(block *tfjson.SchemaBlock, name string) bool {
+	if block == nil {
+		return false
+	}
+	attr, ok := block.Attributes[name]
+	return ok && attr.Computed && !attr.Optional && !attr.Required
+}
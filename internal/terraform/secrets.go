@@ -0,0 +1,67 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// secretPatterns are the formats of common credentials which are detected in values.
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+	{"JSON Web Token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// SecretWarning is a value of a resource change which looks like a secret.
+// The value itself is not kept so that warnings can be posted safely.
+type SecretWarning struct {
+	Address string
+	// Path is the attribute path of the value, e.g. "tags.Token".
+	Path string
+	// Kind is the kind of secret the value looks like, e.g. "GitHub token".
+	Kind string
+}
+
+// detectSecrets returns the values before or after a change which look like secrets, sorted by path.
+func detectSecrets(rc *tfjson.ResourceChange) []SecretWarning {
+	kinds := map[string]string{}
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			for k, e := range x {
+				walk(path+attributePathKey(path, k), e)
+			}
+		case []interface{}:
+			for i, e := range x {
+				walk(fmt.Sprintf("%s[%d]", path, i), e)
+			}
+		case string:
+			for _, p := range secretPatterns {
+				if p.re.MatchString(x) {
+					kinds[path] = p.kind
+					return
+				}
+			}
+		}
+	}
+	walk("", rc.Change.Before)
+	walk("", rc.Change.After)
+
+	warnings := make([]SecretWarning, 0, len(kinds))
+	for path, kind := range kinds {
+		warnings = append(warnings, SecretWarning{Address: rc.Address, Path: path, Kind: kind})
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Path < warnings[j].Path })
+	return warnings
+}
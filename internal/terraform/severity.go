@@ -0,0 +1,85 @@
+package terraform
+
+import "fmt"
+
+// Severities of actions, in ascending order
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+var severities = []string{SeverityLow, SeverityMedium, SeverityHigh}
+
+// severityActions are the actions of terraform mapped to severities by Options.Severities.
+var severityActions = []string{"create", "update", "delete", "replace"}
+
+var severityEmojis = map[string]string{
+	SeverityLow:    "🟢",
+	SeverityMedium: "🟡",
+	SeverityHigh:   "🔴",
+}
+
+// DefaultSeverities returns the severities of actions unless overridden by options.
+func DefaultSeverities() map[string]string {
+	return map[string]string{
+		"create":  SeverityLow,
+		"update":  SeverityMedium,
+		"delete":  SeverityHigh,
+		"replace": SeverityHigh,
+	}
+}
+
+// summaryActions maps the message IDs of actions of the summary to the actions of terraform.
+var summaryActions = map[string]string{
+	"add":     "create",
+	"change":  "update",
+	"destroy": "delete",
+	"replace": "replace",
+}
+
+// Severity returns the severity of the action of the resource in the options, or "" for moves.
+func (r ResourceChangeData) Severity() string {
+	return r.options.Severities[summaryActions[r.Action()]]
+}
+
+// severity returns the highest severity of the resource changes, or "" if there is none.
+func (plan *PlanData) severity() string {
+	highest := -1
+	for _, r := range plan.ResourceChanges {
+		for i, s := range severities {
+			if s == r.Severity() && i > highest {
+				highest = i
+			}
+		}
+	}
+	if highest < 0 {
+		return ""
+	}
+	return severities[highest]
+}
+
+// severityLabel returns the emoji and the severity of an action of the summary, e.g. "🔴 high",
+// or the severity in words if Accessible, e.g. "(severity: high)".
+func (o Options) severityLabel(action string) string {
+	severity := o.Severities[summaryActions[action]]
+	if severity == "" {
+		return ""
+	}
+	if o.Accessible {
+		return "(" + fmt.Sprintf(o.message("severity_label"), severity) + ")"
+	}
+	return severityEmojis[severity] + " " + severity
+}
+
+func validateSeverities(m map[string]string) error {
+	for action, severity := range m {
+		if !containsString(severityActions, action) {
+			return fmt.Errorf("unknown action of severity %q (must be one of %v)", action, severityActions)
+		}
+		if !containsString(severities, severity) {
+			return fmt.Errorf("unknown severity %q of %s (must be one of %v)", severity, action, severities)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Sort orders of addresses and resource changes
+const (
+	SortNone   = ""
+	SortAlpha  = "alpha"
+	SortAction = "action"
+	SortType   = "type"
+	SortModule = "module"
+)
+
+var sortOrders = []string{SortAlpha, SortAction, SortType, SortModule}
+
+func validateSortOrder(order string) error {
+	if order == SortNone || containsString(sortOrders, order) {
+		return nil
+	}
+	return fmt.Errorf("unknown sort order %q (must be one of %v)", order, sortOrders)
+}
+
+// sortResourceChanges sorts resource changes in place.
+// Resource changes of the same action, type or module are ordered by address, and the ones of the same address,
+// i.e. deposed objects, keep the order terraform emitted them.
+func sortResourceChanges(rcs []*tfjson.ResourceChange, order string) {
+	var compare func(a, b *tfjson.ResourceChange) int
+	switch order {
+	case SortAlpha:
+		compare = func(a, b *tfjson.ResourceChange) int {
+			return 0
+		}
+	case SortAction:
+		compare = func(a, b *tfjson.ResourceChange) int {
+			return actionRank(a) - actionRank(b)
+		}
+	case SortType:
+		compare = func(a, b *tfjson.ResourceChange) int {
+			return strings.Compare(a.Type, b.Type)
+		}
+	case SortModule:
+		compare = func(a, b *tfjson.ResourceChange) int {
+			return strings.Compare(a.ModuleAddress, b.ModuleAddress)
+		}
+	default:
+		return
+	}
+	sort.SliceStable(rcs, func(i, j int) bool {
+		if c := compare(rcs[i], rcs[j]); c != 0 {
+			return c < 0
+		}
+		return rcs[i].Address < rcs[j].Address
+	})
+}
+
+func actionRank(rc *tfjson.ResourceChange) int {
+	switch {
+	case isMovedBlock(rc):
+		return 4
+	case rc.Change.Actions.Create():
+		return 0
+	case rc.Change.Actions.Update():
+		return 1
+	case rc.Change.Actions.Delete():
+		return 2
+	case rc.Change.Actions.Replace():
+		return 3
+	}
+	return 5
+}
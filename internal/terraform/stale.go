@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlanAge returns how long ago terraform generated the plan, compared to Now of the options or the current time,
+// and false if the plan has no timestamp, which is the case before terraform 1.5.
+func (plan *PlanData) PlanAge() (time.Duration, bool) {
+	if plan.Timestamp.IsZero() {
+		return 0, false
+	}
+	now := plan.options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return now.Sub(plan.Timestamp), true
+}
+
+// staleWarning returns a warning if the plan is older than MaxPlanAge of the options, or "" otherwise.
+func (plan *PlanData) staleWarning() string {
+	if plan.options.MaxPlanAge <= 0 {
+		return ""
+	}
+	age, ok := plan.PlanAge()
+	if !ok || age <= plan.options.MaxPlanAge {
+		return ""
+	}
+	return fmt.Sprintf(plan.options.message("stale_plan"), plan.options.formatAge(age))
+}
+
+// formatAge returns a duration in the largest unit of days, hours and minutes, e.g. "3 days".
+func (o Options) formatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf(o.message("age_days"), int(d/(24*time.Hour)))
+	case d >= 24*time.Hour:
+		return o.message("age_day")
+	case d >= 2*time.Hour:
+		return fmt.Sprintf(o.message("age_hours"), int(d/time.Hour))
+	case d >= time.Hour:
+		return o.message("age_hour")
+	default:
+		return fmt.Sprintf(o.message("age_minutes"), int(d/time.Minute))
+	}
+}
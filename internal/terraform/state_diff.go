@@ -0,0 +1,214 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// StateDiff is the difference of the managed resources of two states, outputs of `terraform show -json`,
+// e.g. archived snapshots of a state, as a history of an environment.
+type StateDiff struct {
+	AddedAddresses   []string
+	RemovedAddresses []string
+	ChangedAddresses []string
+	// ResourceChanges are the changes of the resources in the order of their addresses. Added resources are
+	// created, removed ones deleted and changed ones updated.
+	ResourceChanges []*tfjson.ResourceChange
+	options         Options
+}
+
+// NewStateDiff reads the old and the new states and compares their managed resources by address.
+// Values are processed as the ones of plans, e.g. sensitive values are masked and Query of the options is applied.
+func NewStateDiff(oldInput, newInput io.Reader, opts Options) (*StateDiff, error) {
+	oldResources, err := readStateResources(oldInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the old state: %w", err)
+	}
+	newResources, err := readStateResources(newInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the new state: %w", err)
+	}
+
+	addresses := make([]string, 0, len(oldResources)+len(newResources))
+	for a := range oldResources {
+		addresses = append(addresses, a)
+	}
+	for a := range newResources {
+		if _, ok := oldResources[a]; !ok {
+			addresses = append(addresses, a)
+		}
+	}
+	sort.Strings(addresses)
+
+	diff := &StateDiff{options: opts}
+	plan := &tfjson.Plan{}
+	for _, a := range addresses {
+		before, after := oldResources[a].StateResource, newResources[a].StateResource
+		r := newResources[a]
+		change := &tfjson.Change{AfterUnknown: map[string]interface{}{}}
+		switch {
+		case before == nil:
+			change.Actions = tfjson.Actions{tfjson.ActionCreate}
+			diff.AddedAddresses = append(diff.AddedAddresses, a)
+		case after == nil:
+			r = oldResources[a]
+			change.Actions = tfjson.Actions{tfjson.ActionDelete}
+			diff.RemovedAddresses = append(diff.RemovedAddresses, a)
+		case reflect.DeepEqual(before.AttributeValues, after.AttributeValues):
+			continue
+		default:
+			change.Actions = tfjson.Actions{tfjson.ActionUpdate}
+			diff.ChangedAddresses = append(diff.ChangedAddresses, a)
+		}
+		if before != nil {
+			change.Before = map[string]interface{}(before.AttributeValues)
+			if change.BeforeSensitive, err = stateSensitivity(before); err != nil {
+				return nil, err
+			}
+		}
+		if after != nil {
+			change.After = map[string]interface{}(after.AttributeValues)
+			if change.AfterSensitive, err = stateSensitivity(after); err != nil {
+				return nil, err
+			}
+		}
+		plan.ResourceChanges = append(plan.ResourceChanges, &tfjson.ResourceChange{
+			Address:       r.Address,
+			ModuleAddress: r.module,
+			Mode:          r.Mode,
+			Type:          r.Type,
+			Name:          r.Name,
+			Index:         r.Index,
+			ProviderName:  r.ProviderName,
+			Change:        change,
+		})
+	}
+	if plan, err = processPlan(plan, opts); err != nil {
+		return nil, err
+	}
+	if opts.Query == "" {
+		diff.ResourceChanges = plan.ResourceChanges
+		return diff, nil
+	}
+	// Resources whose values are equal after the query are not changed for the query.
+	diff.ChangedAddresses = nil
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change.Actions.Update() {
+			if reflect.DeepEqual(rc.Change.Before, rc.Change.After) {
+				continue
+			}
+			diff.ChangedAddresses = append(diff.ChangedAddresses, rc.Address)
+		}
+		diff.ResourceChanges = append(diff.ResourceChanges, rc)
+	}
+	return diff, nil
+}
+
+// stateResource is a resource of a state with the address of its module.
+type stateResource struct {
+	*tfjson.StateResource
+	module string
+}
+
+// readStateResources returns the managed resources of a state by address.
+func readStateResources(input io.Reader) (map[string]stateResource, error) {
+	var state tfjson.State
+	if err := json.NewDecoder(input).Decode(&state); err != nil {
+		return nil, err
+	}
+	resources := map[string]stateResource{}
+	var walk func(m *tfjson.StateModule)
+	walk = func(m *tfjson.StateModule) {
+		if m == nil {
+			return
+		}
+		for _, r := range m.Resources {
+			if r.Mode == tfjson.ManagedResourceMode {
+				resources[r.Address] = stateResource{StateResource: r, module: m.Address}
+			}
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	if state.Values != nil {
+		walk(state.Values.RootModule)
+	}
+	return resources, nil
+}
+
+// stateSensitivity returns the sensitivity of the values of a resource of a state in the form of plans.
+func stateSensitivity(r *tfjson.StateResource) (any, error) {
+	if len(r.SensitiveValues) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var sensitivity any
+	if err := json.Unmarshal(r.SensitiveValues, &sensitivity); err != nil {
+		return nil, fmt.Errorf("invalid sensitive values of %s: %w", r.Address, err)
+	}
+	return sensitivity, nil
+}
+
+// Render writes the difference in markdown: the addresses of added, removed and changed resources,
+// and the collapsed diffs of the resources.
+func (d *StateDiff) Render(w io.Writer) error {
+	o := d.options
+	var b strings.Builder
+	heading := fmt.Sprintf(o.message("state_diff_heading"), len(d.AddedAddresses), len(d.RemovedAddresses), len(d.ChangedAddresses))
+	b.WriteString(markdownHeading(o.HeadingLevel, heading) + "\n")
+	for _, group := range []struct {
+		message   string
+		addresses []string
+	}{
+		{"state_added", d.AddedAddresses},
+		{"state_removed", d.RemovedAddresses},
+		{"state_changed", d.ChangedAddresses},
+	} {
+		if len(group.addresses) == 0 {
+			continue
+		}
+		b.WriteString("- " + o.message(group.message) + "\n")
+		for _, a := range group.addresses {
+			b.WriteString("    - " + codeSpan(a) + "\n")
+		}
+	}
+	if len(d.ResourceChanges) > 0 {
+		fence := strings.Repeat(o.CodeFenceChar, o.CodeFenceLength)
+		b.WriteString("<details><summary>" + o.message("change_details") + "</summary>\n")
+		for _, rc := range d.ResourceChanges {
+			renderer := NewUnifiedDiffRenderer(rc, o)
+			renderer.HeaderSuffix = o.message(stateHeaderSuffixMessage(rc))
+			text, err := renderer.Render()
+			if err != nil {
+				return err
+			}
+			if text == "" {
+				// The values differ only in sensitive values, which are masked.
+				text = o.message("sensitive_only") + "\n"
+			}
+			fmt.Fprintf(&b, "\n%s%s\n# %s\n%s%s\n", fence, o.CodeLanguage, renderer.Header(), text, fence)
+		}
+		b.WriteString("\n</details>\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write state diff: %w", err)
+	}
+	return nil
+}
+
+// stateHeaderSuffixMessage returns the message ID of the header suffix of a change between states.
+func stateHeaderSuffixMessage(rc *tfjson.ResourceChange) string {
+	switch {
+	case rc.Change.Actions.Create():
+		return "state_resource_added"
+	case rc.Change.Actions.Delete():
+		return "state_resource_removed"
+	}
+	return "state_resource_changed"
+}
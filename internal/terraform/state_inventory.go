@@ -0,0 +1,236 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// DefaultInventoryAttributes are the key attributes of resources listed in inventories of states.
+var DefaultInventoryAttributes = []string{"id", "name", "arn"}
+
+// sensitiveInventoryValue replaces sensitive values in inventories.
+const sensitiveInventoryValue = "(sensitive)"
+
+// StateInventory is an inventory of the managed resources of a state, the output of `terraform show -json`,
+// for documentation snapshots of environments. Data sources are not listed.
+type StateInventory struct {
+	TerraformVersion string
+	// Attributes are the key attributes of resources, e.g. "id" or "tags.Name".
+	Attributes []string
+	// Modules are the modules with resources, the root module first. The address of the root module is "".
+	Modules []InventoryModule
+	options Options
+}
+
+type InventoryModule struct {
+	Address   string
+	Resources []InventoryResource
+}
+
+type InventoryResource struct {
+	Address string
+	Type    string
+	// Values are the values of the key attributes of the inventory, "" if there is none.
+	Values []string
+}
+
+// InventoryTypeCount is the number of resources of a type in a module.
+type InventoryTypeCount struct {
+	Module string
+	Type   string
+	Count  int
+}
+
+// NewStateInventory reads a state from input and lists its resources with the attributes,
+// DefaultInventoryAttributes if nil. Sensitive values are masked.
+func NewStateInventory(input io.Reader, attributes []string, opts Options) (*StateInventory, error) {
+	var state tfjson.State
+	if err := json.NewDecoder(input).Decode(&state); err != nil {
+		return nil, fmt.Errorf("cannot parse input: %w", err)
+	}
+	if attributes == nil {
+		attributes = DefaultInventoryAttributes
+	}
+	inventory := &StateInventory{TerraformVersion: state.TerraformVersion, Attributes: attributes, options: opts}
+	if state.Values == nil {
+		return inventory, nil
+	}
+	var walk func(m *tfjson.StateModule) error
+	walk = func(m *tfjson.StateModule) error {
+		if m == nil {
+			return nil
+		}
+		module := InventoryModule{Address: m.Address}
+		for _, r := range m.Resources {
+			if r.Mode != tfjson.ManagedResourceMode {
+				continue
+			}
+			resource, err := inventoryResource(r, attributes, opts)
+			if err != nil {
+				return err
+			}
+			module.Resources = append(module.Resources, resource)
+		}
+		if len(module.Resources) > 0 {
+			inventory.Modules = append(inventory.Modules, module)
+		}
+		for _, child := range m.ChildModules {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(state.Values.RootModule); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+func inventoryResource(r *tfjson.StateResource, attributes []string, opts Options) (InventoryResource, error) {
+	var sensitivity any
+	if len(r.SensitiveValues) > 0 {
+		if err := json.Unmarshal(r.SensitiveValues, &sensitivity); err != nil {
+			return InventoryResource{}, fmt.Errorf("invalid sensitive values of %s: %w", r.Address, err)
+		}
+	}
+	enc := valueEncoder{escapeHTML: opts.EscapeHTML}
+	resource := InventoryResource{Address: r.Address, Type: r.Type, Values: make([]string, len(attributes))}
+	for i, attribute := range attributes {
+		keys := strings.Split(attribute, ".")
+		if isSensitivePath(sensitivity, keys) {
+			resource.Values[i] = sensitiveInventoryValue
+			continue
+		}
+		var v any = r.AttributeValues
+		for _, k := range keys {
+			m, _ := v.(map[string]interface{})
+			v = m[k]
+		}
+		switch x := v.(type) {
+		case nil:
+		case string:
+			resource.Values[i] = x
+		default:
+			b, err := enc.marshalScalar(x)
+			if err != nil {
+				return InventoryResource{}, fmt.Errorf("invalid value of %s of %s: %w", attribute, r.Address, err)
+			}
+			resource.Values[i] = string(b)
+		}
+	}
+	return resource, nil
+}
+
+// isSensitivePath reports whether the value at the keys is sensitive in the sensitivity of a state,
+// which marks sensitive values or the objects containing them with true.
+func isSensitivePath(sensitivity any, keys []string) bool {
+	for _, k := range keys {
+		if sensitivity == true {
+			return true
+		}
+		m, ok := sensitivity.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		sensitivity = m[k]
+	}
+	return sensitivity == true
+}
+
+// ResourceCount returns the number of resources in the inventory.
+func (s *StateInventory) ResourceCount() int {
+	count := 0
+	for _, m := range s.Modules {
+		count += len(m.Resources)
+	}
+	return count
+}
+
+// TypeCounts returns the number of resources of each type in each module, in the order of modules and types.
+func (s *StateInventory) TypeCounts() []InventoryTypeCount {
+	var counts []InventoryTypeCount
+	for _, m := range s.Modules {
+		byType := map[string]int{}
+		for _, r := range m.Resources {
+			byType[r.Type]++
+		}
+		types := make([]string, 0, len(byType))
+		for t := range byType {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			counts = append(counts, InventoryTypeCount{Module: m.Address, Type: t, Count: byType[t]})
+		}
+	}
+	return counts
+}
+
+// Render writes the inventory in markdown: a table of the number of resources by module and type,
+// and a table of the resources with their key attributes for each module.
+func (s *StateInventory) Render(w io.Writer) error {
+	o := s.options
+	moduleName := func(address string) string {
+		if address == "" {
+			return o.message("root_module")
+		}
+		return codeSpan(address)
+	}
+	var b strings.Builder
+	b.WriteString(markdownHeading(o.HeadingLevel, fmt.Sprintf(o.message("inventory_heading"), s.ResourceCount(), len(s.Modules))) + "\n")
+	if s.TerraformVersion != "" {
+		b.WriteString("\n" + fmt.Sprintf(o.message("terraform_version"), s.TerraformVersion) + "\n")
+	}
+	if len(s.Modules) == 0 {
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(&b, "\n| %s | %s | %s |\n| --- | --- | ---: |\n", o.message("module"), o.message("resource_type"), o.message("resource_count"))
+	for _, c := range s.TypeCounts() {
+		fmt.Fprintf(&b, "| %s | %s | %d |\n", tableCell(moduleName(c.Module)), tableCell(codeSpan(c.Type)), c.Count)
+	}
+
+	for _, m := range s.Modules {
+		level := o.HeadingLevel
+		if level > 0 && level < 6 {
+			level++
+		}
+		b.WriteString("\n" + markdownHeading(level, moduleName(m.Address)) + "\n\n")
+		b.WriteString("| " + o.message("address"))
+		for _, a := range s.Attributes {
+			b.WriteString(" | " + tableCell(codeSpan(a)))
+		}
+		b.WriteString(" |\n|" + strings.Repeat(" --- |", len(s.Attributes)+1) + "\n")
+		for _, r := range m.Resources {
+			b.WriteString("| " + tableCell(codeSpan(r.Address)))
+			for _, v := range r.Values {
+				cell := ""
+				if v != "" {
+					cell = tableCell(codeSpan(v))
+				}
+				b.WriteString(" | " + cell)
+			}
+			b.WriteString(" |\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return nil
+}
+
+// markdownHeading returns a heading of the level, or bold text if the level is 0.
+func markdownHeading(level int, text string) string {
+	if level == 0 {
+		return "**" + text + "**"
+	}
+	return strings.Repeat("#", level) + " " + text
+}
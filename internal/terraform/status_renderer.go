@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// StatusMaxLength is the maximum number of characters of FormatStatus, the limit of descriptions of commit statuses of GitHub.
+const StatusMaxLength = 140
+
+// renderStatus writes a line of at most StatusMaxLength characters like "+2 ~1 -1: -aws_instance.test, ~aws_subnet.a",
+// for descriptions of commit statuses and texts of badges. Addresses are listed from the most destructive actions
+// as long as they fit, and the rest are counted, e.g. "and 3 more".
+func (plan *PlanData) renderStatus(w io.Writer) error {
+	if _, err := io.WriteString(w, plan.status()+"\n"); err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+	return nil
+}
+
+func (plan *PlanData) status() string {
+	head := plan.Summary().Short()
+	if plan.options.Label != "" {
+		head += fmt.Sprintf(" (%s)", plan.options.Label)
+	}
+	var items []string
+	for _, action := range []struct {
+		symbol    string
+		addresses []string
+	}{
+		{"-", plan.DeletedAddresses},
+		{"-/+", plan.ReplacedAddresses},
+		{"~", plan.UpdatedAddresses},
+		{"+", plan.CreatedAddresses},
+	} {
+		for _, a := range action.addresses {
+			items = append(items, action.symbol+a)
+		}
+	}
+	if len(items) == 0 || utf8.RuneCountInString(head) > StatusMaxLength {
+		return truncateRunes(head, StatusMaxLength)
+	}
+
+	status := head
+	for i, item := range items {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		rest := ""
+		if remaining := len(items) - i - 1; remaining > 0 {
+			rest = fmt.Sprintf(" and %d more", remaining)
+		}
+		if utf8.RuneCountInString(status+sep+item+rest) > StatusMaxLength {
+			more := fmt.Sprintf(" and %d more", len(items)-i)
+			if i == 0 {
+				more = fmt.Sprintf(": %d resources", len(items))
+			}
+			return truncateRunes(status+more, StatusMaxLength)
+		}
+		status += sep + item
+	}
+	return status
+}
+
+// truncateRunes returns s cut to at most n characters, ending with an ellipsis if cut.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
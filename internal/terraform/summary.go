@@ -0,0 +1,196 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grouping keys of the summary
+const (
+	GroupByAction   = ""
+	GroupByProvider = "provider"
+	GroupByModule   = "module"
+)
+
+var groupByKeys = []string{GroupByAction, GroupByProvider, GroupByModule}
+
+// Summary is the number of resources for each action.
+type Summary struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+	Replace int `json:"replace"`
+	Moved   int `json:"moved"`
+	// Severity is the highest severity of the changes by the actions, e.g. SeverityHigh, or "" without changes.
+	Severity string `json:"severity,omitempty"`
+}
+
+// Summary returns the number of resources for each action.
+func (plan *PlanData) Summary() Summary {
+	return Summary{
+		Add:      len(plan.CreatedAddresses),
+		Change:   len(plan.UpdatedAddresses),
+		Destroy:  len(plan.DeletedAddresses),
+		Replace:  len(plan.ReplacedAddresses),
+		Moved:    len(plan.MovedAddresses),
+		Severity: plan.severity(),
+	}
+}
+
+// HasChanges reports whether any resource is changed.
+func (s Summary) HasChanges() bool {
+	return s.Add+s.Change+s.Destroy+s.Replace+s.Moved > 0
+}
+
+// HasDestructiveChanges reports whether any resource is destroyed or replaced.
+func (s Summary) HasDestructiveChanges() bool {
+	return s.Destroy+s.Replace > 0
+}
+
+// Short returns the counts in terraform's symbols, like "+3 ~2 -1".
+// Replacements and moves are included only if there are any.
+func (s Summary) Short() string {
+	if !s.HasChanges() {
+		return "no changes"
+	}
+	parts := []string{fmt.Sprintf("+%d", s.Add), fmt.Sprintf("~%d", s.Change), fmt.Sprintf("-%d", s.Destroy)}
+	if s.Replace > 0 {
+		parts = append(parts, fmt.Sprintf("-/+%d", s.Replace))
+	}
+	if s.Moved > 0 {
+		parts = append(parts, fmt.Sprintf("→%d", s.Moved))
+	}
+	return strings.Join(parts, " ")
+}
+
+// addressGroup is a list of addresses with the same action, as in the summary list.
+// Label is the action in the language of the options.
+type addressGroup struct {
+	Action    string
+	Label     string
+	Addresses []string
+}
+
+// addressGroups returns the non-empty groups of addresses for each action.
+// Addresses are not quoted, and moved resources are described with their previous addresses.
+func (plan *PlanData) addressGroups() []addressGroup {
+	var moved []string
+	for _, r := range plan.ResourceChanges {
+		if isMovedBlock(r.ResourceChange) {
+			moved = append(moved, fmt.Sprintf("%s (from %s)", r.Address(), r.ResourceChange.PreviousAddress))
+		}
+	}
+	var groups []addressGroup
+	for _, g := range []addressGroup{
+		{Action: "add", Addresses: plan.CreatedAddresses},
+		{Action: "change", Addresses: plan.UpdatedAddresses},
+		{Action: "destroy", Addresses: plan.DeletedAddresses},
+		{Action: "replace", Addresses: plan.ReplacedAddresses},
+		{Action: "moved", Addresses: moved},
+	} {
+		if len(g.Addresses) > 0 {
+			g.Label = plan.options.message(g.Action)
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// summaryGroup is the address groups of the resources with the same key, e.g. of a provider.
+type summaryGroup struct {
+	Key    string
+	Groups []addressGroup
+}
+
+// summaryGroups returns the address groups for each key of GroupBy, in the order keys first appear in the changes.
+func (plan *PlanData) summaryGroups() []summaryGroup {
+	key := func(r ResourceChangeData) string {
+		if plan.options.GroupBy == GroupByModule {
+			return truncateModuleAddress(r.ResourceChange.ModuleAddress, plan.options.ModuleDepth)
+		}
+		return r.Provider()
+	}
+	var keys []string
+	byKey := map[string]map[string][]string{}
+	for _, r := range plan.ResourceChanges {
+		k := key(r)
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+			byKey[k] = map[string][]string{}
+		}
+		address := r.Address()
+		if isMovedBlock(r.ResourceChange) {
+			address = fmt.Sprintf("%s (from %s)", r.Address(), r.ResourceChange.PreviousAddress)
+		}
+		byKey[k][r.Action()] = append(byKey[k][r.Action()], address)
+	}
+	groups := make([]summaryGroup, 0, len(keys))
+	for _, k := range keys {
+		g := summaryGroup{Key: k}
+		for _, action := range []string{"add", "change", "destroy", "replace", "moved"} {
+			if addresses := byKey[k][action]; len(addresses) > 0 {
+				g.Groups = append(g.Groups, addressGroup{Action: action, Label: plan.options.message(action), Addresses: addresses})
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// moduleTotal is the number of changes of the resources in a top-level module, including its descendants.
+// Module is empty for the root module.
+type moduleTotal struct {
+	Module string
+	Summary
+}
+
+// moduleTotals returns the number of changes for each top-level module, in the order modules first appear in the changes.
+func (plan *PlanData) moduleTotals() []moduleTotal {
+	var totals []moduleTotal
+	index := map[string]int{}
+	for _, r := range plan.ResourceChanges {
+		module := truncateModuleAddress(r.ResourceChange.ModuleAddress, 1)
+		i, ok := index[module]
+		if !ok {
+			i = len(totals)
+			index[module] = i
+			totals = append(totals, moduleTotal{Module: module})
+		}
+		s := &totals[i].Summary
+		switch r.Action() {
+		case "add":
+			s.Add++
+		case "change":
+			s.Change++
+		case "destroy":
+			s.Destroy++
+		case "replace":
+			s.Replace++
+		case "moved":
+			s.Moved++
+		}
+	}
+	return totals
+}
+
+// replaceGroups returns the non-empty groups of replaced addresses for each order of creation and destruction,
+// with the labels of the orders, e.g. "+/- replace (new before old)".
+func (plan *PlanData) replaceGroups() []addressGroup {
+	var createFirst, destroyFirst []string
+	for _, r := range plan.ResourceChanges {
+		switch {
+		case r.ResourceChange.Change.Actions.CreateBeforeDestroy():
+			createFirst = append(createFirst, r.Address())
+		case r.ResourceChange.Change.Actions.DestroyBeforeCreate():
+			destroyFirst = append(destroyFirst, r.Address())
+		}
+	}
+	var groups []addressGroup
+	if len(createFirst) > 0 {
+		groups = append(groups, addressGroup{Action: "replace", Label: plan.options.message("replace_create_before_destroy"), Addresses: createFirst})
+	}
+	if len(destroyFirst) > 0 {
+		groups = append(groups, addressGroup{Action: "replace", Label: plan.options.message("replace_destroy_before_create"), Addresses: destroyFirst})
+	}
+	return groups
+}
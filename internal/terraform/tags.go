@@ -0,0 +1,41 @@
+package terraform
+
+// Renderings of updates changing only tags or labels
+const (
+	TagOnlyUpdatesNone    = ""
+	TagOnlyUpdatesGroup   = "group"
+	TagOnlyUpdatesSummary = "summary"
+)
+
+var tagOnlyUpdatesModes = []string{TagOnlyUpdatesNone, TagOnlyUpdatesGroup, TagOnlyUpdatesSummary}
+
+// tagAttributes are the attributes of tags of AWS and Azure and labels of Google Cloud and Kubernetes.
+var tagAttributes = []string{"tags", "tags_all", "labels", "effective_labels", "terraform_labels"}
+
+// isTagOnly reports whether the resource is updated and only its tags or labels are changed.
+func (r ResourceChangeData) isTagOnly() bool {
+	if r.Action() != "change" {
+		return false
+	}
+	attributes := r.ChangedAttributes()
+	for _, k := range attributes {
+		if !containsString(tagAttributes, k) {
+			return false
+		}
+	}
+	return len(attributes) > 0
+}
+
+// tagOnlyChanges returns the updates changing only tags or labels if they are rendered separately.
+func (plan *PlanData) tagOnlyChanges() []ResourceChangeData {
+	if plan.options.TagOnlyUpdates == TagOnlyUpdatesNone {
+		return nil
+	}
+	var changes []ResourceChangeData
+	for _, r := range plan.ResourceChanges {
+		if r.isTagOnly() {
+			changes = append(changes, r)
+		}
+	}
+	return changes
+}
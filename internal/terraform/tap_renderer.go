@@ -0,0 +1,48 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderTAP writes a Test Anything Protocol (version 13) stream with one test point per resource change.
+// Findings of error level make the test point "not ok", and all findings are reported as YAML diagnostics.
+func (plan *PlanData) renderTAP(w io.Writer) error {
+	findings := findingsByChange(plan.Findings())
+
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	b.WriteString(fmt.Sprintf("1..%d\n", len(plan.ResourceChanges)))
+	for i, r := range plan.ResourceChanges {
+		status := "ok"
+		changeFindings := findings[changeKeyOf(r)]
+		for _, f := range changeFindings {
+			if f.Rule.Level == LevelError {
+				status = "not ok"
+			}
+		}
+		b.WriteString(fmt.Sprintf("%s %d - %s\n", status, i+1, tapEscape(r.Header())))
+		if len(changeFindings) == 0 {
+			continue
+		}
+		b.WriteString("  ---\n")
+		b.WriteString("  findings:\n")
+		for _, f := range changeFindings {
+			b.WriteString(fmt.Sprintf("    - rule: %s\n", f.Rule.ID))
+			b.WriteString(fmt.Sprintf("      severity: %s\n", f.Rule.Level))
+			b.WriteString(fmt.Sprintf("      message: %q\n", f.Message))
+		}
+		b.WriteString("  ...\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write tap: %w", err)
+	}
+	return nil
+}
+
+// tapEscape escapes characters which have a meaning in a test point description.
+func tapEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,95 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion     = "1.4"
+
+	teamsDetailsID = "details"
+)
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	Actions []map[string]any `json:"actions,omitempty"`
+}
+
+// renderTeams writes the plan as a message of Microsoft Teams incoming webhooks with an Adaptive Card:
+// the summary line, facts of action counts and the changed addresses in a container toggled by "Show details".
+func (plan *PlanData) renderTeams(w io.Writer) error {
+	heading, err := plan.headingText()
+	if err != nil {
+		return err
+	}
+	summary := plan.Summary()
+	title := map[string]any{"type": "TextBlock", "text": heading, "weight": "Bolder", "size": "Medium", "wrap": true}
+	if summary.HasDestructiveChanges() {
+		title["color"] = "Attention"
+	}
+
+	var facts []map[string]string
+	for _, f := range []struct {
+		title string
+		count int
+	}{
+		{capitalize(plan.options.message("add")), summary.Add},
+		{capitalize(plan.options.message("change")), summary.Change},
+		{capitalize(plan.options.message("destroy")), summary.Destroy},
+		{capitalize(plan.options.message("replace")), summary.Replace},
+		{capitalize(plan.options.message("moved")), summary.Moved},
+	} {
+		facts = append(facts, map[string]string{"title": f.title, "value": strconv.Itoa(f.count)})
+	}
+	card := adaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		Body:    []map[string]any{title, {"type": "FactSet", "facts": facts}},
+	}
+
+	if groups := plan.addressGroups(); len(groups) > 0 {
+		var items []map[string]any
+		for _, g := range groups {
+			items = append(items,
+				map[string]any{"type": "TextBlock", "text": g.Label, "weight": "Bolder", "wrap": true},
+				// Text runs are not parsed as markdown, so underscores of addresses are not taken as emphasis.
+				map[string]any{"type": "RichTextBlock", "spacing": "None", "inlines": []map[string]string{
+					{"type": "TextRun", "text": strings.Join(g.Addresses, "\n"), "fontType": "Monospace"},
+				}},
+			)
+		}
+		card.Body = append(card.Body, map[string]any{"type": "Container", "id": teamsDetailsID, "isVisible": false, "items": items})
+		card.Actions = append(card.Actions, map[string]any{"type": "Action.ToggleVisibility", "title": plan.options.message("show_details"), "targetElements": []string{teamsDetailsID}})
+	}
+	if plan.options.DetailsURL != "" {
+		card.Actions = append(card.Actions, map[string]any{"type": "Action.OpenUrl", "title": plan.options.message("full_report"), "url": plan.options.DetailsURL})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	message := teamsMessage{Type: "message", Attachments: []teamsAttachment{{ContentType: adaptiveCardContentType, Content: card}}}
+	if err := enc.Encode(message); err != nil {
+		return fmt.Errorf("failed to write adaptive card: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package terraform
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// textFuncs are the functions for formatting strings, counts and times in the templates which users can override,
+// i.e. HeadingFormat, the heading messages and Templates. Their arguments follow the ones of Sprig, with the value
+// to format last so that they can be pipelined, e.g. {{.Label | default "plan" | upper}}.
+var textFuncs = template.FuncMap{
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"title":     capitalize,
+	"trim":      strings.TrimSpace,
+	"trunc":     truncString,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"join":      func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"default":   defaultValue,
+	"pluralize": pluralize,
+	"add":       func(a, b int) int { return a + b },
+	"sub":       func(a, b int) int { return a - b },
+	"date":      formatDate,
+}
+
+// truncString returns the first n characters of s.
+func truncString(n int, s string) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// defaultValue returns v, or d if v is empty, i.e. the zero value or a string, slice or map of no elements.
+func defaultValue(d, v any) any {
+	if v == nil {
+		return d
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return d
+		}
+	default:
+		if rv.IsZero() {
+			return d
+		}
+	}
+	return v
+}
+
+// pluralize returns singular if count is 1, and plural otherwise, e.g. {{pluralize (len .DeletedAddresses) "resource" "resources"}}.
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+// formatDate returns t in the layout of the time package, e.g. {{date "2006-01-02" .Timestamp}}, or "" if t is zero.
+func formatDate(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
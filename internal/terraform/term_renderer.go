@@ -0,0 +1,102 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ANSI escape sequences of the term format
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// renderTerm writes the summary and the diffs as plain text for terminals,
+// colored with ANSI escape sequences unless colors are disabled.
+func (plan *PlanData) renderTerm(w io.Writer) error {
+	paint := func(color, s string) string {
+		if !plan.options.Color || color == "" {
+			return s
+		}
+		return color + s + ansiReset
+	}
+
+	heading, err := plan.headingText()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(paint(ansiBold, heading) + "\n")
+	for _, g := range plan.addressGroups() {
+		b.WriteString("\n" + g.Label + "\n")
+		for _, address := range g.Addresses {
+			b.WriteString(paint(termActionColor(g.Action), "  "+termActionSymbol(g.Action)+" "+address) + "\n")
+		}
+	}
+	for _, r := range plan.ResourceChanges {
+		body, err := r.Render()
+		if err != nil {
+			return err
+		}
+		b.WriteString("\n" + paint(ansiBold+termActionColor(r.Action()), "# "+r.Header()) + "\n")
+		for _, line := range strings.SplitAfter(body, "\n") {
+			if line == "" {
+				continue
+			}
+			b.WriteString(paint(termDiffLineColor(line), strings.TrimSuffix(line, "\n")) + "\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write term: %w", err)
+	}
+	return nil
+}
+
+func termActionSymbol(action string) string {
+	switch action {
+	case "add":
+		return "+"
+	case "change":
+		return "~"
+	case "destroy":
+		return "-"
+	case "replace":
+		return "-/+"
+	case "moved":
+		return "→"
+	}
+	return " "
+}
+
+func termActionColor(action string) string {
+	switch action {
+	case "add":
+		return ansiGreen
+	case "change", "moved":
+		return ansiYellow
+	case "destroy", "replace":
+		return ansiRed
+	}
+	return ""
+}
+
+// termDiffLineColor returns the color of a line of a unified diff.
+func termDiffLineColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		return ansiBold
+	case strings.HasPrefix(line, "@@"):
+		return ansiCyan
+	case strings.HasPrefix(line, "+"):
+		return ansiGreen
+	case strings.HasPrefix(line, "-"):
+		return ansiRed
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package terraform
+
+// ThresholdViolation is a count of changes over a limit of the options, e.g. MaxDestroys.
+type ThresholdViolation struct {
+	// Message is the ID of the message naming the count, e.g. "resources to destroy".
+	Message string
+	Count   int
+	Limit   int
+}
+
+// ThresholdViolations returns the counts of changes over the limits of the options, guarding against accidentally
+// huge plans. Limits of 0 are not checked.
+func (plan *PlanData) ThresholdViolations() []ThresholdViolation {
+	s := plan.Summary()
+	var violations []ThresholdViolation
+	for _, t := range []struct {
+		message string
+		count   int
+		limit   int
+	}{
+		{"threshold_destroy", s.Destroy, plan.options.MaxDestroys},
+		{"threshold_replace", s.Replace, plan.options.MaxReplaces},
+		{"threshold_changes", s.Add + s.Change + s.Destroy + s.Replace, plan.options.MaxChanges},
+	} {
+		if t.limit > 0 && t.count > t.limit {
+			violations = append(violations, ThresholdViolation{Message: t.message, Count: t.count, Limit: t.limit})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,19 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+)
+
+// renderTitle writes a line like "terraform: +3 ~2 -1 (prod/network)" with the counts of changes and the label of
+// the options, for bots setting titles of pull requests or subjects of messages.
+func (plan *PlanData) renderTitle(w io.Writer) error {
+	title := "terraform: " + plan.Summary().Short()
+	if plan.options.Label != "" {
+		title += fmt.Sprintf(" (%s)", plan.options.Label)
+	}
+	if _, err := io.WriteString(w, title+"\n"); err != nil {
+		return fmt.Errorf("failed to write title: %w", err)
+	}
+	return nil
+}
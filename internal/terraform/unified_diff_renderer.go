@@ -6,71 +6,231 @@ import (
 	"fmt"
 	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/pmezard/go-difflib/difflib"
+	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// diffContext is the number of unchanged lines around changes in diffs.
+const diffContext = 3
+
 type UnifiedDiffRenderer struct {
 	ResourceChange   *tfjson.ResourceChange
 	EnableEscapeHTML bool
+	RawValues        bool
+	LegacyUnescape   bool
+	FullContext      bool
+	// HeaderSuffix follows the address in the header, e.g. "will be created".
+	HeaderSuffix string
+	// ReplacePaths are the attribute paths forcing replacement, e.g. [["ami"]].
+	ReplacePaths [][]interface{}
+	// ReplaceMarker is appended to the lines of the attributes forcing replacement, e.g. "forces replacement".
+	// Lines are not marked if empty.
+	ReplaceMarker string
+	// CollapsedMessage replaces the unchanged lines beyond the context of changes instead of hunk headers,
+	// e.g. "… %d unchanged lines …". Diffs are split into hunks if empty.
+	CollapsedMessage string
 }
 
-func NewUnifiedDiffRenderer(resourceChange *tfjson.ResourceChange, enableEscapeHTML bool) *UnifiedDiffRenderer {
-	return &UnifiedDiffRenderer{ResourceChange: resourceChange, EnableEscapeHTML: enableEscapeHTML}
+func NewUnifiedDiffRenderer(resourceChange *tfjson.ResourceChange, opts Options) *UnifiedDiffRenderer {
+	return &UnifiedDiffRenderer{
+		ResourceChange:   resourceChange,
+		EnableEscapeHTML: opts.EscapeHTML,
+		RawValues:        opts.RawValues,
+		LegacyUnescape:   opts.LegacyUnescape,
+		FullContext:      opts.Profile == ProfilePrint,
+		HeaderSuffix:     opts.message(headerSuffixMessage(resourceChange, opts)),
+		ReplaceMarker:    replaceMarker(opts),
+		CollapsedMessage: collapsedMessage(opts),
+	}
+}
+
+func collapsedMessage(opts Options) string {
+	if !opts.CollapseUnchanged {
+		return ""
+	}
+	return opts.message("unchanged_lines")
+}
+
+func replaceMarker(opts Options) string {
+	if !opts.ReplaceMarkers {
+		return ""
+	}
+	return opts.message("forces_replacement")
 }
 
 func (r *UnifiedDiffRenderer) Render() (string, error) {
-	before, err := r.marshalChangeBefore()
+	start := time.Now()
+	before, beforeMarks, err := r.marshalChangeBefore()
 	if err != nil {
 		return "", fmt.Errorf("invalid resource changes (before): %w", err)
 	}
-	after, err := r.marshalChangeAfter()
+	after, afterMarks, err := r.marshalChangeAfter()
 	if err != nil {
 		return "", fmt.Errorf("invalid resource changes (after) : %w", err)
 	}
-	// Try to parse JSON string in values
-	replacer := strings.NewReplacer(`\n`, "\n  ", `\"`, "\"")
+	if r.LegacyUnescape && !r.RawValues {
+		// Unescape line breaks and quotes in the whole document (behavior before the value encoder)
+		replacer := strings.NewReplacer(`\n`, "\n  ", `\"`, "\"")
+		before = []byte(replacer.Replace(string(before)))
+		after = []byte(replacer.Replace(string(after)))
+	}
 	diff := difflib.UnifiedDiff{
-		A:       difflib.SplitLines(replacer.Replace(string(before))),
-		B:       difflib.SplitLines(replacer.Replace(string(after))),
-		Context: 3,
+		A:       difflib.SplitLines(string(before)),
+		B:       difflib.SplitLines(string(after)),
+		Context: diffContext,
+	}
+	if r.FullContext || r.CollapsedMessage != "" {
+		diff.Context = len(diff.A) + len(diff.B)
 	}
 	diffText, err := difflib.GetUnifiedDiffString(diff)
 	if err != nil {
 		return "", fmt.Errorf("failed to create diff: %w", err)
 	}
+	if len(beforeMarks) > 0 || len(afterMarks) > 0 {
+		diffText = markDiffLines(diffText, beforeMarks, afterMarks, " # "+r.ReplaceMarker)
+	}
+	if r.CollapsedMessage != "" && !r.FullContext {
+		diffText = collapseUnchangedLines(diffText, r.CollapsedMessage)
+	}
+	slog.Debug("rendered diff", "address", r.ResourceChange.Address, "lines", len(diff.A)+len(diff.B), "duration", time.Since(start))
 
 	return diffText, nil
 }
 
 func (r *UnifiedDiffRenderer) Header() string {
-	header := fmt.Sprintf("%s %s", r.ResourceChange.Address, r.headerSuffix())
+	header := fmt.Sprintf("%s %s", r.ResourceChange.Address, r.HeaderSuffix)
 
 	return header
 }
 
-func (r *UnifiedDiffRenderer) headerSuffix() string {
+// headerSuffixMessage returns the message ID of the header suffix of the action.
+// Replacements are distinguished by the order of creation and destruction if replaceOrder is set.
+func headerSuffixMessage(rc *tfjson.ResourceChange, opts Options) string {
+	replaceOrder := opts.ReplaceOrder
 	switch {
-	case r.ResourceChange.Change.Actions.Create():
-		return "will be created"
-	case r.ResourceChange.Change.Actions.Update():
-		return "will be updated in-place"
-	case r.ResourceChange.Change.Actions.Delete():
-		return "will be destroyed"
-	case r.ResourceChange.Change.Actions.Replace():
-		return "will be replaced"
+	case opts.DriftOnly && rc.Change.Actions.Delete():
+		return "drift_deleted"
+	case opts.DriftOnly:
+		return "drift_updated"
+	case rc.Change.Actions.Create():
+		return "created"
+	case rc.Change.Actions.Update():
+		return "updated"
+	case rc.Change.Actions.Delete():
+		return "destroyed"
+	case rc.Change.Actions.CreateBeforeDestroy() && replaceOrder:
+		return "replaced_create_before_destroy"
+	case rc.Change.Actions.DestroyBeforeCreate() && replaceOrder:
+		return "replaced_destroy_before_create"
+	case rc.Change.Actions.Replace():
+		return "replaced"
 	}
 	return ""
 }
 
-func (r *UnifiedDiffRenderer) marshalChangeBefore() ([]byte, error) {
+func (r *UnifiedDiffRenderer) marshalChangeBefore() ([]byte, map[int]bool, error) {
 	return r.marshalChange(r.ResourceChange.Change.Before)
 }
 
-func (r *UnifiedDiffRenderer) marshalChangeAfter() ([]byte, error) {
+func (r *UnifiedDiffRenderer) marshalChangeAfter() ([]byte, map[int]bool, error) {
 	return r.marshalChange(r.ResourceChange.Change.After)
 }
 
-func (r *UnifiedDiffRenderer) marshalChange(v any) ([]byte, error) {
+// marshalChange returns the document of a value and the 0-based numbers of its lines forcing replacement.
+func (r *UnifiedDiffRenderer) marshalChange(v any) ([]byte, map[int]bool, error) {
+	if r.RawValues || r.LegacyUnescape {
+		b, err := r.marshalJSON(v)
+		return b, nil, err
+	}
+	enc := valueEncoder{escapeHTML: r.EnableEscapeHTML, marked: r.replacePaths()}
+	b, err := enc.encode(v)
+	return b, enc.markedLines, err
+}
+
+// replacePaths returns the attribute paths forcing replacement if they are marked.
+func (r *UnifiedDiffRenderer) replacePaths() [][]interface{} {
+	if r.ReplaceMarker == "" || !r.ResourceChange.Change.Actions.Replace() {
+		return nil
+	}
+	return r.ReplacePaths
+}
+
+// markDiffLines appends marker to the removed lines of a unified diff in beforeMarks and the added lines in afterMarks,
+// by their 0-based numbers in the documents.
+func markDiffLines(diffText string, beforeMarks, afterMarks map[int]bool, marker string) string {
+	lines := strings.SplitAfter(diffText, "\n")
+	var before, after int
+	for i, line := range lines {
+		if m := hunkHeaderRegexp.FindStringSubmatch(line); m != nil {
+			before, _ = strconv.Atoi(m[1])
+			after, _ = strconv.Atoi(m[2])
+			before--
+			after--
+			continue
+		}
+		marked := false
+		switch {
+		case strings.HasPrefix(line, "-"):
+			marked = beforeMarks[before]
+			before++
+		case strings.HasPrefix(line, "+"):
+			marked = afterMarks[after]
+			after++
+		case strings.HasPrefix(line, " "):
+			before++
+			after++
+		}
+		if marked {
+			lines[i] = strings.TrimSuffix(line, "\n") + marker + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// collapseUnchangedLines replaces the runs of unchanged lines of a unified diff of whole documents with a line of
+// message, formatted with the number of lines, keeping diffContext lines around changes. The hunk header is dropped
+// as the lines of the documents are not contiguous anymore.
+func collapseUnchangedLines(diffText string, message string) string {
+	lines := strings.SplitAfter(diffText, "\n")
+	if len(lines) > 0 && hunkHeaderRegexp.MatchString(lines[0]) {
+		lines = lines[1:]
+	}
+	var b strings.Builder
+	for i := 0; i < len(lines); {
+		if !strings.HasPrefix(lines[i], " ") {
+			b.WriteString(lines[i])
+			i++
+			continue
+		}
+		end := i
+		for end < len(lines) && strings.HasPrefix(lines[end], " ") {
+			end++
+		}
+		head, tail := diffContext, diffContext
+		if i == 0 {
+			head = 0
+		}
+		if end == len(lines) || (end == len(lines)-1 && lines[end] == "") {
+			tail = 0
+		}
+		if hidden := end - i - head - tail; hidden > 1 {
+			b.WriteString(strings.Join(lines[i:i+head], ""))
+			b.WriteString(" " + fmt.Sprintf(message, hidden) + "\n")
+			b.WriteString(strings.Join(lines[end-tail:end], ""))
+		} else {
+			b.WriteString(strings.Join(lines[i:end], ""))
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func (r *UnifiedDiffRenderer) marshalJSON(v any) ([]byte, error) {
 	var buffer bytes.Buffer
 	enc := json.NewEncoder(&buffer)
 	enc.SetIndent("", "  ")
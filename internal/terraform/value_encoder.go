@@ -0,0 +1,161 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const valueIndent = "  "
+
+// valueEncoder writes values as indented JSON documents, except that string values are written decoded:
+// line breaks start a new (indented) line and quotes and backslashes are not escaped, so multi-line values
+// such as policies or scripts stay readable in diffs.
+type valueEncoder struct {
+	escapeHTML bool
+	// marked are the attribute paths whose lines are recorded in markedLines, e.g. the ones forcing replacement.
+	marked [][]interface{}
+	// markedLines are the 0-based numbers of the lines of the values at marked paths.
+	markedLines map[int]bool
+}
+
+func (e *valueEncoder) encode(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := e.encodeValue(&buffer, v, "", nil); err != nil {
+		return nil, err
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+func (e *valueEncoder) encodeValue(buffer *bytes.Buffer, v any, indent string, path []interface{}) error {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 {
+			buffer.WriteString("{}")
+			return nil
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buffer.WriteString("{\n")
+		for i, k := range keys {
+			key, err := e.marshalScalar(k)
+			if err != nil {
+				return err
+			}
+			start := e.line(buffer)
+			buffer.WriteString(indent + valueIndent)
+			buffer.Write(key)
+			buffer.WriteString(": ")
+			p := append(path[:len(path):len(path)], k)
+			if err := e.encodeValue(buffer, x[k], indent+valueIndent, p); err != nil {
+				return err
+			}
+			e.mark(p, start, e.line(buffer))
+			if i < len(keys)-1 {
+				buffer.WriteByte(',')
+			}
+			buffer.WriteByte('\n')
+		}
+		buffer.WriteString(indent + "}")
+	case []interface{}:
+		if len(x) == 0 {
+			buffer.WriteString("[]")
+			return nil
+		}
+		buffer.WriteString("[\n")
+		for i, elem := range x {
+			start := e.line(buffer)
+			buffer.WriteString(indent + valueIndent)
+			p := append(path[:len(path):len(path)], float64(i))
+			if err := e.encodeValue(buffer, elem, indent+valueIndent, p); err != nil {
+				return err
+			}
+			e.mark(p, start, e.line(buffer))
+			if i < len(x)-1 {
+				buffer.WriteByte(',')
+			}
+			buffer.WriteByte('\n')
+		}
+		buffer.WriteString(indent + "]")
+	case string:
+		buffer.WriteString(e.decodedString(x))
+	default:
+		b, err := e.marshalScalar(x)
+		if err != nil {
+			return err
+		}
+		buffer.Write(b)
+	}
+	return nil
+}
+
+// line returns the number of the line being written, if any path is marked.
+func (e *valueEncoder) line(buffer *bytes.Buffer) int {
+	if len(e.marked) == 0 {
+		return 0
+	}
+	return bytes.Count(buffer.Bytes(), []byte{'\n'})
+}
+
+// mark records the lines from start to end if path is marked.
+func (e *valueEncoder) mark(path []interface{}, start, end int) {
+	for _, m := range e.marked {
+		if reflect.DeepEqual(m, path) {
+			if e.markedLines == nil {
+				e.markedLines = map[int]bool{}
+			}
+			for i := start; i <= end; i++ {
+				e.markedLines[i] = true
+			}
+			return
+		}
+	}
+}
+
+func (e *valueEncoder) marshalScalar(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := json.NewEncoder(&buffer)
+	enc.SetEscapeHTML(e.escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// decodedString quotes s without escaping quotes and backslashes.
+// Control characters other than line breaks are still escaped so that they stay visible.
+func (e *valueEncoder) decodedString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteString(`\ufffd`)
+		case r == '\n':
+			b.WriteString("\n" + valueIndent)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20, r == '\u2028', r == '\u2029':
+			b.WriteString(fmt.Sprintf(`\u%04x`, r))
+		case e.escapeHTML && (r == '<' || r == '>' || r == '&'):
+			b.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultWorkspace is the workspace of working directories which never selected one.
+const DefaultWorkspace = "default"
+
+// WorkingDir is the state which a working directory of terraform targets.
+type WorkingDir struct {
+	Workspace string
+	// Backend is the type of the backend, e.g. "s3".
+	Backend string
+	// StateAddress locates the state of the workspace in the backend, e.g. "s3://bucket/key",
+	// or is "" for types of backends whose addresses are unknown.
+	StateAddress string
+}
+
+// backendState is the part of .terraform/terraform.tfstate written by terraform init.
+type backendState struct {
+	Backend *struct {
+		Type   string         `json:"type"`
+		Config map[string]any `json:"config"`
+	} `json:"backend"`
+}
+
+// ReadWorkingDir reads the workspace and the backend of a working directory from the files terraform init and
+// terraform workspace select write to its data directory, .terraform or TF_DATA_DIR, without running terraform.
+// TF_WORKSPACE overrides the selected workspace as in terraform. Directories without backends use the local one.
+func ReadWorkingDir(dir string) (WorkingDir, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return WorkingDir{}, err
+	}
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(dir, dataDir)
+	}
+
+	workDir := WorkingDir{Workspace: os.Getenv("TF_WORKSPACE")}
+	if workDir.Workspace == "" {
+		b, err := os.ReadFile(filepath.Join(dataDir, "environment"))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			workDir.Workspace = DefaultWorkspace
+		case err != nil:
+			return WorkingDir{}, err
+		default:
+			workDir.Workspace = strings.TrimSpace(string(b))
+		}
+	}
+
+	var state backendState
+	b, err := os.ReadFile(filepath.Join(dataDir, "terraform.tfstate"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return WorkingDir{}, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &state); err != nil {
+			return WorkingDir{}, fmt.Errorf("cannot parse the backend of %s: %w", dir, err)
+		}
+	}
+	workDir.Backend = "local"
+	var config map[string]any
+	if state.Backend != nil {
+		workDir.Backend = state.Backend.Type
+		config = state.Backend.Config
+	}
+	workDir.StateAddress = stateAddress(workDir.Backend, config, workDir.Workspace)
+	return workDir, nil
+}
+
+// stateAddress returns the location of the state of a workspace in a backend of the type and the configuration,
+// following where the backends of terraform store the states of workspaces other than the default one.
+func stateAddress(backend string, config map[string]any, workspace string) string {
+	str := func(key, defaultValue string) string {
+		if s, ok := config[key].(string); ok && s != "" {
+			return s
+		}
+		return defaultValue
+	}
+	isDefault := workspace == DefaultWorkspace
+	switch backend {
+	case "local":
+		statePath := str("path", "terraform.tfstate")
+		if isDefault {
+			return statePath
+		}
+		return path.Join(str("workspace_dir", "terraform.tfstate.d"), workspace, path.Base(statePath))
+	case "s3":
+		key := str("key", "")
+		if !isDefault {
+			key = path.Join(str("workspace_key_prefix", "env:"), workspace, key)
+		}
+		return fmt.Sprintf("s3://%s/%s", str("bucket", ""), key)
+	case "gcs":
+		return fmt.Sprintf("gs://%s/%s", str("bucket", ""), path.Join(str("prefix", ""), workspace+".tfstate"))
+	case "azurerm":
+		key := str("key", "terraform.tfstate")
+		if !isDefault {
+			key += "env:" + workspace
+		}
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", str("storage_account_name", ""), str("container_name", ""), key)
+	case "remote", "cloud":
+		name := workspace
+		workspaces, _ := config["workspaces"].(map[string]any)
+		if list, ok := config["workspaces"].([]any); ok && len(list) > 0 {
+			workspaces, _ = list[0].(map[string]any)
+		}
+		if s, ok := workspaces["name"].(string); ok && s != "" {
+			name = s
+		} else if s, ok := workspaces["prefix"].(string); ok && s != "" {
+			name = s + workspace
+		}
+		return path.Join(str("hostname", "app.terraform.io"), str("organization", ""), name)
+	}
+	return ""
+}
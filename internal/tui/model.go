@@ -0,0 +1,384 @@
+// Package tui browses plans in a terminal: a tree of modules and resources, and the diff of the selected resource.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// Keys handled by the model
+const (
+	KeyUp        = "up"
+	KeyDown      = "down"
+	KeyPageUp    = "pgup"
+	KeyPageDown  = "pgdown"
+	KeyEnter     = "enter"
+	KeyEscape    = "esc"
+	KeyBackspace = "backspace"
+)
+
+// actionFilters are the actions cycled by "a", in the order of the summary.
+var actionFilters = []string{"", "add", "change", "destroy", "replace", "moved"}
+
+// ANSI escape sequences
+const (
+	reset   = "\x1b[0m"
+	bold    = "\x1b[1m"
+	reverse = "\x1b[7m"
+	red     = "\x1b[31m"
+	green   = "\x1b[32m"
+	yellow  = "\x1b[33m"
+	cyan    = "\x1b[36m"
+	faint   = "\x1b[2m"
+)
+
+// row is a line of the tree, a module or a resource change in a module.
+type row struct {
+	module string
+	change *terraform.ResourceChangeData
+}
+
+// Model is the state of the browser.
+type Model struct {
+	changes []terraform.ResourceChangeData
+	diffs   map[string][]string
+
+	rows      []row
+	cursor    int
+	collapsed map[string]bool
+	// offset is the first visible row of the tree, diffOffset the first visible line of the diff.
+	offset     int
+	diffOffset int
+
+	actionFilter string
+	typeFilter   string
+	// typing is true while the type filter is edited.
+	typing bool
+
+	Width  int
+	Height int
+}
+
+// New returns the browser of the plan with the size of the terminal.
+func New(plan *terraform.PlanData, width, height int) *Model {
+	m := &Model{
+		changes:   plan.ResourceChanges,
+		diffs:     map[string][]string{},
+		collapsed: map[string]bool{},
+		Width:     width,
+		Height:    height,
+	}
+	m.filter()
+	return m
+}
+
+// filter rebuilds the rows of the tree from the filters, keeping the selected resource if it is still shown.
+func (m *Model) filter() {
+	var selected *terraform.ResourceChangeData
+	if m.cursor < len(m.rows) {
+		selected = m.rows[m.cursor].change
+	}
+	m.rows = m.rows[:0]
+	var modules []string
+	byModule := map[string][]*terraform.ResourceChangeData{}
+	for i := range m.changes {
+		c := &m.changes[i]
+		if m.actionFilter != "" && c.Action() != m.actionFilter {
+			continue
+		}
+		if m.typeFilter != "" && !strings.Contains(c.ResourceChange.Type, m.typeFilter) {
+			continue
+		}
+		module := c.ResourceChange.ModuleAddress
+		if _, ok := byModule[module]; !ok {
+			modules = append(modules, module)
+		}
+		byModule[module] = append(byModule[module], c)
+	}
+	m.cursor = 0
+	for _, module := range modules {
+		m.rows = append(m.rows, row{module: module})
+		if m.collapsed[module] {
+			continue
+		}
+		for _, c := range byModule[module] {
+			if c == selected {
+				m.cursor = len(m.rows)
+			}
+			m.rows = append(m.rows, row{module: module, change: c})
+		}
+	}
+	if selected == nil || m.cursor == 0 {
+		// Select the first resource rather than the root module.
+		for i, r := range m.rows {
+			if r.change != nil {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.offset = 0
+	m.diffOffset = 0
+}
+
+// HandleKey updates the model by a key, a single character or one of the Key constants.
+// It returns false when the browser should quit.
+func (m *Model) HandleKey(key string) bool {
+	if m.typing {
+		switch key {
+		case KeyEnter, KeyEscape:
+			m.typing = false
+		case KeyBackspace:
+			if m.typeFilter != "" {
+				_, size := utf8.DecodeLastRuneInString(m.typeFilter)
+				m.typeFilter = m.typeFilter[:len(m.typeFilter)-size]
+			}
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				m.typeFilter += key
+			}
+		}
+		m.filter()
+		return true
+	}
+
+	switch key {
+	case "q", KeyEscape:
+		return false
+	case KeyUp, "k":
+		m.move(-1)
+	case KeyDown, "j":
+		m.move(1)
+	case KeyPageDown, " ", "J":
+		m.diffOffset += m.paneHeight() / 2
+	case KeyPageUp, "K":
+		m.diffOffset -= m.paneHeight() / 2
+	case KeyEnter:
+		// Toggle the module of the selected row.
+		if m.cursor < len(m.rows) {
+			module := m.rows[m.cursor].module
+			m.collapsed[module] = !m.collapsed[module]
+			m.filter()
+			for i, r := range m.rows {
+				if r.change == nil && r.module == module {
+					m.cursor = i
+				}
+			}
+		}
+	case "a":
+		for i, a := range actionFilters {
+			if a == m.actionFilter {
+				m.actionFilter = actionFilters[(i+1)%len(actionFilters)]
+				break
+			}
+		}
+		m.filter()
+	case "/":
+		m.typing = true
+	case "c":
+		m.actionFilter = ""
+		m.typeFilter = ""
+		m.filter()
+	}
+	if m.diffOffset < 0 {
+		m.diffOffset = 0
+	}
+	return true
+}
+
+func (m *Model) move(delta int) {
+	m.cursor += delta
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	m.diffOffset = 0
+}
+
+// paneHeight is the number of lines of the panes between the title and the help line.
+func (m *Model) paneHeight() int {
+	if h := m.Height - 2; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// diff returns the lines of the diff of a resource change, rendered once.
+func (m *Model) diff(c *terraform.ResourceChangeData) []string {
+	if lines, ok := m.diffs[c.Address()]; ok {
+		return lines
+	}
+	rendered, err := c.Render()
+	if err != nil {
+		rendered = fmt.Sprintf("cannot render the diff: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(rendered, "\n"), "\n")
+	m.diffs[c.Address()] = lines
+	return lines
+}
+
+// View returns the screen as lines separated by "\r\n", for terminals in raw mode.
+func (m *Model) View() string {
+	height := m.paneHeight()
+	treeWidth := m.Width / 3
+	if treeWidth > 60 {
+		treeWidth = 60
+	}
+	if treeWidth < 10 {
+		treeWidth = 10
+	}
+	diffWidth := m.Width - treeWidth - 3
+	if diffWidth < 0 {
+		diffWidth = 0
+	}
+
+	// Scroll the tree to the cursor.
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	var diff []string
+	if m.cursor < len(m.rows) && m.rows[m.cursor].change != nil {
+		diff = m.diff(m.rows[m.cursor].change)
+	}
+	if maxOffset := len(diff) - height; m.diffOffset > maxOffset {
+		m.diffOffset = maxOffset
+	}
+	if m.diffOffset < 0 {
+		m.diffOffset = 0
+	}
+
+	var b strings.Builder
+	b.WriteString(bold + fit(m.title(), m.Width) + reset + "\r\n")
+	for i := 0; i < height; i++ {
+		tree := ""
+		if n := m.offset + i; n < len(m.rows) {
+			tree = m.treeLine(n, treeWidth)
+		} else {
+			tree = strings.Repeat(" ", treeWidth)
+		}
+		line := ""
+		if n := m.diffOffset + i; n < len(diff) {
+			line = colorDiffLine(fit(diff[n], diffWidth))
+		}
+		b.WriteString(tree + faint + " │ " + reset + line + "\r\n")
+	}
+	b.WriteString(faint + fit(m.help(), m.Width) + reset)
+	return b.String()
+}
+
+func (m *Model) title() string {
+	action := m.actionFilter
+	if action == "" {
+		action = "all"
+	}
+	typeFilter := m.typeFilter
+	if m.typing {
+		typeFilter += "_"
+	}
+	if typeFilter == "" {
+		typeFilter = "*"
+	}
+	shown := 0
+	for _, r := range m.rows {
+		if r.change != nil {
+			shown++
+		}
+	}
+	return fmt.Sprintf(" terraform-j2md  %d of %d resources  action: %s  type: %s", shown, len(m.changes), action, typeFilter)
+}
+
+func (m *Model) help() string {
+	if m.typing {
+		return " type to filter resource types, enter to apply"
+	}
+	return " ↑↓/jk select  enter fold module  space/J K scroll diff  a action  / type  c clear  q quit"
+}
+
+func (m *Model) treeLine(n, width int) string {
+	r := m.rows[n]
+	var text, color string
+	if r.change == nil {
+		marker := "▾"
+		if m.collapsed[r.module] {
+			marker = "▸"
+		}
+		name := r.module
+		if name == "" {
+			name = "(root module)"
+		}
+		text, color = marker+" "+name, bold
+	} else {
+		address := strings.TrimPrefix(r.change.Address(), r.module+".")
+		text = fmt.Sprintf("  %s %s", actionSymbol(r.change.Action()), address)
+		color = actionColor(r.change.Action())
+	}
+	text = fit(text, width)
+	if n == m.cursor {
+		return reverse + text + reset
+	}
+	return color + text + reset
+}
+
+func actionSymbol(action string) string {
+	switch action {
+	case "add":
+		return "+"
+	case "change":
+		return "~"
+	case "destroy":
+		return "-"
+	case "replace":
+		return "±"
+	case "moved":
+		return "→"
+	}
+	return " "
+}
+
+func actionColor(action string) string {
+	switch action {
+	case "add":
+		return green
+	case "change", "moved":
+		return yellow
+	case "destroy", "replace":
+		return red
+	}
+	return ""
+}
+
+func colorDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return cyan + line + reset
+	case strings.HasPrefix(line, "+"):
+		return green + line + reset
+	case strings.HasPrefix(line, "-"):
+		return red + line + reset
+	case strings.HasPrefix(line, "#"):
+		return bold + line + reset
+	}
+	return line
+}
+
+// fit cuts or pads s to width runes, replacing tabs which would break the layout.
+func fit(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		if width == 0 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
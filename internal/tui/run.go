@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"golang.org/x/term"
+)
+
+// Run browses the plan on the terminal tty until the user quits.
+func Run(plan *terraform.PlanData, tty *os.File) error {
+	fd := int(tty.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("%s is not a terminal", tty.Name())
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	// Use the alternate screen and hide the cursor, restoring them on exit.
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+
+	m := New(plan, 80, 24)
+	buf := make([]byte, 64)
+	for {
+		// The size is read on each redraw, since resizes are not signaled on every platform.
+		if width, height, err := term.GetSize(fd); err == nil {
+			m.Width, m.Height = width, height
+		}
+		fmt.Fprint(tty, "\x1b[H\x1b[2J"+m.View())
+
+		n, err := tty.Read(buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			if key == "ctrl-c" || !m.HandleKey(key) {
+				return nil
+			}
+		}
+	}
+}
+
+// parseKeys splits the input of a terminal in raw mode into keys.
+func parseKeys(b []byte) []string {
+	sequences := map[string]string{
+		"\x1b[A": KeyUp, "\x1bOA": KeyUp,
+		"\x1b[B": KeyDown, "\x1bOB": KeyDown,
+		"\x1b[5~": KeyPageUp, "\x1b[6~": KeyPageDown,
+	}
+	var keys []string
+	for len(b) > 0 {
+		matched := false
+		for seq, key := range sequences {
+			if len(b) >= len(seq) && string(b[:len(seq)]) == seq {
+				keys, b, matched = append(keys, key), b[len(seq):], true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		switch c := b[0]; {
+		case c == 0x1b:
+			// A lone escape, or an unknown sequence whose rest is dropped.
+			if len(b) == 1 {
+				keys = append(keys, KeyEscape)
+			}
+			return keys
+		case c == '\r' || c == '\n':
+			keys = append(keys, KeyEnter)
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, KeyBackspace)
+		case c == 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			r := []rune(string(b))[0]
+			keys = append(keys, string(r))
+			b = b[len(string(r)):]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
@@ -0,0 +1,200 @@
+// Package lambda renders plans in AWS Lambda, from S3 event notifications or from payloads of direct invocations.
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// Handler renders the plans of events, stores the reports in S3 and posts them to a webhook.
+type Handler struct {
+	// Options are the options of rendering, overridden by the options of direct invocations.
+	Options terraform.Options
+	// NewS3 returns the client of a bucket.
+	NewS3 func(bucket string) *publish.S3
+	// OutputBucket is the bucket of reports. Reports of S3 events are stored in the bucket of the plan if empty.
+	OutputBucket string
+	// OutputPrefix is prepended to the keys of reports.
+	OutputPrefix string
+	// Webhook receives the reports if not nil.
+	Webhook *publish.Webhook
+}
+
+// Request is the payload of direct invocations.
+type Request struct {
+	// Plan is the output of `terraform show -json`.
+	Plan json.RawMessage `json:"plan"`
+	// Options are the flags of the command without dashes, e.g. {"format": "html"}.
+	Options map[string]string `json:"options,omitempty"`
+	// Key is the key of the report in OutputBucket. The report is returned instead of stored if empty.
+	Key string `json:"key,omitempty"`
+}
+
+// Response is the result of an invocation.
+type Response struct {
+	// Output is the report of direct invocations whose reports are not stored.
+	Output      string `json:"output,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// URLs are the URLs of stored reports.
+	URLs []string `json:"urls,omitempty"`
+}
+
+// s3Event is the part of S3 event notifications used by Handle.
+type s3Event struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+		S3          struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// Handle renders the plans of an S3 event notification, or the plan of a Request.
+func (h *Handler) Handle(ctx context.Context, payload json.RawMessage) (*Response, error) {
+	var event s3Event
+	if err := json.Unmarshal(payload, &event); err == nil && len(event.Records) > 0 {
+		return h.handleS3Event(ctx, event)
+	}
+	var req Request
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("payload is neither an S3 event nor a request: %w", err)
+	}
+	return h.handleRequest(ctx, req)
+}
+
+func (h *Handler) handleS3Event(ctx context.Context, event s3Event) (*Response, error) {
+	res := &Response{}
+	for _, record := range event.Records {
+		if record.EventSource != "aws:s3" {
+			continue
+		}
+		bucket := record.S3.Bucket.Name
+		// Keys of notifications are URL encoded.
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object key %q: %w", record.S3.Object.Key, err)
+		}
+		// Reports stored in the bucket of plans must not trigger the function again.
+		if !strings.HasSuffix(key, ".json") || strings.HasSuffix(key, jsonReportSuffix) {
+			continue
+		}
+		plan, err := h.NewS3(bucket).Download(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot download s3://%s/%s: %w", bucket, key, err)
+		}
+		report, err := h.render(plan, h.Options)
+		if err != nil {
+			return nil, fmt.Errorf("s3://%s/%s: %w", bucket, key, err)
+		}
+		outputBucket := h.OutputBucket
+		if outputBucket == "" {
+			outputBucket = bucket
+		}
+		u, err := h.store(ctx, outputBucket, reportKey(key, h.Options.Format), report, h.Options)
+		if err != nil {
+			return nil, err
+		}
+		res.URLs = append(res.URLs, u)
+	}
+	return res, nil
+}
+
+// jsonReportSuffix is the suffix of JSON reports, which would otherwise replace their plans.
+const jsonReportSuffix = ".report.json"
+
+// reportKey returns the key of the report of the plan at key.
+func reportKey(key, format string) string {
+	base := strings.TrimSuffix(key, ".json")
+	ext := terraform.FileExtension(format)
+	if ext == "json" {
+		return base + jsonReportSuffix
+	}
+	return base + "." + ext
+}
+
+func (h *Handler) handleRequest(ctx context.Context, req Request) (*Response, error) {
+	if len(req.Plan) == 0 {
+		return nil, errors.New("plan is required")
+	}
+	params := make(map[string][]string, len(req.Options))
+	for name, v := range req.Options {
+		params[name] = []string{v}
+	}
+	opts, err := terraform.ParseOptions(h.Options, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+	report, err := h.render(req.Plan, opts)
+	if err != nil {
+		return nil, err
+	}
+	if req.Key == "" {
+		if err := h.post(ctx, report, opts); err != nil {
+			return nil, err
+		}
+		return &Response{Output: string(report), ContentType: terraform.ContentType(opts.Format)}, nil
+	}
+	if h.OutputBucket == "" {
+		return nil, errors.New("output bucket is not configured to store the report")
+	}
+	u, err := h.store(ctx, h.OutputBucket, req.Key, report, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{URLs: []string{u}}, nil
+}
+
+func (h *Handler) render(plan []byte, opts terraform.Options) ([]byte, error) {
+	planData, err := terraform.NewPlanData(bytes.NewReader(plan), opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse input as Terraform plan JSON: %w", err)
+	}
+	var b bytes.Buffer
+	if err := planData.Render(&b); err != nil {
+		return nil, fmt.Errorf("cannot render: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// store uploads the report to key under OutputPrefix and posts it to the webhook.
+func (h *Handler) store(ctx context.Context, bucket, key string, report []byte, opts terraform.Options) (string, error) {
+	if h.OutputPrefix != "" {
+		key = path.Join(h.OutputPrefix, key)
+	}
+	u, err := h.NewS3(bucket).Upload(ctx, key, report, terraform.ContentType(opts.Format))
+	if err != nil {
+		return "", fmt.Errorf("cannot upload the report to s3://%s/%s: %w", bucket, key, err)
+	}
+	if err := h.post(ctx, report, opts); err != nil {
+		return "", err
+	}
+	return u, nil
+}
+
+func (h *Handler) post(ctx context.Context, report []byte, opts terraform.Options) error {
+	if h.Webhook == nil {
+		return nil
+	}
+	webhook := *h.Webhook
+	if webhook.ContentType == "" {
+		webhook.ContentType = terraform.ContentType(opts.Format)
+	}
+	if err := webhook.Post(ctx, report); err != nil {
+		return fmt.Errorf("cannot post the report: %w", err)
+	}
+	return nil
+}
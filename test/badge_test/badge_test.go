@@ -0,0 +1,53 @@
+package badge_test
+
+import (
+	"github.com/reproio/terraform-j2md/internal/badge"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary terraform.Summary
+		wantURL string
+	}{
+		{
+			name:    "no changes",
+			summary: terraform.Summary{},
+			wantURL: "https://img.shields.io/badge/terraform-no_changes-lightgrey",
+		},
+		{
+			name:    "add only",
+			summary: terraform.Summary{Add: 3},
+			wantURL: "https://img.shields.io/badge/terraform-+3_~0_--0-brightgreen",
+		},
+		{
+			name:    "change",
+			summary: terraform.Summary{Add: 3, Change: 2},
+			wantURL: "https://img.shields.io/badge/terraform-+3_~2_--0-yellow",
+		},
+		{
+			name:    "destroy and replace",
+			summary: terraform.Summary{Add: 3, Change: 2, Destroy: 1, Replace: 1},
+			wantURL: "https://img.shields.io/badge/terraform-+3_~2_--1_--%2F+1-red",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := badge.New("terraform", tt.summary)
+			if got := b.URL(); got != tt.wantURL {
+				t.Errorf("URL() = %v, want %v", got, tt.wantURL)
+			}
+			svg, err := b.SVG()
+			if err != nil {
+				t.Errorf("SVG() error = %v", err)
+				return
+			}
+			if !strings.HasPrefix(svg, "<svg ") || !strings.Contains(svg, "<title>terraform: ") {
+				t.Errorf("SVG() = %v", svg)
+			}
+		})
+	}
+}
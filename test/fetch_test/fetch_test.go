@@ -0,0 +1,65 @@
+package fetch_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/fetch"
+)
+
+const planJSON = `{"format_version":"1.0","resource_changes":[]}`
+
+func TestScalr_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/iacp/v3/runs/run-1", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("unexpected auth: %q", got)
+		}
+		_, _ = w.Write([]byte(`{"data":{"id":"run-1","relationships":{"plan":{"data":{"id":"plan-1","type":"plans"}}}}}`))
+	})
+	mux.HandleFunc("/api/iacp/v3/plans/plan-1/json-output", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/archive/plan.json", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/archive/plan.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(planJSON))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := fetch.Scalr{URL: server.URL, Token: "token"}
+	got, err := s.Fetch(context.Background(), "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != planJSON {
+		t.Errorf("Fetch() = %s", got)
+	}
+
+	if _, err := s.Fetch(context.Background(), "run-2"); err == nil {
+		t.Error("Fetch() should fail for an unknown run")
+	}
+}
+
+func TestEnv0_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deployments/d-1/plan" || r.URL.Query().Get("format") != "json" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		if user, password, _ := r.BasicAuth(); user != "key" || password != "secret" {
+			t.Errorf("unexpected auth: %q %q", user, password)
+		}
+		_, _ = w.Write([]byte(planJSON))
+	}))
+	defer server.Close()
+
+	e := fetch.Env0{URL: server.URL, APIKey: "key", APISecret: "secret"}
+	got, err := e.Fetch(context.Background(), "d-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != planJSON {
+		t.Errorf("Fetch() = %s", got)
+	}
+}
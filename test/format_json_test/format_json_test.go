@@ -45,6 +45,32 @@ func TestFormatJsonPlan(t *testing.T) {
 }`,
 				After: `{
   "foo": "bar"
+}`,
+			},
+			wantErr: false,
+		},
+		{
+			name: "json string with unsorted keys",
+			args: args{
+				old: &tfjson.Change{
+					Before: `{"b":1.50,"a":{"d":[],"c":null}}`,
+					After:  `{"b":1.50,"a":{"d":[],"c":null}}`,
+				},
+			},
+			want: &tfjson.Change{
+				Before: `{
+  "a": {
+    "c": null,
+    "d": []
+  },
+  "b": 1.50
+}`,
+				After: `{
+  "a": {
+    "c": null,
+    "d": []
+  },
+  "b": 1.50
 }`,
 			},
 			wantErr: false,
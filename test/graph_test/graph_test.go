@@ -0,0 +1,67 @@
+package graph_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"os"
+	"testing"
+)
+
+func testDataPath(name, suffix string) string {
+	return fmt.Sprintf("../testdata/%s/%s", name, suffix)
+}
+
+func Test_graph(t *testing.T) {
+	tests := []struct {
+		name    string
+		graph   string
+		plan    string
+		wantErr bool
+	}{
+		{name: "graph2md", graph: testDataPath("graph2md", "graph.dot"), wantErr: false},
+		{name: "graph2md_plan", graph: testDataPath("graph2md", "graph.dot"), plan: testDataPath("aws_sample", "show.json"), wantErr: false},
+		{name: "graph2md_legacy", graph: testDataPath("graph2md_legacy", "graph.dot"), plan: testDataPath("resource_with_index", "show.json"), wantErr: false},
+		{name: "invalid_json", graph: testDataPath("invalid_json", "show.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := terraform.DefaultOptions()
+			var plan *terraform.PlanData
+			if tt.plan != "" {
+				file, err := os.Open(tt.plan)
+				if err != nil {
+					t.Fatalf("cannot open plan file: %s", tt.plan)
+				}
+				defer file.Close()
+				if plan, err = terraform.NewPlanData(file, opts); err != nil {
+					t.Fatalf("NewPlanData() error = %v", err)
+				}
+			}
+			file, err := os.Open(tt.graph)
+			if err != nil {
+				t.Fatalf("cannot open input file: %s", tt.graph)
+			}
+			defer file.Close()
+
+			graph, err := terraform.NewGraph(file, plan, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGraph() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := bytes.Buffer{}
+			if err := graph.Render(&got); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			expected, err := os.ReadFile(testDataPath(tt.name, "expected.md"))
+			if err != nil {
+				t.Fatalf("cannot open expected file: %v", err)
+			}
+			if got.String() != string(expected) {
+				t.Errorf("Render() = %v, want %v", got.String(), string(expected))
+			}
+		})
+	}
+}
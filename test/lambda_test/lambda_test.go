@@ -0,0 +1,141 @@
+package lambda_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"github.com/reproio/terraform-j2md/lambda"
+)
+
+// fakeS3 serves objects of path-style URLs.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(b)
+	case http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = b
+	}
+}
+
+func newHandler(t *testing.T, store *fakeS3) *lambda.Handler {
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	opts := terraform.DefaultOptions()
+	opts.EscapeHTML = false
+	return &lambda.Handler{
+		Options: opts,
+		NewS3: func(bucket string) *publish.S3 {
+			return &publish.S3{Bucket: bucket, Region: "us-east-1", AccessKeyID: "id", SecretAccessKey: "secret", Endpoint: server.URL}
+		},
+		OutputPrefix: "reports",
+	}
+}
+
+func TestHandler_S3Event(t *testing.T) {
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeS3{objects: map[string][]byte{"/plans/infra/main+1.json": plan}}
+	h := newHandler(t, store)
+
+	event := `{"Records":[
+		{"eventSource":"aws:s3","s3":{"bucket":{"name":"plans"},"object":{"key":"infra/main%2B1.json"}}},
+		{"eventSource":"aws:s3","s3":{"bucket":{"name":"plans"},"object":{"key":"reports/infra/main%2B1.md"}}}
+	]}`
+	res, err := h.Handle(context.Background(), json.RawMessage(event))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.URLs) != 1 {
+		t.Fatalf("URLs = %v", res.URLs)
+	}
+	want, err := os.ReadFile("../testdata/aws_sample/expected.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.objects["/plans/reports/infra/main+1.md"]; string(got) != string(want) {
+		t.Errorf("stored report differs from expected.md: %v", res.URLs)
+	}
+}
+
+func TestHandler_S3EventJSONReport(t *testing.T) {
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{terraform.FormatTeams, terraform.FormatJSONPatch} {
+		t.Run(format, func(t *testing.T) {
+			store := &fakeS3{objects: map[string][]byte{"/plans/main.json": plan}}
+			h := newHandler(t, store)
+			h.Options.Format = format
+			h.OutputPrefix = ""
+
+			event := `{"Records":[{"eventSource":"aws:s3","s3":{"bucket":{"name":"plans"},"object":{"key":"main.json"}}}]}`
+			if _, err := h.Handle(context.Background(), json.RawMessage(event)); err != nil {
+				t.Fatal(err)
+			}
+			if got := store.objects["/plans/main.json"]; string(got) != string(plan) {
+				t.Error("the report replaced the plan")
+			}
+			if _, ok := store.objects["/plans/main.report.json"]; !ok {
+				t.Errorf("report is not stored: %v", store.objects)
+			}
+
+			event = `{"Records":[{"eventSource":"aws:s3","s3":{"bucket":{"name":"plans"},"object":{"key":"main.report.json"}}}]}`
+			res, err := h.Handle(context.Background(), json.RawMessage(event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.URLs) != 0 {
+				t.Errorf("the report triggered rendering: %v", res.URLs)
+			}
+		})
+	}
+}
+
+func TestHandler_Request(t *testing.T) {
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHandler(t, &fakeS3{objects: map[string][]byte{}})
+	payload, _ := json.Marshal(lambda.Request{Plan: plan, Options: map[string]string{"format": "csv"}})
+	res, err := h.Handle(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../testdata/aws_sample/expected.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != string(want) || res.ContentType != "text/csv; charset=utf-8" {
+		t.Errorf("Handle() = %+v", res)
+	}
+
+	payload, _ = json.Marshal(lambda.Request{Plan: plan, Key: "report.md"})
+	if _, err := h.Handle(context.Background(), payload); err == nil {
+		t.Error("Handle() should fail to store the report without the output bucket")
+	}
+}
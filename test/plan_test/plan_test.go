@@ -2,10 +2,14 @@ package plan_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/reproio/terraform-j2md/internal/terraform"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func testDataPath(name, suffix string) string {
@@ -38,7 +42,9 @@ func Test_newPlanData(t *testing.T) {
 			}
 			defer file.Close()
 
-			_, err = terraform.NewPlanData(file, false)
+			opts := terraform.DefaultOptions()
+			opts.EscapeHTML = false
+			_, err = terraform.NewPlanData(file, opts)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewPlanData() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -47,10 +53,102 @@ func Test_newPlanData(t *testing.T) {
 	}
 }
 
+func Test_newPlanDataOptions(t *testing.T) {
+	input, err := os.ReadFile(testDataPath("aws_sample", "show.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(opts terraform.Options) (string, error) {
+		plan, err := terraform.NewPlanData(bytes.NewReader(input), opts)
+		if err != nil {
+			return "", err
+		}
+		var b bytes.Buffer
+		err = plan.Render(&b)
+		return b.String(), err
+	}
+	want, err := render(terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    terraform.Options
+		wantErr bool
+	}{
+		{name: "zero value", opts: terraform.Options{}},
+		{name: "empty fields", opts: terraform.Options{EscapeHTML: true, HeadingLevel: terraform.DefaultHeadingLevel, CodeLanguage: terraform.DefaultCodeLanguage, Color: true}},
+		{name: "invalid", opts: terraform.Options{Format: "pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := render(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPlanData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != want {
+				t.Errorf("render() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func expectedFileName(format string) string {
+	if format == terraform.FormatMarkdown {
+		return "expected.md"
+	}
+	return "expected." + format
+}
+
+func testRender(t *testing.T, name string, opts terraform.Options, wantErr bool) {
+	testRenderInput(t, "", name, opts, wantErr)
+}
+
+// testRenderInput renders show.json of the test data input (name if empty) and compares it with the expected file
+// of name, so that cases of options share the plans of other cases, e.g. aws_sample.
+func testRenderInput(t *testing.T, input, name string, opts terraform.Options, wantErr bool) {
+	if input == "" {
+		input = name
+	}
+	inputFilePath := testDataPath(input, "show.json")
+	file, err := os.Open(inputFilePath)
+	if err != nil {
+		t.Errorf("cannot open input file: %s", inputFilePath)
+		return
+	}
+	defer file.Close()
+
+	plan, err := terraform.NewPlanData(file, opts)
+	if err != nil {
+		t.Errorf("cannot parse JSON as plan: %v", err)
+		return
+	}
+
+	got := bytes.Buffer{}
+	err = plan.Render(&got)
+	if (err != nil) != wantErr {
+		t.Errorf("render() error = %v, wantErr %v", err, wantErr)
+		return
+	}
+
+	expectedFilePath := testDataPath(name, expectedFileName(opts.Format))
+	expected, err := os.ReadFile(expectedFilePath)
+	if err != nil {
+		t.Errorf("cannot open expected file: %s", expectedFilePath)
+		return
+	}
+	if got.String() != string(expected) {
+		t.Errorf("render() = %v, want %v", got.String(), string(expected))
+		return
+	}
+}
+
 func Test_render(t *testing.T) {
 	t.Run("escape HTML characters", func(t *testing.T) {
 		tests := []struct {
 			name    string
+			input   string
 			wantErr bool
 		}{
 			{name: "no_changes", wantErr: false},
@@ -66,85 +164,1151 @@ func Test_render(t *testing.T) {
 			{name: "known_after_apply", wantErr: false},
 			{name: "moved_block", wantErr: false},
 			{name: "resource_with_index", wantErr: false},
+			{name: "backslash_values", wantErr: false},
+			{name: "markdown_address", wantErr: false},
+			{name: "import_id", wantErr: false},
+			{name: "drift", wantErr: false},
+			{name: "conditions", wantErr: false},
+			{name: "secrets", input: "redact", wantErr: false},
+			{name: "outputs_only", wantErr: false},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				inputFilePath := testDataPath(tt.name, "show.json")
-				file, err := os.Open(inputFilePath)
-				if err != nil {
-					t.Errorf("cannot open input file: %s", inputFilePath)
-					return
-				}
-				defer file.Close()
+				opts := terraform.DefaultOptions()
+				testRenderInput(t, tt.input, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				plan, err := terraform.NewPlanData(file, true)
-				if err != nil {
-					t.Errorf("cannot parse JSON as plan: %v", err)
-					return
-				}
+	t.Run("not escape HTML characters", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "special_characters", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.EscapeHTML = false
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				got := bytes.Buffer{}
-				err = plan.Render(&got)
-				if (err != nil) != tt.wantErr {
-					t.Errorf("render() error = %v, wantErr %v", err, tt.wantErr)
-					return
-				}
+	t.Run("raw values", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "raw_values", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.RawValues = true
+				testRenderInput(t, "iam_policy", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				expectedFilePath := testDataPath(tt.name, "expected.md")
-				expected, err := os.ReadFile(expectedFilePath)
-				if err != nil {
-					t.Errorf("cannot open expected file: %s", expectedFilePath)
-					return
-				}
-				if got.String() != string(expected) {
-					t.Errorf("render() = %v, want %v", got.String(), string(expected))
-					return
-				}
+	t.Run("sort", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			sort    string
+			wantErr bool
+		}{
+			{name: "sort_alpha", sort: terraform.SortAlpha, wantErr: false},
+			{name: "sort_action", sort: terraform.SortAction, wantErr: false},
+			{name: "sort_type", sort: terraform.SortType, wantErr: false},
+			{name: "sort_module", sort: terraform.SortModule, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Sort = tt.sort
+				testRenderInput(t, "sort", tt.name, opts, tt.wantErr)
 			})
 		}
 	})
 
-	t.Run("not escape HTML characters", func(t *testing.T) {
+	t.Run("heading", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			headingLevel  int
+			headingFormat string
+			wantErr       bool
+		}{
+			{name: "custom_heading", headingLevel: 0, headingFormat: "Terraform plan: {{len .CreatedAddresses}} to add", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.HeadingLevel = tt.headingLevel
+				opts.HeadingFormat = tt.headingFormat
+				testRenderInput(t, "single_add", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("code fence", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			codeFenceChar   string
+			codeFenceLength int
+			codeLanguage    string
+			wantErr         bool
+		}{
+			{name: "custom_code_fence", codeFenceChar: "~", codeFenceLength: 3, codeLanguage: "", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.CodeFenceChar = tt.codeFenceChar
+				opts.CodeFenceLength = tt.codeFenceLength
+				opts.CodeLanguage = tt.codeLanguage
+				testRenderInput(t, "single_add", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("summary style", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			summaryStyle string
+			wantErr      bool
+		}{
+			{name: "summary_table", summaryStyle: terraform.SummaryStyleTable, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.SummaryStyle = tt.summaryStyle
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
 		tests := []struct {
 			name    string
+			format  string
 			wantErr bool
 		}{
-			{name: "special_characters", wantErr: false},
+			{name: "aws_sample", format: terraform.FormatHTML, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatAsciiDoc, wantErr: false},
+			{name: "moved_block", format: terraform.FormatAsciiDoc, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatConfluence, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatTeams, wantErr: false},
+			{name: "no_changes", format: terraform.FormatTeams, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatCSV, wantErr: false},
+			{name: "include_module", format: terraform.FormatCSV, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatSARIF, wantErr: false},
+			{name: "module_destroy", format: terraform.FormatSARIF, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatJUnit, wantErr: false},
+			{name: "deposed", format: terraform.FormatJUnit, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatTAP, wantErr: false},
+			{name: "deposed", format: terraform.FormatTAP, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatTerm, wantErr: false},
+			{name: "moved_block", format: terraform.FormatTerm, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatJSONPatch, wantErr: false},
+			{name: "moved_block", format: terraform.FormatJSONPatch, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatChangelog, wantErr: false},
+			{name: "moved_block", format: terraform.FormatChangelog, wantErr: false},
+			{name: "no_changes", format: terraform.FormatChangelog, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatCommitMsg, wantErr: false},
+			{name: "include_module", format: terraform.FormatCommitMsg, wantErr: false},
+			{name: "aws_sample", format: terraform.FormatStatus, wantErr: false},
+			{name: "single_add", format: terraform.FormatStatus, wantErr: false},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				inputFilePath := testDataPath(tt.name, "show.json")
-				file, err := os.Open(inputFilePath)
-				if err != nil {
-					t.Errorf("cannot open input file: %s", inputFilePath)
-					return
-				}
-				defer file.Close()
+				opts := terraform.DefaultOptions()
+				opts.Format = tt.format
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				plan, err := terraform.NewPlanData(file, false)
-				if err != nil {
-					t.Errorf("cannot parse JSON as plan: %v", err)
-					return
-				}
+	t.Run("dependency graph", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			input   string
+			wantErr bool
+		}{
+			{name: "dependency_graph", input: "aws_sample", wantErr: false},
+			{name: "dependency_graph_module_instances", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.DependencyGraph = true
+				testRenderInput(t, tt.input, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				got := bytes.Buffer{}
-				err = plan.Render(&got)
-				if (err != nil) != tt.wantErr {
-					t.Errorf("render() error = %v, wantErr %v", err, tt.wantErr)
-					return
-				}
+	t.Run("pie chart", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "pie_chart", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.PieChart = true
+				testRenderInput(t, "all_types_mixed", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("theme", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			input      string
+			theme      string
+			accessible bool
+			wantErr    bool
+		}{
+			{name: "html_theme_dark", input: "single_add", theme: terraform.ThemeDark, wantErr: false},
+			{name: "html_theme_auto", input: "single_add", theme: terraform.ThemeAuto, wantErr: false},
+			{name: "html_theme_high_contrast", input: "all_types_mixed", theme: terraform.ThemeHighContrast, accessible: true, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Format = terraform.FormatHTML
+				opts.Theme = tt.theme
+				opts.Accessible = tt.accessible
+				testRenderInput(t, tt.input, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			format  string
+			wantErr bool
+		}{
+			{name: "print_profile", format: terraform.FormatMarkdown, wantErr: false},
+			{name: "print_profile", format: terraform.FormatHTML, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Format = tt.format
+				opts.Profile = terraform.ProfilePrint
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("registry links", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			format  string
+			wantErr bool
+		}{
+			{name: "registry_links", format: terraform.FormatMarkdown, wantErr: false},
+			{name: "registry_links", format: terraform.FormatHTML, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Format = tt.format
+				opts.RegistryLinks = true
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("module links", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "module_links", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.ModuleLinks = true
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("link templates", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			linkTemplates []terraform.LinkTemplate
+			wantErr       bool
+		}{
+			{name: "link_templates", linkTemplates: []terraform.LinkTemplate{
+				{Pattern: "aws_instance", Template: "https://cmdb.example.com/hosts/{{.Values.id}}"},
+				{Pattern: "aws_route_*", Template: "https://console.example.com/{{.Type}}/{{.Name}}"},
+			}, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.LinkTemplates = tt.linkTemplates
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			groupBy string
+			wantErr bool
+		}{
+			{name: "provider_alias", groupBy: terraform.GroupByProvider, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.ShowProvider = true
+				opts.GroupBy = tt.groupBy
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("module depth", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			moduleDepth int
+			wantErr     bool
+		}{
+			{name: "module_depth", moduleDepth: 1, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.GroupBy = terraform.GroupByModule
+				opts.ModuleDepth = tt.moduleDepth
+				testRenderInput(t, "module_links", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("module totals", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "module_totals", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.ModuleTotals = true
+				testRenderInput(t, "module_links", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("replace order", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "replace_order", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.ReplaceOrder = true
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("hide computed churn", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "computed_churn", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.HideComputedChurn = true
+				testRenderInput(t, "known_after_apply", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
 
-				expectedFilePath := testDataPath(tt.name, "expected.md")
-				expected, err := os.ReadFile(expectedFilePath)
+	t.Run("provider schemas", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "provider_schema", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				b, err := os.ReadFile(testDataPath(tt.name, "schema.json"))
 				if err != nil {
-					t.Errorf("cannot open expected file: %s", expectedFilePath)
-					return
+					t.Fatalf("cannot read schema: %v", err)
 				}
-				if got.String() != string(expected) {
-					t.Errorf("render() = %v, want %v", got.String(), string(expected))
-					return
+				var schemas tfjson.ProviderSchemas
+				if err := json.Unmarshal(b, &schemas); err != nil {
+					t.Fatalf("cannot parse schema: %v", err)
 				}
+				opts := terraform.DefaultOptions()
+				opts.ProviderSchemas = &schemas
+				opts.HideComputedChurn = true
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("thresholds", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "thresholds", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.MaxDestroys = 2
+				opts.MaxChanges = 3
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
 			})
 		}
 	})
+
+	t.Run("fail on types", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "fail_on_types", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.FailOnTypes = []string{"aws_security_group", "aws_instance", "aws_route_table*"}
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("warn on types", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "warn_on_types", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.FailOnTypes = []string{"aws_instance"}
+				opts.WarnOnTypes = []string{"aws_subnet", "aws_route_table*"}
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("severity", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "severity", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts, err := terraform.ParseOptions(terraform.DefaultOptions(), map[string][]string{"severity": {"update=low"}})
+				if err != nil {
+					t.Fatalf("ParseOptions() error = %v", err)
+				}
+				opts.ShowSeverity = true
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("stale plan", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "stale_plan", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.MaxPlanAge = 24 * time.Hour
+				opts.Now = time.Date(2023, 9, 1, 9, 0, 0, 0, time.UTC)
+				testRenderInput(t, "moved_block", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("drift only", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "drift_only", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.DriftOnly = true
+				testRenderInput(t, "drift", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("max detailed resources", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "max_detailed_resources", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.MaxDetailedResources = 3
+				opts.DetailsURL = "https://example.com/plan.html"
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("destructive first", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "destructive_first", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.DestructiveFirst = true
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("highlight embedded", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "highlight_embedded", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.HighlightEmbedded = true
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("template overrides", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			templates []string
+			wantErr   bool
+		}{
+			{name: "template_override", templates: []string{terraform.TemplateSummary, terraform.TemplateResource}, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Templates = map[string]string{}
+				for _, name := range tt.templates {
+					b, err := os.ReadFile(testDataPath(tt.name, name+".tmpl"))
+					if err != nil {
+						t.Fatalf("cannot read template: %v", err)
+					}
+					opts.Templates[name] = string(b)
+				}
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("template functions", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			headingFormat string
+			wantErr       bool
+		}{
+			{
+				name:          "template_funcs",
+				headingFormat: `{{len .ResourceChanges}} {{pluralize (len .ResourceChanges) "change" "changes"}} planned on {{date "2006-01-02" .Timestamp | default "unknown date"}}`,
+				wantErr:       false,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				summary, err := os.ReadFile(testDataPath(tt.name, terraform.TemplateSummary+".tmpl"))
+				if err != nil {
+					t.Fatalf("cannot read template: %v", err)
+				}
+				opts := terraform.DefaultOptions()
+				opts.HeadingFormat = tt.headingFormat
+				opts.Templates = map[string]string{terraform.TemplateSummary: string(summary)}
+				testRenderInput(t, "moved_block", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("header and footer", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "header_footer", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				header, err := os.ReadFile(testDataPath(tt.name, "header.md"))
+				if err != nil {
+					t.Fatalf("cannot read header: %v", err)
+				}
+				footer, err := os.ReadFile(testDataPath(tt.name, "footer.md"))
+				if err != nil {
+					t.Fatalf("cannot read footer: %v", err)
+				}
+				opts := terraform.DefaultOptions()
+				opts.Header = string(header)
+				opts.Footer = string(footer)
+				testRenderInput(t, "single_add", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("checklist", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "checklist", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Checklist = true
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("title", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			label   string
+			wantErr bool
+		}{
+			{name: "aws_sample", label: "prod/network", wantErr: false},
+			{name: "no_changes", label: "", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Format = terraform.FormatTitle
+				opts.Label = tt.label
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("tag-only updates", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			tagOnlyUpdates string
+			wantErr        bool
+		}{
+			{name: "tag_only_group", tagOnlyUpdates: terraform.TagOnlyUpdatesGroup, wantErr: false},
+			{name: "tag_only_summary", tagOnlyUpdates: terraform.TagOnlyUpdatesSummary, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.TagOnlyUpdates = tt.tagOnlyUpdates
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("replace markers", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "replace_markers", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.ReplaceMarkers = true
+				testRenderInput(t, "all_types_mixed", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("detail style", func(t *testing.T) {
+		tests := []struct {
+			name              string
+			input             string
+			detailStyle       string
+			fullDocumentTypes []string
+			wantErr           bool
+		}{
+			{name: "detail_paths", input: "aws_sample", detailStyle: terraform.DetailStylePaths, wantErr: false},
+			{name: "full_documents", input: "all_types_mixed", detailStyle: terraform.DetailStyleFull, wantErr: false},
+			{name: "full_document_types", input: "all_types_mixed", detailStyle: terraform.DetailStyleDiff, fullDocumentTypes: []string{"random_*"}, wantErr: false},
+			{name: "detail_hcl", input: "aws_sample", detailStyle: terraform.DetailStyleHCL, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.DetailStyle = tt.detailStyle
+				opts.FullDocumentTypes = tt.fullDocumentTypes
+				testRenderInput(t, tt.input, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("collapse unchanged", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "collapse_unchanged", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.CollapseUnchanged = true
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("max diff lines", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			maxDiffLines int
+			wantErr      bool
+		}{
+			{name: "max_diff_lines", maxDiffLines: 5, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.MaxDiffLines = tt.maxDiffLines
+				testRenderInput(t, "iam_policy", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("diff stats", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "diff_stats", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.DiffStats = true
+				testRenderInput(t, "all_types_mixed", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("anchors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "anchors", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Anchors = true
+				testRenderInput(t, "resource_with_index", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("state context", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			workspace    string
+			backend      string
+			stateAddress string
+			wantErr      bool
+		}{
+			{name: "state_context", workspace: "prod", backend: "s3", stateAddress: "s3://example-tfstate/network/terraform.tfstate", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Workspace = tt.workspace
+				opts.Backend = tt.backend
+				opts.StateAddress = tt.stateAddress
+				testRenderInput(t, "single_add", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("accessible", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			wantErr bool
+		}{
+			{name: "accessible", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Accessible = true
+				opts.ShowSeverity = true
+				opts.DependencyGraph = true
+				opts.DestructiveFirst = true
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			query   string
+			wantErr bool
+		}{
+			{name: "query", query: "del(.tags_all, .arn)", wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Query = tt.query
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("attribute allowlists", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			allowlists []terraform.AttributeAllowlist
+			wantErr    bool
+		}{
+			{name: "attribute_allowlist", allowlists: []terraform.AttributeAllowlist{
+				{Pattern: "aws_instance", Attributes: []string{"ami", "instance_type"}},
+				{Pattern: "aws_security_*", Attributes: []string{"description", "name"}},
+			}, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.AttributeAllowlists = tt.allowlists
+				testRenderInput(t, "aws_sample", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			patterns []string
+			wantErr  bool
+		}{
+			{name: "redact", patterns: []string{`ghp_[A-Za-z0-9]+`, `AKIA[0-9A-Z]{16}`}, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.RedactPatterns = tt.patterns
+				testRender(t, tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("lang", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			lang    string
+			wantErr bool
+		}{
+			{name: "lang_ja", lang: terraform.LangJapanese, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Lang = tt.lang
+				testRenderInput(t, "all_types_mixed", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			messages map[string]string
+			wantErr  bool
+		}{
+			{name: "custom_messages", messages: map[string]string{"created": "será creado", "add": "crear", "change_details": "Detalles"}, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Messages = tt.messages
+				testRenderInput(t, "all_types_mixed", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("details url", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			format  string
+			wantErr bool
+		}{
+			{name: "details_url", format: terraform.FormatMarkdown, wantErr: false},
+			{name: "details_url", format: terraform.FormatTeams, wantErr: false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := terraform.DefaultOptions()
+				opts.Format = tt.format
+				opts.DetailsURL = "https://ci.example.com/artifacts/plan.html"
+				testRenderInput(t, "single_change", tt.name, opts, tt.wantErr)
+			})
+		}
+	})
+}
+
+func Test_parseOptions(t *testing.T) {
+	defaults := terraform.DefaultOptions()
+	defaults.Messages = map[string]string{"add": "crear"}
+	tests := []struct {
+		name         string
+		params       map[string][]string
+		wantMessages map[string]string
+		wantErr      bool
+	}{
+		{name: "defaults", params: nil, wantMessages: map[string]string{"add": "crear"}},
+		{name: "messages", params: map[string][]string{"message": {"created=será creado", "add=añadir"}}, wantMessages: map[string]string{"add": "añadir", "created": "será creado"}},
+		{name: "unknown message", params: map[string][]string{"message": {"creatd=será creado"}}, wantErr: true},
+		{name: "missing verb", params: map[string][]string{"message": {"has_moved=%s moved"}}, wantErr: true},
+		{name: "not a pair", params: map[string][]string{"message": {"created"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := terraform.ParseOptions(defaults, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(opts.Messages, tt.wantMessages) {
+				t.Errorf("ParseOptions() messages = %v, want %v", opts.Messages, tt.wantMessages)
+			}
+			if len(defaults.Messages) != 1 || defaults.Messages["add"] != "crear" {
+				t.Errorf("ParseOptions() modified the defaults: %v", defaults.Messages)
+			}
+		})
+	}
+}
+
+func Test_parseOptionsInvertedBool(t *testing.T) {
+	tests := []struct {
+		name           string
+		falseDefaults  bool
+		params         map[string][]string
+		wantEscapeHTML bool
+		wantColor      bool
+		wantErr        bool
+	}{
+		{name: "defaults", params: nil, wantEscapeHTML: true, wantColor: true},
+		{name: "set", params: map[string][]string{"no-escape-html": {"true"}, "no-color": {"true"}}, wantEscapeHTML: false, wantColor: false},
+		{name: "unset", params: map[string][]string{"no-escape-html": {"false"}}, wantEscapeHTML: true, wantColor: true},
+		{name: "invalid", params: map[string][]string{"no-escape-html": {"maybe"}}, wantEscapeHTML: true, wantColor: true, wantErr: true},
+		{name: "invalid over set defaults", falseDefaults: true, params: map[string][]string{"no-color": {"maybe"}}, wantEscapeHTML: false, wantColor: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaults := terraform.DefaultOptions()
+			if tt.falseDefaults {
+				defaults.EscapeHTML = false
+				defaults.Color = false
+			}
+			opts, err := terraform.ParseOptions(defaults, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if opts.EscapeHTML != tt.wantEscapeHTML || opts.Color != tt.wantColor {
+				t.Errorf("ParseOptions() EscapeHTML = %v, Color = %v, want %v, %v", opts.EscapeHTML, opts.Color, tt.wantEscapeHTML, tt.wantColor)
+			}
+		})
+	}
+}
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		exitCodes   []string
+		maxChanges  int
+		failOnTypes []string
+		warnOnTypes []string
+		want        int
+		wantErr     bool
+	}{
+		{name: "defaults", input: "aws_sample", want: 0},
+		{name: "changes present", input: "single_add", exitCodes: []string{"changes-present=2", "destroys-present=4"}, want: 2},
+		{name: "destroys present", input: "aws_sample", exitCodes: []string{"changes-present=2", "destroys-present=4"}, want: 4},
+		{name: "no changes", input: "no_changes", exitCodes: []string{"changes-present=2"}, want: 0},
+		{name: "policy violation", input: "aws_sample", exitCodes: []string{"destroys-present=4"}, maxChanges: 3, want: 3},
+		{name: "type violation", input: "aws_sample", exitCodes: []string{"destroys-present=4", "policy-violation=5"}, failOnTypes: []string{"aws_subnet"}, want: 5},
+		{name: "warned type", input: "aws_sample", exitCodes: []string{"policy-violation=5"}, warnOnTypes: []string{"aws_subnet"}, want: 0},
+		{name: "created type", input: "aws_sample", exitCodes: []string{"policy-violation=5"}, failOnTypes: []string{"aws_route_table"}, want: 0},
+		{name: "unknown condition", input: "aws_sample", exitCodes: []string{"destroyed=4"}, wantErr: true},
+		{name: "out of range", input: "aws_sample", exitCodes: []string{"changes-present=256"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := terraform.ParseOptions(terraform.DefaultOptions(), map[string][]string{"exit-code": tt.exitCodes})
+			if err == nil {
+				opts.MaxChanges = tt.maxChanges
+				opts.FailOnTypes = tt.failOnTypes
+				opts.WarnOnTypes = tt.warnOnTypes
+				err = opts.Validate()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			file, err := os.Open(testDataPath(tt.input, "show.json"))
+			if err != nil {
+				t.Fatalf("cannot open input file: %v", err)
+			}
+			defer file.Close()
+			plan, err := terraform.NewPlanData(file, opts)
+			if err != nil {
+				t.Fatalf("cannot parse JSON as plan: %v", err)
+			}
+			if got := plan.ExitCode(); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_summarySeverity(t *testing.T) {
+	tests := []struct {
+		name       string
+		severities []string
+		want       string
+		wantErr    bool
+	}{
+		{name: "aws_sample", want: "high"},
+		{name: "single_change", want: "medium"},
+		{name: "single_add", want: "low"},
+		{name: "no_changes", want: ""},
+		{name: "single_add", severities: []string{"create=high"}, want: "high"},
+		{name: "single_add", severities: []string{"create=critical"}, wantErr: true},
+		{name: "single_add", severities: []string{"import=low"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := terraform.ParseOptions(terraform.DefaultOptions(), map[string][]string{"severity": tt.severities})
+			if err == nil {
+				err = opts.Validate()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			file, err := os.Open(testDataPath(tt.name, "show.json"))
+			if err != nil {
+				t.Fatalf("cannot open input file: %v", err)
+			}
+			defer file.Close()
+			plan, err := terraform.NewPlanData(file, opts)
+			if err != nil {
+				t.Fatalf("cannot parse JSON as plan: %v", err)
+			}
+			if got := plan.Summary().Severity; got != tt.want {
+				t.Errorf("Summary().Severity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readWorkingDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		want    terraform.WorkingDir
+		wantErr bool
+	}{
+		{
+			name: "s3",
+			dir:  "s3",
+			want: terraform.WorkingDir{Workspace: "prod", Backend: "s3", StateAddress: "s3://example-tfstate/env:/prod/network/terraform.tfstate"},
+		},
+		{
+			name: "local workspace",
+			dir:  "local_workspace",
+			want: terraform.WorkingDir{Workspace: "staging", Backend: "local", StateAddress: "terraform.tfstate.d/staging/terraform.tfstate"},
+		},
+		{
+			name: "cloud",
+			dir:  "cloud",
+			want: terraform.WorkingDir{Workspace: "default", Backend: "cloud", StateAddress: "app.terraform.io/example/network-prod"},
+		},
+		{
+			name: "not initialized",
+			dir:  "not_initialized",
+			want: terraform.WorkingDir{Workspace: "default", Backend: "local", StateAddress: "terraform.tfstate"},
+		},
+		{
+			name:    "missing",
+			dir:     "missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_WORKSPACE", "")
+			t.Setenv("TF_DATA_DIR", "")
+			got, err := terraform.ReadWorkingDir(testDataPath("working_dir", tt.dir))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadWorkingDir() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ReadWorkingDir() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,92 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func TestAzureDevOps_Post(t *testing.T) {
+	const threadsPath = "/example/project/_apis/git/repositories/repo/pullRequests/42/threads"
+
+	t.Run("create a thread", func(t *testing.T) {
+		var created map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, token, ok := r.BasicAuth(); !ok || user != "" || token != "pat" {
+				t.Errorf("unexpected auth: %q", r.Header.Get("Authorization"))
+			}
+			if r.URL.Query().Get("api-version") == "" {
+				t.Error("api-version is missing")
+			}
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == threadsPath:
+				_, _ = w.Write([]byte(`{"value":[{"id":1,"status":"active","comments":[{"id":1,"content":"LGTM"}]}]}`))
+			case r.Method == http.MethodPost && r.URL.Path == threadsPath:
+				if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+					t.Fatal(err)
+				}
+				_, _ = w.Write([]byte(`{"id":7}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		}))
+		defer server.Close()
+
+		a := publish.AzureDevOps{OrganizationURL: server.URL + "/example/", Project: "project", Repository: "repo", PullRequestID: "42", Token: "pat"}
+		got, err := a.Post(context.Background(), "### plan", terraform.Summary{Destroy: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := server.URL + "/example/project/_git/repo/pullrequest/42?discussionId=7"; got != want {
+			t.Errorf("Post() = %q, want %q", got, want)
+		}
+		if created["status"] != publish.ThreadStatusActive {
+			t.Errorf("status = %v, want active for destructive changes", created["status"])
+		}
+		content := created["comments"].([]any)[0].(map[string]any)["content"].(string)
+		if !strings.HasPrefix(content, "### plan\n\n<!-- terraform-j2md -->") {
+			t.Errorf("content = %q", content)
+		}
+	})
+
+	t.Run("update the thread", func(t *testing.T) {
+		var patched []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer job-token" {
+				t.Errorf("unexpected auth: %q", got)
+			}
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == threadsPath:
+				_, _ = w.Write([]byte(`{"value":[{"id":1,"comments":[{"id":1,"content":"LGTM"}]},{"id":2,"status":"active","comments":[{"id":3,"content":"old\n<!-- terraform-j2md -->"}]}]}`))
+			case r.Method == http.MethodPatch:
+				var body map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				patched = append(patched, r.URL.Path)
+				if r.URL.Path == threadsPath+"/2" && body["status"] != publish.ThreadStatusClosed {
+					t.Errorf("status = %q", body["status"])
+				}
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		}))
+		defer server.Close()
+
+		a := publish.AzureDevOps{OrganizationURL: server.URL + "/example", Project: "project", Repository: "repo", PullRequestID: "42", Token: "job-token", Bearer: true}
+		if _, err := a.Post(context.Background(), "### plan", terraform.Summary{Add: 1}); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{threadsPath + "/2/comments/3", threadsPath + "/2"}
+		if strings.Join(patched, ",") != strings.Join(want, ",") {
+			t.Errorf("patched %v, want %v", patched, want)
+		}
+	})
+}
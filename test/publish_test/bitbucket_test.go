@@ -0,0 +1,144 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+const bitbucketReport = "### 1 to add\n<details><summary>Change details</summary>\n\n````````diff\n+\n````````\n\n</details>\n"
+
+func TestBitbucketCloud_Post(t *testing.T) {
+	t.Run("create a comment", func(t *testing.T) {
+		var created map[string]map[string]string
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("unexpected auth: %q", got)
+			}
+			const path = "/repositories/ws/repo/pullrequests/42/comments"
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == path && r.URL.Query().Get("page") == "":
+				_, _ = w.Write([]byte(`{"values":[{"id":1,"content":{"raw":"LGTM"}}],"next":"` + server.URL + path + `?page=2"}`))
+			case r.Method == http.MethodGet && r.URL.Path == path:
+				_, _ = w.Write([]byte(`{"values":[{"id":2,"deleted":true,"content":{"raw":"[//]: # (terraform-j2md)"}}]}`))
+			case r.Method == http.MethodPost && r.URL.Path == path:
+				if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+					t.Fatal(err)
+				}
+				_, _ = w.Write([]byte(`{"id":3,"links":{"html":{"href":"https://bitbucket.org/ws/repo/pull-requests/42#comment-3"}}}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		}))
+		defer server.Close()
+
+		b := publish.BitbucketCloud{BaseURL: server.URL, Workspace: "ws", Repository: "repo", PullRequestID: "42", Token: "token"}
+		got, err := b.Post(context.Background(), bitbucketReport)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "https://bitbucket.org/ws/repo/pull-requests/42#comment-3" {
+			t.Errorf("Post() = %q", got)
+		}
+		want := "### 1 to add\n**Change details**\n\n````````diff\n+\n````````\n\n[//]: # (terraform-j2md)\n"
+		if raw := created["content"]["raw"]; raw != want {
+			t.Errorf("content = %q, want %q", raw, want)
+		}
+	})
+
+	t.Run("update the comment", func(t *testing.T) {
+		var method string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, token, ok := r.BasicAuth(); !ok || user != "me" || token != "app-password" {
+				t.Errorf("unexpected auth: %q", r.Header.Get("Authorization"))
+			}
+			switch {
+			case r.Method == http.MethodGet:
+				_, _ = w.Write([]byte(`{"values":[{"id":5,"content":{"raw":"old\n\n[//]: # (terraform-j2md)\n"}}]}`))
+			case r.Method == http.MethodPut && r.URL.Path == "/repositories/ws/repo/pullrequests/42/comments/5":
+				method = r.Method
+				_, _ = w.Write([]byte(`{"id":5,"links":{"html":{"href":"https://bitbucket.org/ws/repo/pull-requests/42#comment-5"}}}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		}))
+		defer server.Close()
+
+		b := publish.BitbucketCloud{BaseURL: server.URL, Workspace: "ws", Repository: "repo", PullRequestID: "42", User: "me", Token: "app-password"}
+		if _, err := b.Post(context.Background(), bitbucketReport); err != nil {
+			t.Fatal(err)
+		}
+		if method != http.MethodPut {
+			t.Error("the comment is not updated")
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		var raw string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				_, _ = w.Write([]byte(`{"values":[]}`))
+				return
+			}
+			var c map[string]map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			raw = c["content"]["raw"]
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		b := publish.BitbucketCloud{BaseURL: server.URL, Workspace: "ws", Repository: "repo", PullRequestID: "42", Token: "token", MaxLength: 500}
+		long := "### 100 to add\n" + strings.Repeat("- `null_resource.r`\n", 100)
+		if _, err := b.Post(context.Background(), long); err != nil {
+			t.Fatal(err)
+		}
+		if len(raw) > 500 || !strings.HasSuffix(raw, "[//]: # (terraform-j2md)\n") {
+			t.Errorf("content (%d bytes) = %q", len(raw), raw)
+		}
+	})
+}
+
+func TestBitbucketServer_Post(t *testing.T) {
+	const path = "/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/42"
+	var updated map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("unexpected auth: %q", got)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == path+"/activities" && r.URL.Query().Get("start") == "0":
+			_, _ = w.Write([]byte(`{"values":[{"action":"APPROVED"},{"action":"COMMENTED","comment":{"id":1,"version":0,"text":"LGTM"}}],"isLastPage":false,"nextPageStart":2}`))
+		case r.Method == http.MethodGet && r.URL.Path == path+"/activities" && r.URL.Query().Get("start") == "2":
+			_, _ = w.Write([]byte(`{"values":[{"action":"COMMENTED","comment":{"id":9,"version":3,"text":"old\n[//]: # (terraform-j2md)"}}],"isLastPage":true}`))
+		case r.Method == http.MethodPut && r.URL.Path == path+"/comments/9":
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write([]byte(`{"id":9,"version":4}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	b := publish.BitbucketServer{BaseURL: server.URL, Project: "PROJ", Repository: "repo", PullRequestID: "42", Token: "token"}
+	got, err := b.Post(context.Background(), bitbucketReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := server.URL + "/projects/PROJ/repos/repo/pull-requests/42/overview?commentId=9"; got != want {
+		t.Errorf("Post() = %q, want %q", got, want)
+	}
+	if updated["version"] != float64(3) {
+		t.Errorf("version = %v, want the version of the comment", updated["version"])
+	}
+	if text := updated["text"].(string); strings.Contains(text, "<details>") {
+		t.Errorf("text = %q", text)
+	}
+}
@@ -0,0 +1,118 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestConfluence_Publish(t *testing.T) {
+	t.Run("create a page", func(t *testing.T) {
+		var created map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, token, ok := r.BasicAuth(); !ok || user != "user" || token != "token" {
+				t.Errorf("unexpected auth: %q", r.Header.Get("Authorization"))
+			}
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/content":
+				if got := r.URL.Query().Get("title"); got != "Plan" {
+					t.Errorf("title = %q", got)
+				}
+				_, _ = w.Write([]byte(`{"results":[]}`))
+			case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content":
+				if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+					t.Fatal(err)
+				}
+				_, _ = w.Write([]byte(`{"id":"123","type":"page","title":"Plan","_links":{"base":"https://example.com/wiki","webui":"/spaces/OPS/pages/123"}}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		c := publish.Confluence{BaseURL: server.URL + "/wiki", User: "user", Token: "token", SpaceKey: "OPS", Title: "Plan", ParentID: "1"}
+		got, err := c.Publish(context.Background(), "<p>report</p>")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "https://example.com/wiki/spaces/OPS/pages/123"; got != want {
+			t.Errorf("Publish() = %q, want %q", got, want)
+		}
+		if created["space"].(map[string]any)["key"] != "OPS" {
+			t.Errorf("unexpected space: %v", created["space"])
+		}
+		if created["ancestors"].([]any)[0].(map[string]any)["id"] != "1" {
+			t.Errorf("unexpected ancestors: %v", created["ancestors"])
+		}
+		if created["body"].(map[string]any)["storage"].(map[string]any)["value"] != "<p>report</p>" {
+			t.Errorf("unexpected body: %v", created["body"])
+		}
+	})
+
+	t.Run("update a page by ID", func(t *testing.T) {
+		var updated map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("unexpected auth: %q", got)
+			}
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/rest/api/content/123":
+				_, _ = w.Write([]byte(`{"id":"123","type":"page","title":"Plan","version":{"number":4}}`))
+			case r.Method == http.MethodPut && r.URL.Path == "/rest/api/content/123":
+				if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+					t.Fatal(err)
+				}
+				_, _ = w.Write([]byte(`{"id":"123","type":"page","title":"Plan"}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		c := publish.Confluence{BaseURL: server.URL, Token: "token", PageID: "123"}
+		got, err := c.Publish(context.Background(), "<p>report</p>")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := server.URL + "/pages/viewpage.action?pageId=123"; got != want {
+			t.Errorf("Publish() = %q, want %q", got, want)
+		}
+		if updated["version"].(map[string]any)["number"] != float64(5) {
+			t.Errorf("unexpected version: %v", updated["version"])
+		}
+		if updated["title"] != "Plan" {
+			t.Errorf("unexpected title: %v", updated["title"])
+		}
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"not permitted"}`))
+		}))
+		defer server.Close()
+
+		c := publish.Confluence{BaseURL: server.URL, PageID: "123"}
+		_, err := c.Publish(context.Background(), "")
+		statusErr, ok := err.(*publish.StatusError)
+		if !ok {
+			t.Fatalf("Publish() error = %v, want StatusError", err)
+		}
+		if statusErr.StatusCode != http.StatusForbidden {
+			t.Errorf("StatusCode = %d", statusErr.StatusCode)
+		}
+	})
+
+	t.Run("missing page", func(t *testing.T) {
+		c := publish.Confluence{BaseURL: "https://example.com", SpaceKey: "OPS"}
+		if err := c.Validate(); err == nil {
+			t.Error("Validate() should fail without title or page ID")
+		}
+	})
+}
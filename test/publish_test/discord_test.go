@@ -0,0 +1,113 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+type discordPayload struct {
+	Embeds []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Color       int    `json:"color"`
+	} `json:"embeds"`
+}
+
+func TestDiscord_Post(t *testing.T) {
+	input, err := os.Open("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+	planData, err := terraform.NewPlanData(input, terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got discordPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d := publish.Discord{WebhookURL: server.URL}
+	if err := d.Post(context.Background(), planData, "report"); err != nil {
+		t.Fatal(err)
+	}
+	embed := got.Embeds[0]
+	if embed.Title != "2 to add, 1 to change, 1 to destroy, 1 to replace." {
+		t.Errorf("title = %q", embed.Title)
+	}
+	if embed.Color != 0xe05d44 {
+		t.Errorf("color = %#x, want red", embed.Color)
+	}
+	if !strings.HasPrefix(embed.Description, "**add**\n`aws_route_table.public-route`\n") {
+		t.Errorf("description = %q", embed.Description)
+	}
+}
+
+func TestDiscord_PostAttachesLargePlans(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"format_version":"1.0","resource_changes":[`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"address":"null_resource.resource_with_a_long_name_%d","mode":"managed","type":"null_resource","name":"r","change":{"actions":["create"],"before":null,"after":{}}}`, i)
+	}
+	b.WriteString("]}")
+	planData, err := terraform.NewPlanData(strings.NewReader(b.String()), terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got discordPayload
+	var attachment string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.Unmarshal([]byte(r.FormValue("payload_json")), &got); err != nil {
+			t.Fatal(err)
+		}
+		f, header, err := r.FormFile("files[0]")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if header.Filename != publish.DiscordAttachmentName {
+			t.Errorf("file name = %q", header.Filename)
+		}
+		content, _ := io.ReadAll(f)
+		attachment = string(content)
+	}))
+	defer server.Close()
+
+	d := publish.Discord{WebhookURL: server.URL}
+	if err := d.Post(context.Background(), planData, "full report"); err != nil {
+		t.Fatal(err)
+	}
+	description := got.Embeds[0].Description
+	if len(description) > 4096 {
+		t.Errorf("description is %d bytes", len(description))
+	}
+	if !strings.HasSuffix(description, "`\n… see the attached plan.md") {
+		t.Errorf("description ends with %q", description[len(description)-80:])
+	}
+	if attachment != "full report" {
+		t.Errorf("attachment = %q", attachment)
+	}
+}
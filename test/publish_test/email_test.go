@@ -0,0 +1,156 @@
+package publish_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestEmail_Message(t *testing.T) {
+	e := publish.Email{From: "ci@example.com", To: []string{"a@example.com", "b@example.com"}, Subject: "Terraform plan: 1 to add"}
+	message, err := e.Message("### plan", "<h3>plan ✓</h3>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := m.Header.Get("Subject"); got != "Terraform plan: 1 to add" {
+		t.Errorf("Subject = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", m.Header.Get("Content-Type"))
+	}
+
+	var types, contents []string
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(quotedprintable.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, p.Header.Get("Content-Type"))
+		contents = append(contents, string(b))
+	}
+	if strings.Join(types, ",") != "text/plain; charset=utf-8,text/html; charset=utf-8" {
+		t.Errorf("parts = %v", types)
+	}
+	if strings.Join(contents, ",") != "### plan,<h3>plan ✓</h3>" {
+		t.Errorf("contents = %q", contents)
+	}
+}
+
+func TestEmail_Send(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type result struct {
+		commands []string
+		data     string
+	}
+	results := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var r result
+		reader := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			r.commands = append(r.commands, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case line == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := reader.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				r.data = data.String()
+				reply("250 ok")
+			case line == "QUIT":
+				reply("221 bye")
+				results <- r
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+		results <- r
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	e := publish.Email{
+		Host:    host,
+		Port:    portNumber,
+		TLS:     publish.SMTPNoTLS,
+		From:    "CI <ci@example.com>",
+		To:      []string{"a@example.com"},
+		Subject: "plan",
+	}
+	if err := e.Send(context.Background(), "### plan", "<h3>plan</h3>"); err != nil {
+		t.Fatal(err)
+	}
+	r := <-results
+	commands := strings.Join(r.commands, "\n")
+	for _, want := range []string{"MAIL FROM:<ci@example.com>", "RCPT TO:<a@example.com>", "DATA", "QUIT"} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("commands %q do not include %q", commands, want)
+		}
+	}
+	if !strings.Contains(r.data, "Subject: plan\r\n") {
+		t.Errorf("data = %q", r.data)
+	}
+}
+
+func TestEmail_Validate(t *testing.T) {
+	e := publish.Email{Host: "smtp.example.com", TLS: "ssl", From: "ci@example.com", To: []string{"a@example.com"}}
+	if err := e.Validate(); err == nil {
+		t.Error("Validate() should fail for an unknown TLS mode")
+	}
+	e.TLS = publish.SMTPStartTLS
+	e.To = []string{"not an address"}
+	if err := e.Validate(); err == nil {
+		t.Error("Validate() should fail for an invalid recipient")
+	}
+}
@@ -0,0 +1,289 @@
+package publish_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+// fakeGitHub serves the comments of pull request 12 of octo/infra under /api/v3 like GitHub Enterprise Server.
+type fakeGitHub struct {
+	t        *testing.T
+	comments map[int]string
+	nextID   int
+}
+
+func newFakeGitHub(t *testing.T, comments ...string) *fakeGitHub {
+	f := &fakeGitHub{t: t, comments: map[int]string{}, nextID: 1}
+	for _, c := range comments {
+		f.comments[f.nextID] = c
+		f.nextID++
+	}
+	return f
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Bearer token" {
+		f.t.Errorf("unexpected auth: %q", got)
+	}
+	if got := r.Header.Get("Accept"); got != "application/vnd.github+json" {
+		f.t.Errorf("Accept = %q", got)
+	}
+	const prefix = "/api/v3/repos/octo/infra/issues/"
+	var body map[string]string
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case r.URL.Path == prefix+"12/comments" && r.Method == http.MethodGet:
+		var list []map[string]any
+		if r.URL.Query().Get("page") == "1" {
+			for _, id := range f.ids() {
+				list = append(list, map[string]any{"id": id, "body": f.comments[id]})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.URL.Path == prefix+"12/comments" && r.Method == http.MethodPost:
+		id := f.nextID
+		f.nextID++
+		f.comments[id] = body["body"]
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d,"html_url":"https://github.example.com/octo/infra/pull/12#issuecomment-%d"}`, id, id)
+	case strings.HasPrefix(r.URL.Path, prefix+"comments/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, prefix+"comments/"))
+		if _, ok := f.comments[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			f.comments[id] = body["body"]
+			fmt.Fprintf(w, `{"id":%d,"html_url":"https://github.example.com/octo/infra/pull/12#issuecomment-%d"}`, id, id)
+		case http.MethodDelete:
+			delete(f.comments, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		f.t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeGitHub) ids() []int {
+	var ids []int
+	for id := range f.comments {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestGitHub_Post(t *testing.T) {
+	const marked = "old\n\n<!-- terraform-j2md -->\n"
+	const labeled = "other\n\n<!-- terraform-j2md:staging -->\n"
+	tests := []struct {
+		name     string
+		comments []string
+		sticky   publish.Sticky
+		wantURL  string
+		wantIDs  []int
+	}{
+		{name: "no comments", sticky: publish.Sticky{Behavior: publish.BehaviorUpdate}, wantURL: "#issuecomment-1", wantIDs: []int{1}},
+		{name: "update", comments: []string{marked, "LGTM", marked}, sticky: publish.Sticky{Behavior: publish.BehaviorUpdate}, wantURL: "#issuecomment-3", wantIDs: []int{1, 2, 3}},
+		{name: "update with label", comments: []string{marked, labeled}, sticky: publish.Sticky{Label: "staging", Behavior: publish.BehaviorUpdate}, wantURL: "#issuecomment-2", wantIDs: []int{1, 2}},
+		{name: "new", comments: []string{marked}, sticky: publish.Sticky{Behavior: publish.BehaviorNew}, wantURL: "#issuecomment-2", wantIDs: []int{1, 2}},
+		{name: "recreate", comments: []string{marked, "LGTM", labeled}, sticky: publish.Sticky{Behavior: publish.BehaviorRecreate}, wantURL: "#issuecomment-4", wantIDs: []int{2, 3, 4}},
+		{name: "delete orphaned", comments: []string{marked, "LGTM", marked}, sticky: publish.Sticky{Behavior: publish.BehaviorDeleteOrphaned}, wantURL: "#issuecomment-3", wantIDs: []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t, tt.comments...)
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			g := publish.GitHub{APIURL: server.URL + "/api/v3/", Token: "token", Repository: "octo/infra"}
+			got, err := g.Post(context.Background(), 12, "### plan", tt.sticky)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(got, tt.wantURL) {
+				t.Errorf("Post() = %q, want %q", got, tt.wantURL)
+			}
+			if ids := fake.ids(); fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("comments %v, want %v", ids, tt.wantIDs)
+			}
+			posted := fake.comments[fake.ids()[len(fake.ids())-1]]
+			if want := "### plan\n\n" + tt.sticky.Marker() + "\n"; posted != want {
+				t.Errorf("posted %q, want %q", posted, want)
+			}
+		})
+	}
+}
+
+func TestGitHub_Validate(t *testing.T) {
+	g := publish.GitHub{Token: "token", Repository: "infra"}
+	if err := g.Validate(); err == nil {
+		t.Error("Validate() should fail for a repository without the owner")
+	}
+}
+
+func TestSticky_Validate(t *testing.T) {
+	for _, s := range []publish.Sticky{{Label: "a-->b"}, {Behavior: "replace"}} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate() should fail for %+v", s)
+		}
+	}
+}
+
+func TestGitHub_CreateCheckRun(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/octo/infra/check-runs" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1,"html_url":"https://github.com/octo/infra/runs/1"}`)
+	}))
+	defer server.Close()
+
+	g := publish.GitHub{APIURL: server.URL, Token: "token", Repository: "octo/infra"}
+	u, err := g.CreateCheckRun(context.Background(), publish.CheckRun{
+		Name:       "terraform plan",
+		HeadSHA:    "abc123",
+		Conclusion: publish.CheckNeutral,
+		Title:      publish.CheckTitle(terraform.Summary{Add: 3, Change: 2, Destroy: 1}),
+		Summary:    "### plan",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://github.com/octo/infra/runs/1" {
+		t.Errorf("CreateCheckRun() = %q", u)
+	}
+	want := `map[conclusion:neutral head_sha:abc123 name:terraform plan output:map[summary:### plan title:+3 ~2 -1, 1 destroy] status:completed]`
+	if fmt.Sprint(got) != want {
+		t.Errorf("request %v, want %v", got, want)
+	}
+}
+
+func TestGitHub_CreateCommitStatus(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/octo/infra/statuses/abc123" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	g := publish.GitHub{APIURL: server.URL, Token: "token", Repository: "octo/infra"}
+	err := g.CreateCommitStatus(context.Background(), publish.CommitStatus{
+		Context:     "terraform plan",
+		SHA:         "abc123",
+		State:       publish.CheckSuccess,
+		Description: publish.CheckTitle(terraform.Summary{Add: 3, Change: 2}),
+		TargetURL:   "https://example.com/plan.html",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `map[context:terraform plan description:+3 ~2 -0, no destroys state:success target_url:https://example.com/plan.html]`
+	if fmt.Sprint(got) != want {
+		t.Errorf("request %v, want %v", got, want)
+	}
+}
+
+func TestCheckTitle(t *testing.T) {
+	tests := []struct {
+		summary terraform.Summary
+		want    string
+	}{
+		{terraform.Summary{}, "no changes"},
+		{terraform.Summary{Add: 3, Change: 2}, "+3 ~2 -0, no destroys"},
+		{terraform.Summary{Destroy: 1, Replace: 2}, "+0 ~0 -1 -/+2, 3 destroys"},
+	}
+	for _, tt := range tests {
+		if got := publish.CheckTitle(tt.summary); got != tt.want {
+			t.Errorf("CheckTitle(%+v) = %q, want %q", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestGitHub_App(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch r.URL.Path {
+		case "/repos/octo/infra/installation", "/app/installations/7/access_tokens":
+			parts := strings.Split(auth, ".")
+			if len(parts) != 3 {
+				t.Fatalf("not a JWT: %q", auth)
+			}
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+				t.Errorf("invalid signature: %v", err)
+			}
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			if !strings.Contains(string(claims), `"iss":"123"`) {
+				t.Errorf("claims %s", claims)
+			}
+			if r.URL.Path == "/repos/octo/infra/installation" {
+				fmt.Fprint(w, `{"id":7}`)
+			} else {
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"token":"ghs_installation"}`)
+			}
+		case "/repos/octo/infra/statuses/abc123":
+			if auth != "ghs_installation" {
+				t.Errorf("unexpected token %q", auth)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	g := publish.GitHub{
+		APIURL:     server.URL,
+		Token:      "ignored",
+		Repository: "octo/infra",
+		App:        &publish.GitHubApp{AppID: "123", PrivateKey: privateKey},
+	}
+	status := publish.CommitStatus{Context: "terraform plan", SHA: "abc123", State: publish.CheckSuccess}
+	for i := 0; i < 2; i++ {
+		if err := g.CreateCommitStatus(context.Background(), status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "[GET /repos/octo/infra/installation POST /app/installations/7/access_tokens POST /repos/octo/infra/statuses/abc123 POST /repos/octo/infra/statuses/abc123]"
+	if fmt.Sprint(requests) != want {
+		t.Errorf("requests %v, want %v", requests, want)
+	}
+}
@@ -0,0 +1,52 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestGitLab_Post(t *testing.T) {
+	const notes = "/api/v4/projects/group%2Finfra/merge_requests/5/notes"
+	var updated map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "token" {
+			t.Errorf("PRIVATE-TOKEN = %q", got)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == notes:
+			_, _ = w.Write([]byte(`[
+				{"id":1,"body":"old\n<!-- terraform-j2md:prod -->","system":true},
+				{"id":2,"body":"old\n<!-- terraform-j2md:prod -->"},
+				{"id":3,"body":"LGTM"}
+			]`))
+		case r.Method == http.MethodPut && r.URL.EscapedPath() == notes+"/2":
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write([]byte(`{"id":2}`))
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/projects/group%2Finfra/merge_requests/5":
+			_, _ = w.Write([]byte(`{"web_url":"https://gitlab.example.com/group/infra/-/merge_requests/5"}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	g := publish.GitLab{URL: server.URL, Token: "token", Project: "group/infra"}
+	got, err := g.Post(context.Background(), 5, "### plan", publish.Sticky{Label: "prod", Behavior: publish.BehaviorUpdate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://gitlab.example.com/group/infra/-/merge_requests/5#note_2"; got != want {
+		t.Errorf("Post() = %q, want %q", got, want)
+	}
+	if want := "### plan\n\n<!-- terraform-j2md:prod -->\n"; updated["body"] != want {
+		t.Errorf("body = %q, want %q", updated["body"], want)
+	}
+}
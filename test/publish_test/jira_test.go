@@ -0,0 +1,90 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func TestJiraIssueKey(t *testing.T) {
+	tests := map[string]string{
+		"feature/OPS-123-add-bucket": "OPS-123",
+		"OPS2-7":                     "OPS2-7",
+		"fix/ops-123":                "",
+		"main":                       "",
+	}
+	for branch, want := range tests {
+		if got := publish.JiraIssueKey(branch); got != want {
+			t.Errorf("JiraIssueKey(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestJiraSummary(t *testing.T) {
+	input, err := os.Open("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+	planData, err := terraform.NewPlanData(input, terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := publish.JiraSummary(planData, "https://ci.example.com/artifacts/plan.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `*2 to add, 1 to change, 1 to destroy, 1 to replace.*
+* add
+** {{aws_route_table.public-route}}
+** {{aws_route_table_association.puclic-a}}
+* change
+** {{aws_subnet.public-a}}
+* destroy
+** {{aws_instance.test}}
+* replace
+** {{aws_security_group.admin}}
+
+[Full report|https://ci.example.com/artifacts/plan.html]
+`
+	if got != want {
+		t.Errorf("JiraSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestJira_Comment(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue/OPS-123/comment" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		if user, token, ok := r.BasicAuth(); !ok || user != "user" || token != "token" {
+			t.Errorf("unexpected auth: %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"10001"}`))
+	}))
+	defer server.Close()
+
+	j := publish.Jira{BaseURL: server.URL, User: "user", Token: "token", Issue: "OPS-123"}
+	got, err := j.Comment(context.Background(), "*no changes*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := server.URL + "/browse/OPS-123?focusedCommentId=10001"; got != want {
+		t.Errorf("Comment() = %q, want %q", got, want)
+	}
+	if posted["body"] != "*no changes*" {
+		t.Errorf("unexpected body: %v", posted)
+	}
+}
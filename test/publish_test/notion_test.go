@@ -0,0 +1,163 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func TestNotion_Publish(t *testing.T) {
+	input, err := os.Open("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+	planData, err := terraform.NewPlanData(input, terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var created struct {
+		Parent     map[string]string `json:"parent"`
+		Properties map[string]struct {
+			Title []struct {
+				Text struct {
+					Content string `json:"content"`
+				} `json:"text"`
+			} `json:"title"`
+		} `json:"properties"`
+		Children []map[string]any `json:"children"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected auth: %q", got)
+		}
+		if r.Header.Get("Notion-Version") == "" {
+			t.Error("Notion-Version header is missing")
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/pages" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(`{"id":"abc","url":"https://www.notion.so/abc"}`))
+	}))
+	defer server.Close()
+
+	n := publish.Notion{BaseURL: server.URL, Token: "secret", DatabaseID: "db"}
+	got, err := n.Publish(context.Background(), planData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://www.notion.so/abc" {
+		t.Errorf("Publish() = %q", got)
+	}
+	if created.Parent["database_id"] != "db" {
+		t.Errorf("unexpected parent: %v", created.Parent)
+	}
+	if title := created.Properties["Name"].Title[0].Text.Content; title != "2 to add, 1 to change, 1 to destroy, 1 to replace." {
+		t.Errorf("unexpected title: %q", title)
+	}
+	var types []string
+	for _, b := range created.Children {
+		types = append(types, b["type"].(string))
+	}
+	want := []string{"heading_3", "bulleted_list_item", "bulleted_list_item", "bulleted_list_item", "bulleted_list_item", "toggle"}
+	if len(types) != len(want) {
+		t.Fatalf("block types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("block types = %v, want %v", types, want)
+			break
+		}
+	}
+	diffs := created.Children[5]["toggle"].(map[string]any)["children"].([]any)
+	if len(diffs) != len(planData.ResourceChanges) {
+		t.Errorf("number of code blocks = %d, want %d", len(diffs), len(planData.ResourceChanges))
+	}
+}
+
+func TestNotion_PublishAppendsInBatches(t *testing.T) {
+	plan := struct {
+		FormatVersion   string           `json:"format_version"`
+		ResourceChanges []map[string]any `json:"resource_changes"`
+	}{FormatVersion: "1.0"}
+	for i := 0; i < 600; i++ {
+		plan.ResourceChanges = append(plan.ResourceChanges, map[string]any{
+			"address": fmt.Sprintf("null_resource.r%d", i),
+			"mode":    "managed",
+			"type":    "null_resource",
+			"name":    fmt.Sprintf("r%d", i),
+			"change":  map[string]any{"actions": []string{"create"}, "before": nil, "after": map[string]any{}},
+		})
+	}
+	b, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "show-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.Write(b)
+	_, _ = f.Seek(0, 0)
+	defer f.Close()
+	planData, err := terraform.NewPlanData(f, terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var blocks []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/v1/blocks/page-id/children" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		var body struct {
+			Children []map[string]any `json:"children"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Children) > 100 {
+			t.Errorf("%d blocks in a request", len(body.Children))
+		}
+		nested := 0
+		for _, b := range body.Children {
+			content := b[b["type"].(string)].(map[string]any)
+			if children, ok := content["children"].([]any); ok {
+				if len(children) > 100 {
+					t.Errorf("%d children of a block", len(children))
+				}
+				nested += len(children)
+			}
+		}
+		if total := len(body.Children) + nested; total > 1000 {
+			t.Errorf("%d blocks including nested ones in a request", total)
+		}
+		blocks = append(blocks, body.Children...)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	n := publish.Notion{BaseURL: server.URL, Token: "secret", PageID: "page-id"}
+	if _, err := n.Publish(context.Background(), planData); err != nil {
+		t.Fatal(err)
+	}
+	// heading, six lists of 100 added addresses and six toggles of 100 diffs
+	if len(blocks) != 13 {
+		t.Fatalf("number of blocks = %d, want 13", len(blocks))
+	}
+	label := blocks[1]["bulleted_list_item"].(map[string]any)["rich_text"].([]any)[0].(map[string]any)["text"].(map[string]any)["content"]
+	if label != "add (1-100 of 600)" {
+		t.Errorf("label = %q", label)
+	}
+}
@@ -0,0 +1,36 @@
+package publish_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestTruncateMarkdown(t *testing.T) {
+	long := "### 1 to add\n<details><summary>Change details</summary>\n\n````````diff\n" + strings.Repeat("+  \"key\": \"value\",\n", 100) + "````````\n\n</details>\n"
+
+	t.Run("short", func(t *testing.T) {
+		if got := publish.TruncateMarkdown(long, len(long)); got != long {
+			t.Errorf("TruncateMarkdown() changed a report within the limit")
+		}
+	})
+
+	t.Run("closes the fence and details", func(t *testing.T) {
+		got := publish.TruncateMarkdown(long, 1000)
+		if len(got) > 1000 {
+			t.Errorf("length = %d", len(got))
+		}
+		if !strings.HasSuffix(got, "+  \"key\": \"value\",\n````````\n\n</details>\n\n_The report is truncated because it is too long._\n") {
+			t.Errorf("TruncateMarkdown() = %q", got)
+		}
+	})
+
+	t.Run("outside of the fence", func(t *testing.T) {
+		list := "### 100 to add\n" + strings.Repeat("- `null_resource.r`\n", 100)
+		got := publish.TruncateMarkdown(list, 500)
+		if strings.Contains(got, "````") || strings.Contains(got, "</details>") {
+			t.Errorf("TruncateMarkdown() = %q", got)
+		}
+	})
+}
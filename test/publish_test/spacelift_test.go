@@ -0,0 +1,61 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestSpacelift_Comment(t *testing.T) {
+	var comment map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Query, "apiKeyUser"):
+			if req.Variables["id"] != "key" || req.Variables["secret"] != "secret" {
+				t.Errorf("variables %v", req.Variables)
+			}
+			_, _ = w.Write([]byte(`{"data":{"apiKeyUser":{"jwt":"jwt"}}}`))
+		case strings.Contains(req.Query, "runCommentCreate"):
+			if got := r.Header.Get("Authorization"); got != "Bearer jwt" {
+				t.Errorf("unexpected auth: %q", got)
+			}
+			comment = req.Variables
+			_, _ = w.Write([]byte(`{"data":{"runCommentCreate":{"body":"### plan"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := publish.Spacelift{Endpoint: server.URL, APIKeyID: "key", APIKeySecret: "secret", Stack: "infra", Run: "01RUN"}
+	if err := s.Comment(context.Background(), "### plan"); err != nil {
+		t.Fatal(err)
+	}
+	if comment["stack"] != "infra" || comment["run"] != "01RUN" || comment["body"] != "### plan" {
+		t.Errorf("comment %v", comment)
+	}
+}
+
+func TestSpacelift_CommentError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"run not found"}]}`))
+	}))
+	defer server.Close()
+
+	s := publish.Spacelift{Endpoint: server.URL, Token: "jwt", Stack: "infra", Run: "01RUN"}
+	err := s.Comment(context.Background(), "### plan")
+	if err == nil || !strings.Contains(err.Error(), "run not found") {
+		t.Errorf("Comment() error = %v", err)
+	}
+}
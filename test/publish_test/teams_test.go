@@ -0,0 +1,36 @@
+package publish_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestTeams_Post(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		_, _ = w.Write([]byte("1"))
+	}))
+	defer server.Close()
+
+	teams := publish.Teams{WebhookURL: server.URL}
+	if err := teams.Post(context.Background(), []byte(`{"type":"message"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"type":"message"}` {
+		t.Errorf("posted %q", got)
+	}
+
+	if err := teams.Post(context.Background(), []byte("### plan")); err == nil {
+		t.Error("Post() should fail for a message which is not JSON")
+	}
+}
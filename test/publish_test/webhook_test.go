@@ -0,0 +1,89 @@
+package publish_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/publish"
+)
+
+func TestWebhook_Post(t *testing.T) {
+	t.Run("headers and signature", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "### plan" {
+				t.Errorf("body = %q", body)
+			}
+			if got := r.Header.Get("Content-Type"); got != "text/markdown" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := r.Header.Get("X-Token"); got != "abc" {
+				t.Errorf("X-Token = %q", got)
+			}
+			if got, want := r.Header.Get("X-Signature"), publish.Signature("secret", body); got != want {
+				t.Errorf("X-Signature = %q, want %q", got, want)
+			}
+		}))
+		defer server.Close()
+
+		w := publish.Webhook{
+			URL:             server.URL,
+			ContentType:     "text/markdown",
+			Header:          http.Header{"X-Token": []string{"abc"}},
+			Secret:          "secret",
+			SignatureHeader: "X-Signature",
+		}
+		if err := w.Post(context.Background(), []byte("### plan")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			statuses     []int
+			retries      int
+			wantAttempts int
+			wantErr      bool
+		}{
+			{name: "success after server errors", statuses: []int{503, 429, 200}, retries: 3, wantAttempts: 3},
+			{name: "too many server errors", statuses: []int{500, 500, 500}, retries: 2, wantAttempts: 3, wantErr: true},
+			{name: "client error", statuses: []int{400, 200}, retries: 3, wantAttempts: 1, wantErr: true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				attempts := 0
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					if string(body) != "report" {
+						t.Errorf("body of attempt %d = %q", attempts, body)
+					}
+					w.WriteHeader(tt.statuses[attempts])
+					attempts++
+				}))
+				defer server.Close()
+
+				w := publish.Webhook{URL: server.URL, Retries: tt.retries}
+				err := w.Post(context.Background(), []byte("report"))
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Post() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if attempts != tt.wantAttempts {
+					t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+				}
+			})
+		}
+	})
+}
+
+func TestSignature(t *testing.T) {
+	// The example of GitHub's documentation on validating webhook deliveries
+	got := publish.Signature("It's a Secret to Everybody", []byte("Hello, World!"))
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got != want {
+		t.Errorf("Signature() = %q, want %q", got, want)
+	}
+}
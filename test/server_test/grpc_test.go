@@ -0,0 +1,130 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	rendererv1 "github.com/reproio/terraform-j2md/api/renderer/v1"
+	"github.com/reproio/terraform-j2md/internal/server"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newRendererClient(t *testing.T, renderer *server.GRPCServer) rendererv1.RendererServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	rendererv1.RegisterRendererServiceServer(s, renderer)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return rendererv1.NewRendererServiceClient(conn)
+}
+
+func TestGRPCServer_Render(t *testing.T) {
+	client := newRendererClient(t, server.NewGRPCServer(terraform.DefaultOptions()))
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.Render(context.Background(), &rendererv1.RenderRequest{
+		PlanJson: plan,
+		Options:  map[string]string{"format": "html", "no-escape-html": "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	var contentType string
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetContentType() != "" {
+			contentType = res.GetContentType()
+		}
+		got = append(got, res.GetData()...)
+	}
+	if contentType != "text/html; charset=utf-8" {
+		t.Errorf("content type %q", contentType)
+	}
+	want, err := os.ReadFile("../testdata/aws_sample/expected.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Error("document differs from expected.html")
+	}
+
+	stream, err = client.Render(context.Background(), &rendererv1.RenderRequest{PlanJson: plan, Options: map[string]string{"format": "pdf"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Recv() error = %v, want InvalidArgument", err)
+	}
+
+	stream, err = client.Render(context.Background(), &rendererv1.RenderRequest{PlanJson: plan, Options: map[string]string{"link-template": "aws_*={{.Address}}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Recv() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCServer_Summarize(t *testing.T) {
+	client := newRendererClient(t, server.NewGRPCServer(terraform.DefaultOptions()))
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Summarize(context.Background(), &rendererv1.SummarizeRequest{PlanJson: plan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetShort() != "+2 ~1 -1 -/+1" || !res.GetHasDestructiveChanges() || len(res.GetCreatedAddresses()) != 2 {
+		t.Errorf("Summarize() = %v", res)
+	}
+}
+
+func TestGRPCServer_SummarizeTimeout(t *testing.T) {
+	renderer := server.NewGRPCServer(terraform.DefaultOptions())
+	renderer.RenderTimeout = time.Nanosecond
+	renderer.Metrics = server.NewMetrics()
+	client := newRendererClient(t, renderer)
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Summarize(context.Background(), &rendererv1.SummarizeRequest{PlanJson: plan}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Summarize() error = %v, want DeadlineExceeded", err)
+	}
+	var b strings.Builder
+	if err := renderer.Metrics.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := `terraform_j2md_renders_total{format="summary",result="error"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("metrics do not contain %s:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,155 @@
+package server_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reproio/terraform-j2md/internal/server"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+)
+
+func TestServer_Render(t *testing.T) {
+	ts := httptest.NewServer(server.New(terraform.DefaultOptions()).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantContentType string
+		wantFile        string
+	}{
+		{name: "markdown", query: "no-escape-html=true", wantStatus: http.StatusOK, wantContentType: "text/markdown; charset=utf-8", wantFile: "expected.md"},
+		{name: "html", query: "format=html&no-escape-html=true", wantStatus: http.StatusOK, wantContentType: "text/html; charset=utf-8", wantFile: "expected.html"},
+		{name: "unknown option", query: "colour=red", wantStatus: http.StatusBadRequest},
+		{name: "invalid option", query: "format=pdf", wantStatus: http.StatusBadRequest},
+		{name: "template option", query: "heading-format=%7B%7Brange+1000000000000%7D%7Dx%7B%7Bend%7D%7D", wantStatus: http.StatusBadRequest},
+		{name: "query option", query: "query=del(.tags)", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.Open("../testdata/aws_sample/show.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer body.Close()
+			res, err := http.Post(ts.URL+"/render?"+tt.query, "application/json", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			got, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", res.StatusCode, tt.wantStatus, got)
+			}
+			if tt.wantFile == "" {
+				return
+			}
+			if ct := res.Header.Get("Content-Type"); ct != tt.wantContentType {
+				t.Errorf("Content-Type %q, want %q", ct, tt.wantContentType)
+			}
+			want, err := os.ReadFile("../testdata/aws_sample/" + tt.wantFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("body differs from %s", tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestServer_RenderInvalid(t *testing.T) {
+	ts := httptest.NewServer(server.New(terraform.DefaultOptions()).Handler())
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL+"/render", "application/json", strings.NewReader("not json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", res.StatusCode, http.StatusBadRequest)
+	}
+
+	res, err = http.Get(ts.URL + "/render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status %d, want %d", res.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_RenderTimeout(t *testing.T) {
+	s := server.New(terraform.DefaultOptions())
+	s.RenderTimeout = time.Nanosecond
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	body, err := os.Open("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	res, err := http.Post(ts.URL+"/render", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	ts := httptest.NewServer(server.New(terraform.DefaultOptions()).Handler())
+	defer ts.Close()
+
+	plan, err := os.ReadFile("../testdata/aws_sample/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []struct {
+		query string
+		body  string
+	}{
+		{query: "format=html", body: string(plan)},
+		{query: "format=html", body: string(plan)},
+		{query: "", body: "not json"},
+		{query: "format=pdf", body: string(plan)},
+	} {
+		res, err := http.Post(ts.URL+"/render?"+req.query, "application/json", strings.NewReader(req.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+
+	res, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	got, _ := io.ReadAll(res.Body)
+	for _, want := range []string{
+		`terraform_j2md_renders_total{format="html",result="ok"} 2`,
+		`terraform_j2md_renders_total{format="markdown",result="invalid"} 1`,
+		`terraform_j2md_renders_total{format="unknown",result="invalid"} 1`,
+		`terraform_j2md_render_duration_seconds_count{format="html"} 2`,
+		fmt.Sprintf(`terraform_j2md_render_input_bytes_sum{format="html"} %d`, 2*len(plan)),
+		`terraform_j2md_render_input_bytes_bucket{format="markdown",le="1024"} 1`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package state_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"os"
+	"testing"
+)
+
+func testDataPath(name, suffix string) string {
+	return fmt.Sprintf("../testdata/%s/%s", name, suffix)
+}
+
+func Test_stateInventory(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes []string
+		wantErr    bool
+	}{
+		{name: "state_inventory", attributes: nil, wantErr: false},
+		{name: "state_inventory_attributes", attributes: []string{"id", "tags.Name"}, wantErr: false},
+		{name: "invalid_json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputFilePath := testDataPath(tt.name, "state.json")
+			if tt.wantErr {
+				inputFilePath = testDataPath(tt.name, "show.json")
+			}
+			file, err := os.Open(inputFilePath)
+			if err != nil {
+				t.Fatalf("cannot open input file: %s", inputFilePath)
+			}
+			defer file.Close()
+
+			inventory, err := terraform.NewStateInventory(file, tt.attributes, terraform.DefaultOptions())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStateInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := bytes.Buffer{}
+			if err := inventory.Render(&got); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			expected, err := os.ReadFile(testDataPath(tt.name, "expected.md"))
+			if err != nil {
+				t.Fatalf("cannot open expected file: %v", err)
+			}
+			if got.String() != string(expected) {
+				t.Errorf("Render() = %v, want %v", got.String(), string(expected))
+			}
+		})
+	}
+}
+
+func Test_stateDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldFile string
+		newFile string
+		wantErr bool
+	}{
+		{name: "state_diff", oldFile: "old.json", newFile: "new.json", wantErr: false},
+		{name: "invalid_json", oldFile: "show.json", newFile: "show.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldFile, err := os.Open(testDataPath(tt.name, tt.oldFile))
+			if err != nil {
+				t.Fatalf("cannot open input file: %s", tt.oldFile)
+			}
+			defer oldFile.Close()
+			newFile, err := os.Open(testDataPath(tt.name, tt.newFile))
+			if err != nil {
+				t.Fatalf("cannot open input file: %s", tt.newFile)
+			}
+			defer newFile.Close()
+
+			diff, err := terraform.NewStateDiff(oldFile, newFile, terraform.DefaultOptions())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStateDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := bytes.Buffer{}
+			if err := diff.Render(&got); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			expected, err := os.ReadFile(testDataPath(tt.name, "expected.md"))
+			if err != nil {
+				t.Fatalf("cannot open expected file: %v", err)
+			}
+			if got.String() != string(expected) {
+				t.Errorf("Render() = %v, want %v", got.String(), string(expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package tui_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/reproio/terraform-j2md/internal/terraform"
+	"github.com/reproio/terraform-j2md/internal/tui"
+)
+
+func newModel(t *testing.T) *tui.Model {
+	f, err := os.Open("../testdata/all_types_mixed/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	plan, err := terraform.NewPlanData(f, terraform.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tui.New(plan, 120, 30)
+}
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantTitle   string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:      "initial",
+			wantTitle: "4 of 4 resources  action: all  type: *",
+			contains:  []string{"(root module)", "env_variable.test2", "random_id.test4", "-  \"name\": \"test2\","},
+		},
+		{
+			name:        "action filter",
+			keys:        []string{"a"},
+			wantTitle:   "1 of 4 resources  action: add  type: *",
+			contains:    []string{"env_variable.test5", "+  \"name\": \"test5\""},
+			notContains: []string{"env_variable.test2"},
+		},
+		{
+			name:        "type filter",
+			keys:        []string{"/", "r", "a", "n", "d", tui.KeyEnter},
+			wantTitle:   "1 of 4 resources  action: all  type: rand",
+			contains:    []string{"random_id.test4"},
+			notContains: []string{"env_variable.test2"},
+		},
+		{
+			name:      "clear filters",
+			keys:      []string{"a", "a", "/", "x", tui.KeyBackspace, "y", tui.KeyEnter, "c"},
+			wantTitle: "4 of 4 resources  action: all  type: *",
+		},
+		{
+			name:     "select",
+			keys:     []string{tui.KeyDown, "j"},
+			contains: []string{"+  \"name\": \"test5\""},
+		},
+		{
+			name:        "fold module",
+			keys:        []string{"k", tui.KeyUp, tui.KeyEnter},
+			contains:    []string{"▸ (root module)"},
+			notContains: []string{"env_variable.test2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel(t)
+			for _, key := range tt.keys {
+				if !m.HandleKey(key) {
+					t.Fatalf("HandleKey(%q) quit", key)
+				}
+			}
+			view := m.View()
+			if lines := strings.Split(view, "\r\n"); len(lines) != 30 {
+				t.Errorf("View() has %d lines, want 30", len(lines))
+			}
+			if tt.wantTitle != "" && !strings.Contains(view, tt.wantTitle) {
+				t.Errorf("View() title does not contain %q:\n%s", tt.wantTitle, view)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(view, s) {
+					t.Errorf("View() does not contain %q:\n%s", s, view)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(view, s) {
+					t.Errorf("View() contains %q:\n%s", s, view)
+				}
+			}
+		})
+	}
+}
+
+func TestQuit(t *testing.T) {
+	m := newModel(t)
+	if !m.HandleKey("/") || !m.HandleKey("q") {
+		t.Errorf("HandleKey(%q) quit while typing the type filter", "q")
+	}
+	if !m.HandleKey(tui.KeyEscape) {
+		t.Errorf("HandleKey(%q) quit while typing the type filter", tui.KeyEscape)
+	}
+	if m.HandleKey("q") {
+		t.Errorf("HandleKey(%q) did not quit", "q")
+	}
+}